@@ -0,0 +1,104 @@
+// sad-go-logger/cmd/sadlog/convert.go
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/sadco-io/sad-go-logger/logger/logfile"
+)
+
+func runConvert(args []string) error {
+	fs := flag.NewFlagSet("convert", flag.ExitOnError)
+	var ff filterFlags
+	ff.register(fs)
+	to := fs.String("to", "ndjson", "output format: "+strings.Join(logfile.Formats, ", "))
+	output := fs.String("o", "", "output file (default stdout)")
+	columns := fs.String("columns", "", "comma-separated extra CSV columns")
+	allColumns := fs.Bool("all-columns", false, "use every field found in the input as a CSV column")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: sadlog convert --to <format> [flags] [file ...]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	filter, err := ff.filter()
+	if err != nil {
+		return err
+	}
+
+	files := fs.Args()
+	if len(files) == 0 {
+		files = []string{defaultLogFile()}
+	}
+
+	var cols []string
+	if *columns != "" {
+		cols = strings.Split(*columns, ",")
+	}
+	if *allColumns {
+		if cols, err = collectColumns(files, filter); err != nil {
+			return err
+		}
+	}
+
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	conv, err := logfile.NewConverter(*to, out, cols)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		err := logfile.ScanFile(file, func(r logfile.Record) error {
+			if !filter.Match(r) {
+				return nil
+			}
+			return conv.Write(r)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return conv.Flush()
+}
+
+// collectColumns returns the sorted set of field keys, other than the
+// timestamp, level and message, of the matching records in files.
+func collectColumns(files []string, filter logfile.Filter) ([]string, error) {
+	seen := map[string]bool{}
+	for _, file := range files {
+		err := logfile.ScanFile(file, func(r logfile.Record) error {
+			if filter.Match(r) {
+				for key := range r {
+					seen[key] = true
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cols := make([]string, 0, len(seen))
+	for key := range seen {
+		if !hiddenKeys[key] {
+			cols = append(cols, key)
+		}
+	}
+	sort.Strings(cols)
+	return cols, nil
+}
@@ -0,0 +1,112 @@
+// sad-go-logger/cmd/sadlog/filter.go
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sadco-io/sad-go-logger/logger/logfile"
+	"go.uber.org/zap/zapcore"
+)
+
+// fieldFlags collects repeated --field key=value flags.
+type fieldFlags map[string]string
+
+func (f fieldFlags) String() string {
+	pairs := make([]string, 0, len(f))
+	for k, v := range f {
+		pairs = append(pairs, k+"="+v)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (f fieldFlags) Set(s string) error {
+	key, value, ok := strings.Cut(s, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("expected key=value, got %q", s)
+	}
+	f[key] = value
+	return nil
+}
+
+// filterFlags are the record selection flags shared by the subcommands.
+type filterFlags struct {
+	level  string
+	since  string
+	until  string
+	grep   string
+	fields fieldFlags
+}
+
+// register adds the filter flags to fs.
+func (ff *filterFlags) register(fs *flag.FlagSet) {
+	ff.fields = fieldFlags{}
+	fs.StringVar(&ff.level, "level", "debug", "minimum level to show (debug, info, warn, error, fatal, panic)")
+	fs.StringVar(&ff.since, "since", "", "only entries newer than a duration (e.g. 15m) or a timestamp")
+	fs.StringVar(&ff.until, "until", "", "only entries older than a duration (e.g. 15m) or a timestamp")
+	fs.StringVar(&ff.grep, "grep", "", "only entries whose message matches this regular expression")
+	fs.Var(ff.fields, "field", "only entries with field key=value (repeatable)")
+}
+
+// filter builds the logfile.Filter described by the flags.
+func (ff *filterFlags) filter() (logfile.Filter, error) {
+	var f logfile.Filter
+	var err error
+
+	if err := f.MinLevel.UnmarshalText([]byte(ff.level)); err != nil {
+		return f, fmt.Errorf("invalid --level: %v", err)
+	}
+	if f.Since, err = parseTimeBound(ff.since); err != nil {
+		return f, fmt.Errorf("invalid --since: %v", err)
+	}
+	if f.Until, err = parseTimeBound(ff.until); err != nil {
+		return f, fmt.Errorf("invalid --until: %v", err)
+	}
+	if ff.grep != "" {
+		if f.Grep, err = regexp.Compile(ff.grep); err != nil {
+			return f, fmt.Errorf("invalid --grep: %v", err)
+		}
+	}
+	if len(ff.fields) > 0 {
+		f.Fields = ff.fields
+	}
+	return f, nil
+}
+
+// parseTimeBound parses a duration relative to now, or an absolute
+// timestamp in one of the layouts used by the logger.
+func parseTimeBound(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	if t, ok := (logfile.Record{logfile.TimeKey: s}).Time(); ok {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%q is neither a duration nor a timestamp", s)
+}
+
+// levelColor returns the ANSI color sequence used for level.
+func levelColor(level zapcore.Level) string {
+	switch {
+	case level <= zapcore.DebugLevel:
+		return "\x1b[35m" // magenta
+	case level == zapcore.InfoLevel:
+		return "\x1b[34m" // blue
+	case level == zapcore.WarnLevel:
+		return "\x1b[33m" // yellow
+	case level == zapcore.ErrorLevel:
+		return "\x1b[31m" // red
+	default:
+		return "\x1b[1;31m" // bold red
+	}
+}
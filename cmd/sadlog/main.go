@@ -0,0 +1,67 @@
+// sad-go-logger/cmd/sadlog/main.go
+
+// Command sadlog reads the JSON log files written by the logger package.
+//
+// Usage:
+//
+//	sadlog [flags] [file ...]
+//
+// Without a subcommand, sadlog pretty-prints the given files (logs.txt in
+// the log directory of SERVICE_NAME by default), optionally following them
+// as they grow. The convert subcommand transforms them to other formats for
+// bulk import.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// command is a sadlog subcommand.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+// commands are the available subcommands, in the order they are listed in
+// the usage message.
+var commands = []command{
+	{name: "tail", summary: "pretty-print and follow log files (default)", run: runTail},
+	{name: "search", summary: "search a log file through a full-text index", run: runSearch},
+	{name: "convert", summary: "convert log files to csv, ecs, logfmt or ndjson", run: runConvert},
+	{name: "replay", summary: "reship log files to a remote sink", run: runReplay},
+}
+
+func main() {
+	args := os.Args[1:]
+	run := runTail
+	if len(args) > 0 {
+		if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+			usage()
+			return
+		}
+		for _, cmd := range commands {
+			if args[0] == cmd.name {
+				run, args = cmd.run, args[1:]
+				break
+			}
+		}
+	}
+
+	if err := run(args); err != nil {
+		fmt.Fprintf(os.Stderr, "sadlog: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: sadlog [command] [flags] [file ...]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Run 'sadlog <command> -h' for the flags of a command.")
+}
@@ -0,0 +1,52 @@
+// sad-go-logger/cmd/sadlog/replay.go
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sadco-io/sad-go-logger/logger"
+)
+
+func runReplay(args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	var ff filterFlags
+	ff.register(fs)
+	names := strings.Join(logger.RemoteSinkNames(), "|")
+	sinkName := fs.String("sink", "", "destination: "+names+", configured from the usual environment variables")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: sadlog replay --sink <%s> [flags] [file ...]\n", names)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	filter, err := ff.filter()
+	if err != nil {
+		return err
+	}
+
+	files := fs.Args()
+	if len(files) == 0 {
+		files = []string{defaultLogFile()}
+	}
+
+	for _, file := range files {
+		sink, err := logger.NewRemoteSink(*sinkName)
+		if err != nil {
+			return err
+		}
+		if sink == nil {
+			return fmt.Errorf("%s sink is not configured", *sinkName)
+		}
+
+		count, err := logger.Replay(file, sink, filter)
+		if err != nil {
+			return fmt.Errorf("replaying %s: %v (after %d entries)", file, err, count)
+		}
+		fmt.Fprintf(os.Stderr, "replayed %d entries from %s\n", count, file)
+	}
+	return nil
+}
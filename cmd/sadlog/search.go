@@ -0,0 +1,87 @@
+// sad-go-logger/cmd/sadlog/search.go
+
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sadco-io/sad-go-logger/logger/logfile"
+)
+
+func runSearch(args []string) error {
+	fs := flag.NewFlagSet("search", flag.ExitOnError)
+	var ff filterFlags
+	ff.register(fs)
+	text := fs.String("text", "", "words that must all appear in the message")
+	limit := fs.Int("limit", 100, "maximum number of entries (0 for all)")
+	indexFields := fs.String("index-fields", "", "comma-separated fields to index for --field lookups")
+	db := fs.String("db", "", "search the SQLite store at this path instead of a log file (requires -tags logsqlite)")
+	noColor := fs.Bool("no-color", false, "disable colors (default when stdout is not a terminal)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: sadlog search [flags] [file]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	filter, err := ff.filter()
+	if err != nil {
+		return err
+	}
+
+	query := logfile.Query{
+		Text:     *text,
+		MinLevel: filter.MinLevel,
+		Since:    filter.Since,
+		Until:    filter.Until,
+		Fields:   filter.Fields,
+		Grep:     filter.Grep,
+		Limit:    *limit,
+	}
+
+	var results []logfile.Record
+	if *db != "" {
+		store, err := logfile.OpenStore(*db, logfile.StoreOptions{})
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+		if results, err = store.Search(query); err != nil {
+			return err
+		}
+	} else {
+		file := defaultLogFile()
+		if fs.NArg() > 0 {
+			file = fs.Arg(0)
+		}
+
+		var opts logfile.IndexOptions
+		if *indexFields != "" {
+			opts.Fields = strings.Split(*indexFields, ",")
+		} else {
+			for key := range filter.Fields {
+				opts.Fields = append(opts.Fields, key)
+			}
+		}
+		idx, err := logfile.OpenIndex(file, opts)
+		if err != nil {
+			return err
+		}
+		if results, err = idx.Search(query); err != nil {
+			return err
+		}
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+	p := &printer{out: out, color: !*noColor && isTerminal(os.Stdout)}
+
+	// Results are most recent first; print them in chronological order.
+	for i := len(results) - 1; i >= 0; i-- {
+		p.print(results[i])
+	}
+	return nil
+}
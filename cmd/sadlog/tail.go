@@ -0,0 +1,172 @@
+// sad-go-logger/cmd/sadlog/tail.go
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sadco-io/sad-go-logger/logger/logfile"
+)
+
+// defaultLogFile returns the file read when no file is given: logs.txt, or
+// LOG_FILE_NAME if set, or the latest of the files rotated on a schedule,
+// in the log directory of SERVICE_NAME, see logfile.FindFile.
+func defaultLogFile() string {
+	name := os.Getenv("LOG_FILE_NAME")
+	if name == "" {
+		name = "logs.txt"
+	}
+	return logfile.FindFile(os.Getenv("SERVICE_NAME"), name)
+}
+
+// hiddenKeys are printed as part of the entry header rather than as
+// trailing fields.
+var hiddenKeys = map[string]bool{
+	logfile.MessageKey: true,
+	logfile.LevelKey:   true,
+	logfile.TimeKey:    true,
+}
+
+func runTail(args []string) error {
+	fs := flag.NewFlagSet("tail", flag.ExitOnError)
+	var ff filterFlags
+	ff.register(fs)
+	follow := fs.Bool("f", false, "follow the files as they grow")
+	noColor := fs.Bool("no-color", false, "disable colors (default when stdout is not a terminal)")
+	showHost := fs.Bool("host", false, "also print the hostname and serviceName fields")
+	fs.Parse(args)
+
+	filter, err := ff.filter()
+	if err != nil {
+		return err
+	}
+
+	files := fs.Args()
+	if len(files) == 0 {
+		files = []string{defaultLogFile()}
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+	p := &printer{out: out, color: !*noColor && isTerminal(os.Stdout), showHost: *showHost}
+
+	print := func(r logfile.Record) error {
+		if filter.Match(r) {
+			p.print(r)
+		}
+		return nil
+	}
+
+	if !*follow {
+		for _, file := range files {
+			if err := logfile.ScanFile(file, print); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(files))
+	for _, file := range files {
+		wg.Add(1)
+		go func(file string) {
+			defer wg.Done()
+			errs <- logfile.Follow(ctx, file, true, 250*time.Millisecond, func(r logfile.Record) error {
+				err := print(r)
+				p.flush()
+				return err
+			})
+		}(file)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printer renders records as colored, human-readable lines.
+type printer struct {
+	mu       sync.Mutex
+	out      *bufio.Writer
+	color    bool
+	showHost bool
+}
+
+func (p *printer) print(r logfile.Record) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	level := r.Level()
+	ts, _ := r[logfile.TimeKey].(string)
+	if ts != "" {
+		p.write("\x1b[2m", ts)
+		p.out.WriteByte(' ')
+	}
+	p.write(levelColor(level), fmt.Sprintf("%-5s", strings.ToUpper(level.String())))
+	p.out.WriteByte(' ')
+	p.out.WriteString(r.Message())
+
+	keys := make([]string, 0, len(r))
+	for key := range r {
+		if hiddenKeys[key] || (!p.showHost && (key == "hostname" || key == "serviceName")) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		p.out.WriteByte(' ')
+		p.write("\x1b[36m", key+"=")
+		value, _ := r.Field(key)
+		if _, isString := r[key].(string); isString && strings.ContainsAny(value, " \t\n\"") {
+			value = fmt.Sprintf("%q", value)
+		}
+		p.out.WriteString(value)
+	}
+	p.out.WriteByte('\n')
+}
+
+func (p *printer) flush() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.out.Flush()
+}
+
+// write writes s wrapped in the given color when colors are enabled.
+func (p *printer) write(color, s string) {
+	if !p.color {
+		p.out.WriteString(s)
+		return
+	}
+	p.out.WriteString(color)
+	p.out.WriteString(s)
+	p.out.WriteString("\x1b[0m")
+}
+
+// isTerminal reports whether w is a character device.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
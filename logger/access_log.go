@@ -0,0 +1,206 @@
+// sad-go-logger/logger/access_log.go
+
+package logger
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// accessFileName is the file written by the access log.
+const accessFileName = "access.txt"
+
+// AccessRecord describes one request served, as written to the access log.
+type AccessRecord struct {
+	Time       time.Time
+	Method     string
+	URI        string
+	Proto      string
+	Status     int
+	Bytes      int64
+	Duration   time.Duration
+	RemoteAddr string
+	Host       string
+	User       string
+	Referer    string
+	UserAgent  string
+	RequestID  string
+}
+
+// fields returns the record as structured fields.
+func (r AccessRecord) fields() []zap.Field {
+	fields := []zap.Field{
+		zap.String("method", r.Method),
+		zap.String("uri", r.URI),
+		zap.String("proto", r.Proto),
+		zap.Int("status", r.Status),
+		zap.Int64("bytes", r.Bytes),
+		zap.Duration("duration", r.Duration),
+		zap.String("remoteAddr", r.RemoteAddr),
+		zap.String("host", r.Host),
+	}
+	if r.User != "" {
+		fields = append(fields, zap.String("user", r.User))
+	}
+	if r.Referer != "" {
+		fields = append(fields, zap.String("referer", r.Referer))
+	}
+	if r.UserAgent != "" {
+		fields = append(fields, zap.String("userAgent", r.UserAgent))
+	}
+	if r.RequestID != "" {
+		fields = append(fields, zap.String(RequestIDKey, r.RequestID))
+	}
+	return fields
+}
+
+// combined returns the record in the Apache combined log format.
+func (r AccessRecord) combined() string {
+	host := r.RemoteAddr
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	dash := func(s string) string {
+		if s == "" {
+			return "-"
+		}
+		return s
+	}
+	return fmt.Sprintf("%s - %s [%s] %q %d %d %q %q\n",
+		dash(host), dash(r.User), r.Time.Format("02/Jan/2006:15:04:05 -0700"),
+		r.Method+" "+r.URI+" "+r.Proto, r.Status, r.Bytes, dash(r.Referer), dash(r.UserAgent))
+}
+
+// accessLog is the access-log pipeline, separate from the application log.
+type accessLog struct {
+	file       *rotatingFile
+	combined   bool
+	core       zapcore.Core // JSON file (unless combined) and remote sinks
+	sampleRate float64
+}
+
+var (
+	accessOnce sync.Once
+	access     *accessLog
+	accessErr  error
+)
+
+// newAccessLog builds the access log from the environment, see LogAccess.
+func newAccessLog() (*accessLog, error) {
+	format := os.Getenv("LOG_ACCESS_FORMAT")
+	if format != "" && format != "json" && format != "combined" {
+		return nil, fmt.Errorf("unknown LOG_ACCESS_FORMAT %q, expected json or combined", format)
+	}
+	rotation, err := rotationFromEnv("LOG_ACCESS", Rotation{MaxSize: 100 << 20, MaxBackups: 5})
+	if err != nil {
+		return nil, err
+	}
+	sampleRate := 1.0
+	if s := os.Getenv("LOG_ACCESS_SAMPLE_RATE"); s != "" {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid LOG_ACCESS_SAMPLE_RATE: %v", err)
+		}
+		sampleRate = v
+	}
+
+	file, err := openRotatingFile(logPath(accessFileName), 0644, rotation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open access log: %v", err)
+	}
+
+	a := &accessLog{file: file, combined: format == "combined", sampleRate: sampleRate}
+	encoder := zapcore.NewJSONEncoder(globalEncoderConfig())
+	var cores []zapcore.Core
+	if !a.combined {
+		cores = append(cores, zapcore.NewCore(encoder, file, zapcore.DebugLevel))
+	}
+	for _, w := range remoteWritersFromEnv("ACCESS") {
+		cores = append(cores, zapcore.NewCore(encoder, zapcore.AddSync(w), zapcore.DebugLevel))
+	}
+	a.core = zapcore.NewTee(cores...).With([]zap.Field{
+		zap.String("hostname", hostname),
+		zap.String("serviceName", serviceName),
+	})
+	return a, nil
+}
+
+// LogAccess writes rec to the access log, a pipeline separate from the
+// application log so that request logs can follow their own sampling and
+// retention policy. It is used by AccessLogMiddleware, and is available to
+// other servers such as gRPC interceptors. The access log is configured at
+// first use by:
+//   - LOG_ACCESS_FORMAT: "json" (default) or "combined" (Apache combined)
+//     for access.txt in the log directory; remote destinations always receive JSON
+//   - LOG_ACCESS_MAX_SIZE_MB: size at which the file is rotated (default: 100)
+//   - LOG_ACCESS_MAX_BACKUPS: rotated files kept (default: 5)
+//   - LOG_ACCESS_SAMPLE_RATE: fraction of successful requests logged
+//     (default: 1); requests with a status of 400 or above are always logged
+//   - ACCESS_LOGSTASH_HOST, ACCESS_LOGSTASH_PORT and ACCESS_LOGSTASH_USE_TLS
+//   - ACCESS_NEW_RELIC_API_KEY and ACCESS_NEW_RELIC_LOGS_ENDPOINT
+func LogAccess(rec AccessRecord) error {
+	accessOnce.Do(func() {
+		access, accessErr = newAccessLog()
+		if accessErr != nil {
+			Log.Warn("Access log disabled", zap.Error(accessErr))
+		}
+	})
+	if accessErr != nil {
+		return accessErr
+	}
+
+	if rec.Status < 400 && access.sampleRate < 1 && rand.Float64() >= access.sampleRate {
+		return nil
+	}
+	if rec.Time.IsZero() {
+		rec.Time = time.Now()
+	}
+
+	if access.combined {
+		if _, err := access.file.Write([]byte(rec.combined())); err != nil {
+			return fmt.Errorf("failed to write access log: %v", err)
+		}
+	}
+	ent := zapcore.Entry{Level: zapcore.InfoLevel, Time: rec.Time, Message: "access"}
+	return access.core.Write(ent, rec.fields())
+}
+
+// AccessLogMiddleware writes every request served by next to the access
+// log, see LogAccess.
+func AccessLogMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		user, _, _ := r.BasicAuth()
+		requestID := RequestIDFromContext(r.Context())
+		if requestID == "" {
+			requestID = rec.Header().Get(RequestIDHeader)
+		}
+		LogAccess(AccessRecord{
+			Time:       start,
+			Method:     r.Method,
+			URI:        r.RequestURI,
+			Proto:      r.Proto,
+			Status:     rec.status,
+			Bytes:      rec.bytes,
+			Duration:   time.Since(start),
+			RemoteAddr: r.RemoteAddr,
+			Host:       r.Host,
+			User:       user,
+			Referer:    r.Referer(),
+			UserAgent:  r.UserAgent(),
+			RequestID:  requestID,
+		})
+	})
+}
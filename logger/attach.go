@@ -0,0 +1,121 @@
+// sad-go-logger/logger/attach.go
+
+package logger
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// SinkID identifies a sink added with AttachSink.
+type SinkID uint64
+
+// attachedSink is a sink added at runtime.
+type attachedSink struct {
+	id   SinkID
+	core zapcore.Core
+}
+
+// attachedSinks are the sinks added at runtime to the loggers of a
+// builtLogger. The list is replaced, never modified, so that writes in
+// progress can range over it without locking.
+type attachedSinks struct {
+	mu     sync.Mutex
+	list   atomic.Pointer[[]attachedSink]
+	nextID SinkID
+}
+
+// AttachSink adds sink to the global Log, and to every logger sharing its
+// sinks such as the request loggers, while the service is running, e.g. to
+// temporarily send entries to a debugging destination. sink is either a
+// zapcore.Core, or a RemoteSyncWriter or any other io.Writer receiving the
+// entries as JSON. It receives the entries enabled by the logger's level
+// and its own, with the fields added by Logger.With, but not the entries
+// dropped by sampling or filters. It returns the id that DetachSink takes.
+func AttachSink(sink interface{}) (SinkID, error) {
+	g := current()
+	if g == nil {
+		return 0, fmt.Errorf("logger not initialized")
+	}
+
+	core, err := sinkCore(sink, zapcore.DebugLevel)
+	if err != nil {
+		return 0, err
+	}
+	return g.attached.add(core), nil
+}
+
+// sinkCore returns the core of a sink given to AttachSink, writing the
+// entries enabled by level unless sink is a zapcore.Core.
+func sinkCore(sink interface{}, level zapcore.LevelEnabler) (zapcore.Core, error) {
+	switch s := sink.(type) {
+	case zapcore.Core:
+		return s, nil
+	case zapcore.WriteSyncer:
+		return zapcore.NewCore(zapcore.NewJSONEncoder(newEncoderConfig()), s, level), nil
+	case io.Writer:
+		return zapcore.NewCore(zapcore.NewJSONEncoder(newEncoderConfig()), zapcore.AddSync(s), level), nil
+	default:
+		return nil, fmt.Errorf("unsupported sink type %T, expected a zapcore.Core or io.Writer", sink)
+	}
+}
+
+// DetachSink removes a sink added with AttachSink, syncing it first. The
+// sink is not closed.
+func DetachSink(id SinkID) error {
+	g := current()
+	if g == nil {
+		return fmt.Errorf("logger not initialized")
+	}
+	core, ok := g.attached.remove(id)
+	if !ok {
+		return fmt.Errorf("no attached sink %d", id)
+	}
+	return core.Sync()
+}
+
+func (a *attachedSinks) add(core zapcore.Core) SinkID {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.nextID++
+	list := append([]attachedSink(nil), a.cores()...)
+	list = append(list, attachedSink{id: a.nextID, core: core})
+	a.list.Store(&list)
+	return a.nextID
+}
+
+func (a *attachedSinks) remove(id SinkID) (zapcore.Core, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var removed zapcore.Core
+	list := make([]attachedSink, 0, len(a.cores()))
+	for _, s := range a.cores() {
+		if s.id == id {
+			removed = s.core
+		} else {
+			list = append(list, s)
+		}
+	}
+	if removed == nil {
+		return nil, false
+	}
+	a.list.Store(&list)
+	return removed, true
+}
+
+// cores returns the attached sinks. a may be nil.
+func (a *attachedSinks) cores() []attachedSink {
+	if a == nil {
+		return nil
+	}
+	if list := a.list.Load(); list != nil {
+		return *list
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+// sad-go-logger/logger/audit.go
+
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// auditFileName is the append-only file written by Audit.
+const auditFileName = "audit.txt"
+
+// AuditFields are the fields every audit event must carry, as non-empty
+// strings (zap.String).
+var AuditFields = []string{"actor", "action", "target", "outcome"}
+
+// AuditOutcomes are the accepted values of the outcome field.
+var AuditOutcomes = []string{"success", "failure", "denied"}
+
+// auditReservedKeys may not be used as audit fields.
+var auditReservedKeys = map[string]bool{"message": true, "level": true, "datetime": true, "event": true}
+
+var (
+	auditOnce sync.Once
+	auditCore zapcore.Core
+	auditErr  error
+)
+
+// Audit records a compliance event, such as a permission change or a login,
+// in audit.txt in the log directory, away from the application log. Every
+// event must carry the actor, action, target and outcome fields:
+//
+//	logger.Audit("role.granted",
+//		zap.String("actor", admin), zap.String("action", "grant"),
+//		zap.String("target", user), zap.String("outcome", "success"))
+//
+// Events that do not match the schema are rejected with an error and not
+// written. The file is opened in append-only mode and synced to disk after
+// every event. Audit events skip the level, sampling, hooks and drop filters
+// of the application logger, and are sent to their own remote destination
+// only, configured by:
+//   - AUDIT_LOGSTASH_HOST, AUDIT_LOGSTASH_PORT and AUDIT_LOGSTASH_USE_TLS
+//   - AUDIT_NEW_RELIC_API_KEY and AUDIT_NEW_RELIC_LOGS_ENDPOINT
+func Audit(event string, fields ...zap.Field) error {
+	if err := validateAudit(event, fields); err != nil {
+		return err
+	}
+
+	auditOnce.Do(func() {
+		auditCore, auditErr = newAuditCore()
+	})
+	if auditErr != nil {
+		return auditErr
+	}
+
+	now := time.Now()
+	if globalConfig.Clock != nil {
+		now = globalConfig.Clock.Now()
+	}
+	ent := zapcore.Entry{Level: zapcore.InfoLevel, Time: now, Message: event}
+	all := append([]zap.Field{zap.String("event", event)}, fields...)
+	if err := auditCore.Write(ent, all); err != nil {
+		return fmt.Errorf("failed to write audit event %q: %v", event, err)
+	}
+	return nil
+}
+
+// validateAudit checks an audit event against the schema.
+func validateAudit(event string, fields []zap.Field) error {
+	if event == "" {
+		return fmt.Errorf("invalid audit event: empty event name")
+	}
+
+	values := map[string]string{}
+	for _, f := range fields {
+		if auditReservedKeys[f.Key] {
+			return fmt.Errorf("invalid audit event %q: reserved field %q", event, f.Key)
+		}
+		if _, ok := values[f.Key]; ok {
+			return fmt.Errorf("invalid audit event %q: duplicate field %q", event, f.Key)
+		}
+		values[f.Key] = ""
+		if f.Type == zapcore.StringType {
+			values[f.Key] = f.String
+		}
+	}
+
+	for _, key := range AuditFields {
+		if values[key] == "" {
+			return fmt.Errorf("invalid audit event %q: missing or non-string %s field", event, key)
+		}
+	}
+	for _, outcome := range AuditOutcomes {
+		if values["outcome"] == outcome {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid audit event %q: unknown outcome %q", event, values["outcome"])
+}
+
+// newAuditCore builds the core written by Audit.
+func newAuditCore() (zapcore.Core, error) {
+	file, err := openRotatingFile(logPath(auditFileName), 0640, Rotation{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %v", err)
+	}
+
+	encoder := zapcore.NewJSONEncoder(globalEncoderConfig())
+	cores := []zapcore.Core{
+		zapcore.NewCore(encoder, zapcore.Lock(&syncingWriter{file}), zapcore.DebugLevel),
+	}
+
+	for _, w := range remoteWritersFromEnv("AUDIT") {
+		cores = append(cores, zapcore.NewCore(encoder, &syncingWriter{w}, zapcore.DebugLevel))
+	}
+
+	return zapcore.NewTee(cores...).With([]zap.Field{
+		zap.String("hostname", hostname),
+		zap.String("serviceName", serviceName),
+	}), nil
+}
+
+// syncingWriter syncs its writer after every write, so that an audit event
+// is on disk, or handed to its remote destination, once Audit returns.
+type syncingWriter struct {
+	zapcore.WriteSyncer
+}
+
+func (w *syncingWriter) Write(p []byte) (int, error) {
+	n, err := w.WriteSyncer.Write(p)
+	if err != nil {
+		return n, err
+	}
+	return n, w.WriteSyncer.Sync()
+}
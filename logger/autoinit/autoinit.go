@@ -0,0 +1,16 @@
+// sad-go-logger/logger/autoinit/autoinit.go
+
+// Package autoinit initializes the global logger from the environment as
+// soon as it is imported, the way the logger package did before Init:
+//
+//	import _ "github.com/sadco-io/sad-go-logger/logger/autoinit"
+//
+// It panics if the logger cannot be built, e.g. when the log directory is
+// not writable.
+package autoinit
+
+import "github.com/sadco-io/sad-go-logger/logger"
+
+func init() {
+	logger.MustInit(logger.ConfigFromEnv())
+}
@@ -0,0 +1,152 @@
+// sad-go-logger/logger/avro.go
+
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sadco-io/sad-go-logger/logger/logfile"
+)
+
+// avroRecordName is the full name of the Avro record of log entries.
+const avroRecordName = "io.sadco.logger.LogEntry"
+
+// avroLogEntrySchema is the Avro schema of log entries: the columns of
+// logRow, with the other fields as strings.
+const avroLogEntrySchema = `{"type":"record","name":"LogEntry","namespace":"io.sadco.logger","fields":[` +
+	`{"name":"timestamp","type":{"type":"long","logicalType":"timestamp-millis"}},` +
+	`{"name":"level","type":"string"},` +
+	`{"name":"service","type":"string"},` +
+	`{"name":"host","type":"string"},` +
+	`{"name":"message","type":"string"},` +
+	`{"name":"fields","type":{"type":"map","values":"string"}}]}`
+
+// encodeAvroLogEntry appends the Avro binary encoding of row, following
+// avroLogEntrySchema, to b.
+func encodeAvroLogEntry(b []byte, row logRow) []byte {
+	b = appendAvroLong(b, row.Time.UnixMilli())
+	b = appendAvroString(b, row.Level)
+	b = appendAvroString(b, row.Service)
+	b = appendAvroString(b, row.Host)
+	b = appendAvroString(b, row.Message)
+
+	// The map is written as a single block, in key order so that the
+	// encoding of an entry is stable.
+	if len(row.Fields) > 0 {
+		keys := make([]string, 0, len(row.Fields))
+		for key := range row.Fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		b = appendAvroLong(b, int64(len(keys)))
+		for _, key := range keys {
+			b = appendAvroString(b, key)
+			b = appendAvroString(b, logfile.FormatValue(row.Fields[key]))
+		}
+	}
+	return appendAvroLong(b, 0)
+}
+
+// appendAvroLong appends v as a zig-zag varint, as Avro encodes int and
+// long values.
+func appendAvroLong(b []byte, v int64) []byte {
+	return binary.AppendVarint(b, v)
+}
+
+// appendAvroString appends s prefixed with its length.
+func appendAvroString(b []byte, s string) []byte {
+	b = appendAvroLong(b, int64(len(s)))
+	return append(b, s...)
+}
+
+// appendSchemaRegistryHeader appends the header of the Confluent wire
+// format, a zero magic byte and the schema ID, that precedes the Avro
+// payload.
+func appendSchemaRegistryHeader(b []byte, id int32) []byte {
+	b = append(b, 0)
+	return binary.BigEndian.AppendUint32(b, uint32(id))
+}
+
+// schemaSubject returns the subject the schema of the values of topic is
+// registered under with the given naming strategy: "topic" (TopicNameStrategy),
+// "record" (RecordNameStrategy) or "topic_record" (TopicRecordNameStrategy).
+func schemaSubject(strategy, topic string) (string, error) {
+	switch strategy {
+	case "", "topic":
+		return topic + "-value", nil
+	case "record":
+		return avroRecordName, nil
+	case "topic_record":
+		return topic + "-" + avroRecordName, nil
+	}
+	return "", fmt.Errorf("unknown subject name strategy %q, expected topic, record or topic_record", strategy)
+}
+
+// schemaRegistry is a client of the Confluent Schema Registry API.
+type schemaRegistry struct {
+	url      string
+	username string
+	password string
+	client   *http.Client
+}
+
+// newSchemaRegistry returns a client of the registry at base.
+func newSchemaRegistry(base, username, password string) *schemaRegistry {
+	return &schemaRegistry{
+		url:      strings.TrimRight(base, "/"),
+		username: username,
+		password: password,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// schemaID returns the ID of schema under subject. With register, the
+// schema is registered under subject if it is not already, which the
+// registry refuses if it is not compatible with the subject's earlier
+// versions. Otherwise, the schema must already be registered.
+func (r *schemaRegistry) schemaID(subject, schema string, register bool) (int32, error) {
+	path := "/subjects/" + url.PathEscape(subject)
+	if register {
+		path += "/versions"
+	}
+	body, err := json.Marshal(map[string]string{"schema": schema})
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequest("POST", r.url+path, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	if r.username != "" {
+		req.SetBasicAuth(r.username, r.password)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reach the schema registry: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		ID      int32  `json:"id"`
+		Message string `json:"message"`
+	}
+	json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result)
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return result.ID, nil
+	case resp.StatusCode == http.StatusNotFound && !register:
+		return 0, fmt.Errorf("schema not registered under subject %q, register it or set KAFKA_SCHEMA_AUTO_REGISTER=true", subject)
+	}
+	return 0, fmt.Errorf("schema registry rejected subject %q: status code %d: %s", subject, resp.StatusCode, result.Message)
+}
@@ -0,0 +1,156 @@
+// sad-go-logger/logger/backpressure.go
+
+package logger
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Pressure is the backpressure state of a remote sink's queue.
+type Pressure struct {
+	// Sink names the destination, as in the logger's warnings.
+	Sink string
+
+	// Queued is the number of entries waiting to be sent, and Capacity the
+	// number beyond which the oldest are dropped.
+	Queued   int
+	Capacity int
+
+	// High is set once the queue fills past the backpressure threshold,
+	// see LOG_BACKPRESSURE_THRESHOLD, and cleared once it is back under
+	// half of it.
+	High bool
+
+	// Down is set while the destination is unreachable: its last batch
+	// still failed after the retries. Err holds the failure.
+	Down bool
+	Err  error
+}
+
+// Fill returns the fraction of the queue in use, from 0 to 1.
+func (p Pressure) Fill() float64 {
+	if p.Capacity <= 0 {
+		return 0
+	}
+	return float64(p.Queued) / float64(p.Capacity)
+}
+
+// pressureReporter is implemented by the remote sinks reporting their
+// backpressure, see Pressure.
+type pressureReporter interface {
+	Pressure() Pressure
+}
+
+// registeredPressureFunc is a backpressure callback and the id that
+// removes it.
+type registeredPressureFunc struct {
+	id uint64
+	fn func(Pressure)
+}
+
+var (
+	pressureMu    sync.RWMutex
+	pressureFuncs []registeredPressureFunc
+	pressureID    uint64
+
+	// pressureSinks are the sinks reporting their pressure, until closed.
+	pressureSinks = map[*batchWriter]struct{}{}
+
+	pressureThresholdOnce sync.Once
+	pressureThreshold     = 80
+)
+
+// OnBackpressure registers fn to be called whenever a remote sink's High or
+// Down state changes, so that applications can shed their own optional
+// logging or raise an alert before entries are dropped. fn is called from
+// the logging or sending goroutine and must not block. It returns a
+// function removing the callback.
+//
+// Backpressure is reported by the sinks that queue entries, which includes
+// the HTTP, database, socket and gRPC sinks but not the ELK and New Relic
+// writers.
+func OnBackpressure(fn func(Pressure)) (remove func()) {
+	pressureMu.Lock()
+	defer pressureMu.Unlock()
+
+	pressureID++
+	id := pressureID
+	pressureFuncs = append(pressureFuncs, registeredPressureFunc{id: id, fn: fn})
+
+	return func() {
+		pressureMu.Lock()
+		defer pressureMu.Unlock()
+		// Copy the list: notifications in progress may still range over it.
+		list := make([]registeredPressureFunc, 0, len(pressureFuncs))
+		for _, f := range pressureFuncs {
+			if f.id != id {
+				list = append(list, f)
+			}
+		}
+		pressureFuncs = list
+	}
+}
+
+// Backpressure returns the current state of every open sink reporting its
+// pressure, by sink name.
+func Backpressure() []Pressure {
+	pressureMu.RLock()
+	sinks := make([]*batchWriter, 0, len(pressureSinks))
+	for w := range pressureSinks {
+		sinks = append(sinks, w)
+	}
+	pressureMu.RUnlock()
+
+	states := make([]Pressure, len(sinks))
+	for i, w := range sinks {
+		states[i] = w.Pressure()
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Sink < states[j].Sink })
+	return states
+}
+
+// notifyPressure calls the registered callbacks.
+func notifyPressure(p Pressure) {
+	pressureMu.RLock()
+	funcs := pressureFuncs
+	pressureMu.RUnlock()
+	for _, f := range funcs {
+		f.fn(p)
+	}
+}
+
+// trackPressure adds w to the sinks listed by Backpressure until
+// untrackPressure.
+func trackPressure(w *batchWriter) {
+	pressureMu.Lock()
+	defer pressureMu.Unlock()
+	pressureSinks[w] = struct{}{}
+}
+
+func untrackPressure(w *batchWriter) {
+	pressureMu.Lock()
+	defer pressureMu.Unlock()
+	delete(pressureSinks, w)
+}
+
+// backpressureThreshold returns the queue fill, in percent, at which a sink
+// is under pressure, from LOG_BACKPRESSURE_THRESHOLD (default: 80).
+func backpressureThreshold() int {
+	pressureThresholdOnce.Do(func() {
+		s := os.Getenv("LOG_BACKPRESSURE_THRESHOLD")
+		if s == "" {
+			return
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 || n > 100 {
+			fmt.Printf("Invalid LOG_BACKPRESSURE_THRESHOLD: %s. Using 80.\n", s)
+			return
+		}
+		pressureThreshold = n
+	})
+	return pressureThreshold
+}
@@ -0,0 +1,52 @@
+// sad-go-logger/logger/baggage.go
+
+package logger
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/baggage"
+	"go.uber.org/zap"
+)
+
+// baggageKeys is the allowlist of the OpenTelemetry baggage members copied
+// into log fields.
+var baggageKeys atomic.Pointer[[]string]
+
+// SetBaggageFields sets the OpenTelemetry baggage members, by key, that are
+// copied from the context into log fields, such as a tenant or experiment
+// bucket propagated across services. No members are copied by default;
+// LOG_BAGGAGE_FIELDS sets the initial list, comma-separated.
+func SetBaggageFields(keys ...string) {
+	list := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if key = strings.TrimSpace(key); key != "" {
+			list = append(list, key)
+		}
+	}
+	baggageKeys.Store(&list)
+}
+
+// BaggageFields returns the allowed baggage members of ctx as string
+// fields named after their keys. Members absent from the baggage are
+// skipped.
+func BaggageFields(ctx context.Context) []zap.Field {
+	keys := baggageKeys.Load()
+	if keys == nil || len(*keys) == 0 {
+		return nil
+	}
+	b := baggage.FromContext(ctx)
+	if b.Len() == 0 {
+		return nil
+	}
+
+	var fields []zap.Field
+	for _, key := range *keys {
+		if m := b.Member(key); m.Key() != "" {
+			fields = append(fields, zap.String(key, m.Value()))
+		}
+	}
+	return fields
+}
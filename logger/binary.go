@@ -0,0 +1,56 @@
+// sad-go-logger/logger/binary.go
+
+package logger
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// DefaultBinaryFieldSize is the maximum number of bytes of a payload that
+// Binary base64-encodes.
+const DefaultBinaryFieldSize = 1024
+
+// binaryPayload renders a byte slice as a structured object suitable for
+// JSON sinks.
+type binaryPayload struct {
+	data []byte
+	max  int
+}
+
+// MarshalLogObject implements zapcore.ObjectMarshaler.
+func (b binaryPayload) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	sum := sha256.Sum256(b.data)
+	enc.AddInt("length", len(b.data))
+	enc.AddString("sha256", hex.EncodeToString(sum[:]))
+
+	data := b.data
+	limit := b.max
+	if limit < 0 {
+		limit = 0
+	}
+	if len(data) > limit {
+		data = data[:limit]
+		enc.AddBool("truncated", true)
+	}
+	enc.AddString("base64", base64.StdEncoding.EncodeToString(data))
+	return nil
+}
+
+// Binary constructs a field that logs a small binary blob (message payloads,
+// signatures, ...) as an object containing its length, its SHA-256 checksum
+// and its base64 encoding, truncated to DefaultBinaryFieldSize bytes.
+func Binary(key string, data []byte) zap.Field {
+	return BinaryN(key, data, DefaultBinaryFieldSize)
+}
+
+// BinaryN is like Binary but base64-encodes at most max bytes of data.
+// Larger payloads are truncated; the length and checksum fields always
+// describe the full payload.
+func BinaryN(key string, data []byte, max int) zap.Field {
+	return zap.Object(key, binaryPayload{data: data, max: max})
+}
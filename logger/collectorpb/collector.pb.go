@@ -0,0 +1,246 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: collectorpb/collector.proto
+
+package collectorpb
+
+import (
+	logpb "github.com/sadco-io/sad-go-logger/logger/logpb"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// LogBatch is a batch of entries.
+type LogBatch struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// sequence numbers the batches of a stream, from 1.
+	Sequence uint64            `protobuf:"varint,1,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Entries  []*logpb.LogEntry `protobuf:"bytes,2,rep,name=entries,proto3" json:"entries,omitempty"`
+}
+
+func (x *LogBatch) Reset() {
+	*x = LogBatch{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_collectorpb_collector_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LogBatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogBatch) ProtoMessage() {}
+
+func (x *LogBatch) ProtoReflect() protoreflect.Message {
+	mi := &file_collectorpb_collector_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogBatch.ProtoReflect.Descriptor instead.
+func (*LogBatch) Descriptor() ([]byte, []int) {
+	return file_collectorpb_collector_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *LogBatch) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *LogBatch) GetEntries() []*logpb.LogEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+// BatchAck acknowledges a batch.
+type BatchAck struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Sequence uint64 `protobuf:"varint,1,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	// error, when set, reports that the batch was rejected.
+	Error string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (x *BatchAck) Reset() {
+	*x = BatchAck{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_collectorpb_collector_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchAck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchAck) ProtoMessage() {}
+
+func (x *BatchAck) ProtoReflect() protoreflect.Message {
+	mi := &file_collectorpb_collector_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchAck.ProtoReflect.Descriptor instead.
+func (*BatchAck) Descriptor() ([]byte, []int) {
+	return file_collectorpb_collector_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *BatchAck) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *BatchAck) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+var File_collectorpb_collector_proto protoreflect.FileDescriptor
+
+var file_collectorpb_collector_proto_rawDesc = []byte{
+	0x0a, 0x1b, 0x63, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x70, 0x62, 0x2f, 0x63, 0x6f,
+	0x6c, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x16, 0x73,
+	0x61, 0x64, 0x6c, 0x6f, 0x67, 0x67, 0x65, 0x72, 0x2e, 0x63, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74,
+	0x6f, 0x72, 0x2e, 0x76, 0x31, 0x1a, 0x15, 0x6c, 0x6f, 0x67, 0x70, 0x62, 0x2f, 0x6c, 0x6f, 0x67,
+	0x5f, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x5c, 0x0a, 0x08,
+	0x4c, 0x6f, 0x67, 0x42, 0x61, 0x74, 0x63, 0x68, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x65, 0x71, 0x75,
+	0x65, 0x6e, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x73, 0x65, 0x71, 0x75,
+	0x65, 0x6e, 0x63, 0x65, 0x12, 0x34, 0x0a, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x73, 0x61, 0x64, 0x6c, 0x6f, 0x67, 0x67, 0x65,
+	0x72, 0x2e, 0x6c, 0x6f, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x6f, 0x67, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x52, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x22, 0x3c, 0x0a, 0x08, 0x42, 0x61,
+	0x74, 0x63, 0x68, 0x41, 0x63, 0x6b, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e,
+	0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e,
+	0x63, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x32, 0x64, 0x0a, 0x0c, 0x4c, 0x6f, 0x67, 0x43,
+	0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x12, 0x54, 0x0a, 0x0a, 0x53, 0x74, 0x72, 0x65,
+	0x61, 0x6d, 0x4c, 0x6f, 0x67, 0x73, 0x12, 0x20, 0x2e, 0x73, 0x61, 0x64, 0x6c, 0x6f, 0x67, 0x67,
+	0x65, 0x72, 0x2e, 0x63, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x2e, 0x76, 0x31, 0x2e,
+	0x4c, 0x6f, 0x67, 0x42, 0x61, 0x74, 0x63, 0x68, 0x1a, 0x20, 0x2e, 0x73, 0x61, 0x64, 0x6c, 0x6f,
+	0x67, 0x67, 0x65, 0x72, 0x2e, 0x63, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x2e, 0x76,
+	0x31, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x41, 0x63, 0x6b, 0x28, 0x01, 0x30, 0x01, 0x42, 0x36,
+	0x5a, 0x34, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x73, 0x61, 0x64,
+	0x63, 0x6f, 0x2d, 0x69, 0x6f, 0x2f, 0x73, 0x61, 0x64, 0x2d, 0x67, 0x6f, 0x2d, 0x6c, 0x6f, 0x67,
+	0x67, 0x65, 0x72, 0x2f, 0x6c, 0x6f, 0x67, 0x67, 0x65, 0x72, 0x2f, 0x63, 0x6f, 0x6c, 0x6c, 0x65,
+	0x63, 0x74, 0x6f, 0x72, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_collectorpb_collector_proto_rawDescOnce sync.Once
+	file_collectorpb_collector_proto_rawDescData = file_collectorpb_collector_proto_rawDesc
+)
+
+func file_collectorpb_collector_proto_rawDescGZIP() []byte {
+	file_collectorpb_collector_proto_rawDescOnce.Do(func() {
+		file_collectorpb_collector_proto_rawDescData = protoimpl.X.CompressGZIP(file_collectorpb_collector_proto_rawDescData)
+	})
+	return file_collectorpb_collector_proto_rawDescData
+}
+
+var file_collectorpb_collector_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_collectorpb_collector_proto_goTypes = []any{
+	(*LogBatch)(nil),       // 0: sadlogger.collector.v1.LogBatch
+	(*BatchAck)(nil),       // 1: sadlogger.collector.v1.BatchAck
+	(*logpb.LogEntry)(nil), // 2: sadlogger.log.v1.LogEntry
+}
+var file_collectorpb_collector_proto_depIdxs = []int32{
+	2, // 0: sadlogger.collector.v1.LogBatch.entries:type_name -> sadlogger.log.v1.LogEntry
+	0, // 1: sadlogger.collector.v1.LogCollector.StreamLogs:input_type -> sadlogger.collector.v1.LogBatch
+	1, // 2: sadlogger.collector.v1.LogCollector.StreamLogs:output_type -> sadlogger.collector.v1.BatchAck
+	2, // [2:3] is the sub-list for method output_type
+	1, // [1:2] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_collectorpb_collector_proto_init() }
+func file_collectorpb_collector_proto_init() {
+	if File_collectorpb_collector_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_collectorpb_collector_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*LogBatch); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_collectorpb_collector_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*BatchAck); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_collectorpb_collector_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_collectorpb_collector_proto_goTypes,
+		DependencyIndexes: file_collectorpb_collector_proto_depIdxs,
+		MessageInfos:      file_collectorpb_collector_proto_msgTypes,
+	}.Build()
+	File_collectorpb_collector_proto = out.File
+	file_collectorpb_collector_proto_rawDesc = nil
+	file_collectorpb_collector_proto_goTypes = nil
+	file_collectorpb_collector_proto_depIdxs = nil
+}
@@ -0,0 +1,125 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: collectorpb/collector.proto
+
+package collectorpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	LogCollector_StreamLogs_FullMethodName = "/sadlogger.collector.v1.LogCollector/StreamLogs"
+)
+
+// LogCollectorClient is the client API for LogCollector service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// LogCollector receives log entries from the gRPC sink of the logger.
+type LogCollectorClient interface {
+	// StreamLogs receives batches of entries, and acknowledges each with its
+	// sequence number once it is stored. The client resends the batches that
+	// are not acknowledged.
+	StreamLogs(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[LogBatch, BatchAck], error)
+}
+
+type logCollectorClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLogCollectorClient(cc grpc.ClientConnInterface) LogCollectorClient {
+	return &logCollectorClient{cc}
+}
+
+func (c *logCollectorClient) StreamLogs(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[LogBatch, BatchAck], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &LogCollector_ServiceDesc.Streams[0], LogCollector_StreamLogs_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[LogBatch, BatchAck]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type LogCollector_StreamLogsClient = grpc.BidiStreamingClient[LogBatch, BatchAck]
+
+// LogCollectorServer is the server API for LogCollector service.
+// All implementations must embed UnimplementedLogCollectorServer
+// for forward compatibility.
+//
+// LogCollector receives log entries from the gRPC sink of the logger.
+type LogCollectorServer interface {
+	// StreamLogs receives batches of entries, and acknowledges each with its
+	// sequence number once it is stored. The client resends the batches that
+	// are not acknowledged.
+	StreamLogs(grpc.BidiStreamingServer[LogBatch, BatchAck]) error
+	mustEmbedUnimplementedLogCollectorServer()
+}
+
+// UnimplementedLogCollectorServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedLogCollectorServer struct{}
+
+func (UnimplementedLogCollectorServer) StreamLogs(grpc.BidiStreamingServer[LogBatch, BatchAck]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamLogs not implemented")
+}
+func (UnimplementedLogCollectorServer) mustEmbedUnimplementedLogCollectorServer() {}
+func (UnimplementedLogCollectorServer) testEmbeddedByValue()                      {}
+
+// UnsafeLogCollectorServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to LogCollectorServer will
+// result in compilation errors.
+type UnsafeLogCollectorServer interface {
+	mustEmbedUnimplementedLogCollectorServer()
+}
+
+func RegisterLogCollectorServer(s grpc.ServiceRegistrar, srv LogCollectorServer) {
+	// If the following call pancis, it indicates UnimplementedLogCollectorServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&LogCollector_ServiceDesc, srv)
+}
+
+func _LogCollector_StreamLogs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(LogCollectorServer).StreamLogs(&grpc.GenericServerStream[LogBatch, BatchAck]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type LogCollector_StreamLogsServer = grpc.BidiStreamingServer[LogBatch, BatchAck]
+
+// LogCollector_ServiceDesc is the grpc.ServiceDesc for LogCollector service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var LogCollector_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "sadlogger.collector.v1.LogCollector",
+	HandlerType: (*LogCollectorServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamLogs",
+			Handler:       _LogCollector_StreamLogs_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "collectorpb/collector.proto",
+}
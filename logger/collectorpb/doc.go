@@ -0,0 +1,8 @@
+// sad-go-logger/logger/collectorpb/doc.go
+
+// Package collectorpb holds the protocol of the gRPC sink of the logger
+// package, for implementing in-house log collectors: serve LogCollector and
+// acknowledge every LogBatch once its entries are stored.
+package collectorpb
+
+//go:generate protoc -I.. --go_out=.. --go_opt=paths=source_relative --go-grpc_out=.. --go-grpc_opt=paths=source_relative collectorpb/collector.proto
@@ -0,0 +1,769 @@
+// sad-go-logger/logger/config.go
+
+package logger
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Names of the log files written by Build, in the directory returned by
+// logDir.
+const (
+	logFileName   = "logs.txt"
+	errorFileName = "errors.txt"
+)
+
+// Default permissions of the log files and of the directories created for
+// them.
+const (
+	defaultFileMode os.FileMode = 0644
+	defaultDirMode  os.FileMode = 0755
+)
+
+// currentLogPath returns the path of the log file written now, stamped
+// with the current period if the files are rotated on a schedule.
+func currentLogPath() string {
+	path, _ := globalConfig.FileRotation.period(globalConfig.filePath(globalConfig.FileName, logFileName), time.Now())
+	return path
+}
+
+// Config holds the settings used to build a logger. The zero value is not
+// useful on its own; start from ConfigFromEnv and override what you need.
+type Config struct {
+	// ServiceName is attached to every entry as the serviceName field.
+	ServiceName string
+
+	// Level is the minimum level written to the console, file and remote
+	// sinks, in any form accepted by ParseLevel; empty means "debug".
+	// Build fails on an invalid level.
+	Level string
+
+	// LevelOverrides are the levels of the named loggers of Get, by name,
+	// applied to the loggers below them too: "storage" covers "storage" and
+	// "storage.s3", the longest name matching. The level of Log applies to
+	// the other loggers.
+	LevelOverrides map[string]string
+
+	// ConsoleFormat selects the console rendering: empty for one line per
+	// entry, "dev" to render stack traces and nested values as indented
+	// blocks under the entry, with stack traces captured for errors, or
+	// "json" for one JSON object per line, like the log files.
+	ConsoleFormat string
+
+	// Color renders the console, in the "" and "dev" formats, for a
+	// terminal: levels in ANSI colors, aligned columns and colored keys.
+	Color bool
+
+	// Caller adds the file and line of the logging call to every entry, as
+	// the caller field.
+	Caller bool
+
+	// CallerSkip is the number of stack frames skipped to find the caller,
+	// for the callers of helpers wrapping the logger to be reported rather
+	// than the helpers.
+	CallerSkip int
+
+	// StacktraceLevel is the minimum level of the entries a stack trace is
+	// attached to, as the stacktrace field, in any form accepted by
+	// ParseLevel, or "none". Empty means "error" in the "dev" console
+	// format, rendered on the console only, and none otherwise.
+	StacktraceLevel string
+
+	// TimeFormat is the format of the timestamps of the console, file and
+	// remote sinks: "epoch" (Unix epoch seconds, with a fraction), "millis"
+	// or "nanos" (Unix epoch milliseconds or nanoseconds), "rfc3339",
+	// "rfc3339nano", a Go layout such as "Jan _2 15:04:05", or empty for
+	// "2006-01-02 15:04:05.000".
+	TimeFormat string
+
+	// TimeZone is the zone timestamps are rendered in: "UTC", "Local" or
+	// an IANA name such as "Europe/Paris". It defaults to the zone of the
+	// Clock, the local zone for the system clock.
+	TimeZone string
+
+	// Clock timestamps entries. It defaults to the system clock; tests and
+	// simulations can inject a fixed or virtual clock such as ManualClock.
+	Clock zapcore.Clock
+
+	// Sampling, when set, caps the volume of repeated entries.
+	Sampling *SamplingConfig
+
+	// RingBufferSize, when positive, is the size in bytes of a ring
+	// buffer between the logging calls and the sinks: a logging call only
+	// encodes the entry and copies it into the ring, which a background
+	// goroutine drains to the console, files and remote sinks. Entries are
+	// dropped while the ring is full. Sync waits for the ring to drain.
+	RingBufferSize int
+
+	// DisableFile skips the log and error files and the SQLite store, for
+	// read-only filesystems such as containers logging to stdout: the log
+	// directory is then not created either, unless the access or audit log
+	// is used.
+	DisableFile bool
+
+	// Dir, when set, is the directory of the log and error files, created
+	// if needed, instead of the log directory of the other files, see
+	// logfile.Dirs.
+	Dir string
+
+	// FileName and ErrorFileName are the names of the log and error files,
+	// logs.txt and errors.txt when empty. A relative name, possibly with
+	// directories, is relative to the log directory; an absolute one is
+	// used as it is. Missing parent directories are created.
+	FileName      string
+	ErrorFileName string
+
+	// FileMode is the permission the log and error files are created with,
+	// 0644 when zero, and DirMode the one of the directories created for
+	// them, 0755 when zero.
+	FileMode os.FileMode
+	DirMode  os.FileMode
+
+	// FileRotation rotates the log and error files. The zero value lets
+	// them grow forever.
+	FileRotation Rotation
+
+	// SinkMappings adapt the entries of the sinks to their native
+	// vocabulary, by sink name: "console", "file" (the log and error
+	// files) or a remote sink, see RemoteSinkNames.
+	SinkMappings map[string]*SinkMapping
+
+	// RetryPolicies replace the retry policy of the remote sinks, by name,
+	// see DefaultRetryPolicy.
+	RetryPolicies map[string]*RetryPolicy
+
+	// RemoteWriters are additional remote sinks, by name, such as custom
+	// writers or compositions like NewMultiRemoteSyncWriter. They are
+	// added after the sinks enabled through the environment, in name
+	// order.
+	RemoteWriters map[string]RemoteSyncWriter
+
+	// ZapOptions are applied to the logger after those of the
+	// configuration, e.g. zap.Hooks, zap.Fields or zap.WrapCore to tee
+	// cores of your own. They apply to the loggers derived from it, and
+	// survive Reload.
+	ZapOptions []zap.Option
+}
+
+// ConfigFromEnv returns the configuration described by the SERVICE_NAME,
+// LOG_LEVEL, LOG_CONSOLE_FORMAT, LOG_FORMAT, LOG_COLOR, LOG_CALLER,
+// LOG_TIME_FORMAT, LOG_TIME_ZONE, LOG_LEVEL_OVERRIDES,
+// LOG_RING_BUFFER_SIZE_MB, LOG_DISABLE_FILE, LOG_FILE_* (the name, mode
+// and rotation of the log files, see rotationFromEnv), LOG_ERROR_FILE_NAME,
+// LOG_DIR_MODE, LOG_SINK_MAPPING_<SINK> and LOG_RETRY_POLICY_<SINK>
+// environment variables, with defaults for unset or invalid values. The
+// file named by LOG_CONFIG_FILE, if any, is loaded first, see
+// LoadConfigFile.
+func ConfigFromEnv() Config {
+	return configFromEnv(Config{})
+}
+
+// configFromEnv is ConfigFromEnv on top of cfg: the variables that are set
+// replace its fields, and the defaults only fill the fields left empty.
+func configFromEnv(cfg Config) Config {
+	if path := os.Getenv("LOG_CONFIG_FILE"); path != "" {
+		if err := LoadConfigFile(path); err != nil && initLog != nil {
+			initLog["configFileMessage"] = err.Error()
+		}
+	}
+
+	if s := os.Getenv("SERVICE_NAME"); s != "" {
+		cfg.ServiceName = s
+	}
+	if cfg.ServiceName == "" {
+		if initLog != nil {
+			initLog["serviceNameMessage"] = "SERVICE_NAME is not set, using sad_service as default"
+		}
+		cfg.ServiceName = "sad_service"
+	}
+
+	if s := os.Getenv("LOG_LEVEL"); s != "" {
+		cfg.Level = s
+		if _, err := ParseLevel(s); err != nil {
+			if initLog != nil {
+				initLog["levelMessage"] = err.Error() + ", using info"
+			}
+			cfg.Level = "info"
+		}
+	}
+	if cfg.Level == "" {
+		cfg.Level = "debug"
+	}
+
+	if s := os.Getenv("LOG_LEVEL_OVERRIDES"); s != "" {
+		overrides, err := ParseLevelOverrides(s)
+		if err != nil && initLog != nil {
+			initLog["levelOverridesMessage"] = err.Error() + ", ignoring the invalid overrides"
+		}
+		cfg.LevelOverrides = overrides
+	}
+
+	switch s := os.Getenv("LOG_CONSOLE_FORMAT"); s {
+	case "":
+		switch format := os.Getenv("LOG_FORMAT"); format {
+		case "":
+			// LOG_COLOR alone selects the full development console.
+			if os.Getenv("LOG_COLOR") == "true" && cfg.ConsoleFormat == "" {
+				cfg.ConsoleFormat = "dev"
+			}
+		case "console":
+			if cfg.ConsoleFormat == "json" {
+				cfg.ConsoleFormat = ""
+			}
+		case "json":
+			cfg.ConsoleFormat = "json"
+		default:
+			if initLog != nil {
+				initLog["formatMessage"] = fmt.Sprintf("invalid LOG_FORMAT %q, expected json or console", format)
+			}
+		}
+	case "dev", "json":
+		cfg.ConsoleFormat = s
+	default:
+		if initLog != nil {
+			initLog["consoleFormatMessage"] = fmt.Sprintf("invalid LOG_CONSOLE_FORMAT %q, expected dev, json or empty", s)
+		}
+	}
+
+	if s := os.Getenv("LOG_CALLER_SKIP"); s != "" {
+		if n, err := strconv.Atoi(s); err == nil && n >= 0 {
+			cfg.CallerSkip = n
+		} else if initLog != nil {
+			initLog["callerSkipMessage"] = fmt.Sprintf("invalid LOG_CALLER_SKIP %q, expected a number of frames", s)
+		}
+	}
+	if s := os.Getenv("LOG_STACKTRACE_LEVEL"); s != "" {
+		if _, _, err := (Config{StacktraceLevel: s}).stacktraceLevel(); err == nil {
+			cfg.StacktraceLevel = s
+		} else if initLog != nil {
+			initLog["stacktraceLevelMessage"] = err.Error() + ", stack traces unchanged"
+		}
+	}
+
+	if s := os.Getenv("LOG_TIME_FORMAT"); s != "" {
+		if _, err := timeEncoder(s, ""); err == nil {
+			cfg.TimeFormat = s
+		} else if initLog != nil {
+			initLog["timeFormatMessage"] = err.Error() + ", time format unchanged"
+		}
+	}
+	if s := os.Getenv("LOG_TIME_ZONE"); s != "" {
+		if _, err := timeEncoder("", s); err == nil {
+			cfg.TimeZone = s
+		} else if initLog != nil {
+			initLog["timeZoneMessage"] = err.Error() + ", time zone unchanged"
+		}
+	}
+
+	if s := os.Getenv("LOG_RING_BUFFER_SIZE_MB"); s != "" {
+		if n, err := strconv.Atoi(s); err == nil && n > 0 {
+			cfg.RingBufferSize = n << 20
+		} else {
+			if initLog != nil {
+				initLog["ringBufferMessage"] = fmt.Sprintf("invalid LOG_RING_BUFFER_SIZE_MB %q, ring buffer disabled", s)
+			}
+			cfg.RingBufferSize = 0
+		}
+	}
+
+	if s := os.Getenv("LOG_FILE_NAME"); s != "" {
+		cfg.FileName = s
+	}
+	if s := os.Getenv("LOG_ERROR_FILE_NAME"); s != "" {
+		cfg.ErrorFileName = s
+	}
+	if os.Getenv("LOG_DISABLE_FILE") == "true" {
+		cfg.DisableFile = true
+	}
+	if s := os.Getenv("LOG_CALLER"); s != "" {
+		cfg.Caller = s == "true"
+	}
+	if s := os.Getenv("LOG_COLOR"); s != "" {
+		cfg.Color = s == "true"
+	}
+
+	for _, mode := range []struct {
+		name, key string
+		to        *os.FileMode
+	}{
+		{"LOG_FILE_MODE", "fileModeMessage", &cfg.FileMode},
+		{"LOG_DIR_MODE", "dirModeMessage", &cfg.DirMode},
+	} {
+		if s := os.Getenv(mode.name); s != "" {
+			if v, err := strconv.ParseUint(s, 8, 32); err == nil && v <= 0777 {
+				*mode.to = os.FileMode(v)
+			} else if initLog != nil {
+				initLog[mode.key] = fmt.Sprintf("invalid %s %q, expected octal permissions such as 0640, using the default", mode.name, s)
+			}
+		}
+	}
+
+	if rotation, err := rotationFromEnv("LOG_FILE", cfg.FileRotation); err != nil {
+		if initLog != nil {
+			initLog["fileRotationMessage"] = err.Error() + ", rotation unchanged"
+		}
+	} else {
+		cfg.FileRotation = rotation
+	}
+
+	// The maps are copied, if needed, so that those of the base are left
+	// alone.
+	var mappings map[string]*SinkMapping
+	for _, sink := range append([]string{"console", "file"}, RemoteSinkNames()...) {
+		m, err := sinkMappingFromEnv(sink)
+		if err != nil {
+			if initLog != nil {
+				initLog[sink+"MappingMessage"] = err.Error()
+			}
+			continue
+		}
+		if m != nil {
+			if mappings == nil {
+				mappings = make(map[string]*SinkMapping, len(cfg.SinkMappings)+1)
+				maps.Copy(mappings, cfg.SinkMappings)
+			}
+			mappings[sink] = m
+		}
+	}
+	if mappings != nil {
+		cfg.SinkMappings = mappings
+	}
+
+	var policies map[string]*RetryPolicy
+	for _, sink := range RemoteSinkNames() {
+		p, err := retryPolicyFromEnv(sink)
+		if err != nil {
+			if initLog != nil {
+				initLog[sink+"RetryPolicyMessage"] = err.Error() + ", using the default retry policy"
+			}
+			continue
+		}
+		if p != nil {
+			if policies == nil {
+				policies = make(map[string]*RetryPolicy, len(cfg.RetryPolicies)+1)
+				maps.Copy(policies, cfg.RetryPolicies)
+			}
+			policies[sink] = p
+		}
+	}
+	if policies != nil {
+		cfg.RetryPolicies = policies
+	}
+
+	return cfg
+}
+
+// builtLogger is a logger constructed by Config.build together with the
+// handles needed to control it at runtime.
+type builtLogger struct {
+	logger *zap.Logger
+
+	// level is the minimum level of the console, file and remote sinks.
+	level zap.AtomicLevel
+
+	// sampling controls the sampling settings of all sinks.
+	sampling *samplingControl
+
+	// attached are the sinks added at runtime, see AttachSink.
+	attached *attachedSinks
+
+	// sinks are the destinations written, replaced by Reload.
+	sinks atomic.Pointer[sinkSet]
+
+	// opts build further loggers sharing the sinks.
+	opts []zap.Option
+}
+
+// sinkSet is the destinations of a builtLogger.
+type sinkSet struct {
+	// newCore tees the sinks, see Config.buildSinks.
+	newCore func(level zapcore.LevelEnabler, tail *tailBuffer, extra []zapcore.Core) zapcore.Core
+
+	// names names the enabled destinations.
+	names []string
+
+	// closers are the files and remote sinks opened for the set, closed
+	// once it is replaced, after the ring, if any, drained.
+	closers []io.Closer
+	ring    *ringBuffer
+}
+
+// Build constructs a logger from the configuration. It writes to stdout,
+// logs.txt and errors.txt in the log directory, see logfile.Dirs, unless
+// DisableFile is set, to the SQLite store when LOG_SQLITE_STORE is set, and
+// to the remote sinks enabled through the environment.
+func (c Config) Build() (*zap.Logger, error) {
+	b, err := c.build()
+	if err != nil {
+		return nil, err
+	}
+	return b.logger, nil
+}
+
+func (c Config) build() (*builtLogger, error) {
+	zapLevel, err := c.level()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := parseLevelOverrides(c.LevelOverrides); err != nil {
+		return nil, err
+	}
+	level := zap.NewAtomicLevelAt(zapLevel)
+	attached := &attachedSinks{}
+	set, err := c.buildSinks(attached)
+	if err != nil {
+		return nil, err
+	}
+
+	sampling := &samplingControl{}
+	sampling.set(c.Sampling)
+
+	opts := []zap.Option{
+		zap.WithCaller(c.callerNeeded()),
+		zap.AddCallerSkip(c.CallerSkip),
+		zap.Fields(
+			zap.String("hostname", hostname),
+			zap.String("serviceName", c.ServiceName),
+		),
+	}
+	if c.Clock != nil {
+		opts = append(opts, zap.WithClock(c.Clock))
+	}
+	if stackLevel, ok, _ := c.stacktraceLevel(); ok {
+		opts = append(opts, zap.AddStacktrace(stackLevel))
+	}
+	opts = append(opts, c.ZapOptions...)
+
+	b := &builtLogger{level: level, sampling: sampling, attached: attached, opts: opts}
+	b.sinks.Store(set)
+	b.logger = b.newLogger(nil)
+	return b, nil
+}
+
+// callerNeeded reports whether an encoder writes the caller, so that it is
+// looked up.
+func (c Config) callerNeeded() bool {
+	if c.Caller {
+		return true
+	}
+	for _, m := range c.SinkMappings {
+		if m != nil && m.CallerKey != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// stacktraceLevel returns the parsed StacktraceLevel, and whether stack
+// traces are attached at all.
+func (c Config) stacktraceLevel() (zapcore.Level, bool, error) {
+	switch strings.ToLower(c.StacktraceLevel) {
+	case "":
+		return zap.ErrorLevel, c.ConsoleFormat == "dev", nil
+	case "none":
+		return zap.ErrorLevel, false, nil
+	}
+	level, err := ParseLevel(c.StacktraceLevel)
+	if err != nil {
+		return level, false, fmt.Errorf("invalid stacktrace level: %v", err)
+	}
+	return level, true, nil
+}
+
+// level returns the parsed Level, debug if it is empty.
+func (c Config) level() (zapcore.Level, error) {
+	if c.Level == "" {
+		return zap.DebugLevel, nil
+	}
+	return ParseLevel(c.Level)
+}
+
+// filePath returns the path of the log file name, or of defaultName if
+// name is empty.
+func (c Config) filePath(name, defaultName string) string {
+	if name == "" {
+		name = defaultName
+	}
+	if filepath.IsAbs(name) {
+		return name
+	}
+	if c.Dir != "" {
+		return filepath.Join(c.Dir, name)
+	}
+	return filepath.Join(resolveLogDir(c.ServiceName), name)
+}
+
+// openLogFile opens the log file name, or defaultName if name is empty,
+// creating its parent directories if needed.
+func (c Config) openLogFile(name, defaultName string) (*rotatingFile, error) {
+	path := c.filePath(name, defaultName)
+	dirMode, fileMode := c.DirMode, c.FileMode
+	if dirMode == 0 {
+		dirMode = defaultDirMode
+	}
+	if fileMode == 0 {
+		fileMode = defaultFileMode
+	}
+	if err := os.MkdirAll(filepath.Dir(path), dirMode); err != nil {
+		return nil, fmt.Errorf("failed to create the directory of %s: %v", path, err)
+	}
+	return openRotatingFile(path, fileMode, c.FileRotation)
+}
+
+// buildSinks opens the files and the remote sinks of the configuration.
+// The cores it returns write to attached as well.
+func (c Config) buildSinks(attached *attachedSinks) (_ *sinkSet, err error) {
+	// ring and closers are the ring buffer, files and remote sinks opened
+	// so far, closed if the build fails.
+	var ring *ringBuffer
+	var closers []io.Closer
+	defer func() {
+		if err != nil {
+			if ring != nil {
+				ring.close()
+			}
+			for _, closer := range closers {
+				closer.Close()
+			}
+		}
+	}()
+
+	// Open or create log files in the logs directory
+	var fileSink, errorFileSink zapcore.WriteSyncer
+	sinks := []string{"console"}
+	if !c.DisableFile {
+		file, err := c.openLogFile(c.FileName, logFileName)
+		if err != nil {
+			return nil, err
+		}
+		closers = append(closers, file)
+		errorLog, err := c.openLogFile(c.ErrorFileName, errorFileName)
+		if err != nil {
+			return nil, err
+		}
+		closers = append(closers, errorLog)
+		fileSink, errorFileSink = file, errorLog
+		sinks = append(sinks, "file")
+	}
+
+	encoderConfig, err := c.encoderConfig()
+	if err != nil {
+		return nil, err
+	}
+	if c.Caller {
+		encoderConfig.CallerKey = "caller"
+	}
+	if _, ok, _ := c.stacktraceLevel(); ok && c.StacktraceLevel != "" {
+		encoderConfig.StacktraceKey = "stacktrace"
+	}
+
+	// Create a custom core that writes to both stdout and file
+	consoleConfig, err := c.SinkMappings["console"].encoderConfig(encoderConfig, c.TimeFormat, c.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid console sink mapping: %v", err)
+	}
+	fileConfig, err := c.SinkMappings["file"].encoderConfig(encoderConfig, c.TimeFormat, c.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid file sink mapping: %v", err)
+	}
+	if c.Color && c.ConsoleFormat != "json" {
+		consoleConfig = colorEncoderConfig(consoleConfig)
+	}
+	var consoleEncoder zapcore.Encoder
+	switch c.ConsoleFormat {
+	case "":
+		consoleEncoder = zapcore.NewConsoleEncoder(consoleConfig)
+	case "dev":
+		consoleEncoder = newDevConsoleEncoder(consoleConfig, c.Color)
+	case "json":
+		consoleEncoder = zapcore.NewJSONEncoder(consoleConfig)
+	default:
+		return nil, fmt.Errorf("invalid console format %q, expected dev, json or empty", c.ConsoleFormat)
+	}
+	fileEncoder := zapcore.NewJSONEncoder(fileConfig)
+	storeEncoder := zapcore.NewJSONEncoder(encoderConfig)
+
+	stdoutSink := zapcore.AddSync(os.Stdout)
+
+	// Feed the local SQLite store, when enabled
+	var storeSink zapcore.WriteSyncer
+	if os.Getenv("LOG_SQLITE_STORE") == "true" && !c.DisableFile {
+		if w := openLocalStore(); w != nil {
+			storeSink = w
+			sinks = append(sinks, "sqlite")
+		}
+	}
+
+	// Put the ring buffer, when enabled, in front of every sink
+	if c.RingBufferSize > 0 {
+		if ring, err = newRingBuffer(c.RingBufferSize); err != nil {
+			return nil, err
+		}
+		stdoutSink = ring.writer(stdoutSink)
+		if fileSink != nil {
+			fileSink, errorFileSink = ring.writer(fileSink), ring.writer(errorFileSink)
+		}
+		if storeSink != nil {
+			storeSink = ring.writer(storeSink)
+		}
+	}
+
+	// Create the remote sinks enabled through the environment
+	type remoteSink struct {
+		sink    zapcore.WriteSyncer
+		encoder zapcore.Encoder
+		mapping *SinkMapping
+		shed    *shedState
+	}
+	remoteSinks := []remoteSink{}
+	shedMarks, shedding := shedWatermarksFromEnv()
+	addRemoteSink := func(name string, w RemoteSyncWriter) error {
+		mapping := c.SinkMappings[name]
+		cfg, err := mapping.encoderConfig(encoderConfig, c.TimeFormat, c.TimeZone)
+		if err != nil {
+			return fmt.Errorf("invalid %s sink mapping: %v", name, err)
+		}
+		if limiter, spillPath, err := rateLimitFromEnv(name); err != nil {
+			fmt.Printf("%v. %s rate limit disabled.\n", err, name)
+		} else if limiter != nil || spillPath != "" {
+			rw, ok := w.(rateLimitedWriter)
+			if !ok {
+				fmt.Printf("The %s sink does not support rate limits. Rate limit disabled.\n", name)
+			} else if err := rw.setRateLimit(limiter, spillPath); err != nil {
+				fmt.Printf("%v. Rate limit disabled.\n", err)
+			}
+		}
+		if n, err := batchSizeFromEnv(name); err != nil {
+			fmt.Printf("%v. Using the default %s batch size.\n", err, name)
+		} else if n > 0 {
+			if bw, ok := w.(batchedWriter); ok {
+				bw.setBatchSize(n)
+			} else {
+				fmt.Printf("The %s sink does not send batches. Batch size ignored.\n", name)
+			}
+		}
+		if p := c.RetryPolicies[name]; p != nil {
+			if rw, ok := w.(retryingWriter); ok {
+				rw.setRetryPolicy(*p)
+			} else {
+				fmt.Printf("The %s sink does not support retry policies. Using its own retries.\n", name)
+			}
+		}
+		var shed *shedState
+		if shedding {
+			shed = newShedState(w, shedMarks)
+		}
+		sink := zapcore.AddSync(w)
+		if ring != nil {
+			sink = ring.writer(sink)
+		}
+		remoteSinks = append(remoteSinks, remoteSink{sink: sink, encoder: zapcore.NewJSONEncoder(cfg), mapping: mapping, shed: shed})
+		sinks = append(sinks, name)
+		return nil
+	}
+
+	// Check which remote sinks are enabled
+	for _, t := range remoteSinkTypes {
+		if !t.enabled() {
+			continue
+		}
+		if w := t.open(); w != nil {
+			if closer, ok := w.(io.Closer); ok {
+				closers = append(closers, closer)
+			}
+			if err := addRemoteSink(t.name, w); err != nil {
+				return nil, err
+			}
+		}
+	}
+	names := make([]string, 0, len(c.RemoteWriters))
+	for name, w := range c.RemoteWriters {
+		if w != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := addRemoteSink(name, c.RemoteWriters[name]); err != nil {
+			return nil, err
+		}
+	}
+
+	// newCore tees every sink through the registered hooks, the console,
+	// file and remote ones writing the entries enabled by level. Entries for
+	// the remote sinks are held in tail, when set, see
+	// TailRetentionMiddleware, and shed while their queue fills up.
+	newCore := func(level zapcore.LevelEnabler, tail *tailBuffer, extra []zapcore.Core) zapcore.Core {
+		consoleMapping, fileMapping := c.SinkMappings["console"], c.SinkMappings["file"]
+		cores := []zapcore.Core{consoleMapping.wrap(zapcore.NewCore(consoleEncoder, stdoutSink, level))}
+		if fileSink != nil {
+			cores = append(cores,
+				fileMapping.wrap(zapcore.NewCore(fileEncoder, fileSink, level)),
+				fileMapping.wrap(zapcore.NewCore(fileEncoder, errorFileSink, zap.ErrorLevel)),
+			)
+		}
+		cores = append(cores,
+			&streamCore{hub: liveStream},
+			&statsCore{level: level},
+			&flightCore{recorder: flight, level: level},
+		)
+		if storeSink != nil {
+			cores = append(cores, zapcore.NewCore(storeEncoder, storeSink, level))
+		}
+		for _, remote := range remoteSinks {
+			if tail != nil {
+				for _, core := range tail.cores(remote.encoder, remote.sink, level) {
+					cores = append(cores, remote.shed.wrap(remote.mapping.wrap(core)))
+				}
+			} else {
+				cores = append(cores, remote.shed.wrap(remote.mapping.wrap(zapcore.NewCore(remote.encoder, remote.sink, level))))
+			}
+		}
+		cores = append(cores, extra...)
+		return &hookCore{cores: cores, attached: attached, level: level}
+	}
+
+	return &sinkSet{newCore: newCore, names: sinks, closers: closers, ring: ring}, nil
+}
+
+// close flushes and closes the files and remote sinks of the set.
+func (s *sinkSet) close() {
+	if s.ring != nil {
+		s.ring.close()
+	}
+	for _, c := range s.closers {
+		if syncer, ok := c.(interface{ Sync() error }); ok {
+			syncer.Sync()
+		}
+		if err := c.Close(); err != nil {
+			fmt.Printf("Failed to close a replaced log sink: %v\n", err)
+		}
+	}
+}
+
+// newLogger returns a logger writing to the sinks of b, holding the entries
+// for the remote sinks in tail when it is not nil.
+func (b *builtLogger) newLogger(tail *tailBuffer) *zap.Logger {
+	return zap.New(b.newCore(b.level, tail, nil), b.opts...)
+}
+
+// newCore returns the core of the loggers writing to the sinks of b and to
+// extra at level.
+func (b *builtLogger) newCore(level zapcore.LevelEnabler, tail *tailBuffer, extra []zapcore.Core) *debugOverrideCore {
+	return &debugOverrideCore{
+		Core:  &samplingCore{Core: &reloadCore{logger: b, level: level, tail: tail, extra: extra}, control: b.sampling},
+		debug: &reloadCore{logger: b, level: zap.DebugLevel, tail: tail, extra: extra},
+	}
+}
@@ -0,0 +1,186 @@
+// sad-go-logger/logger/config_file.go
+
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// fileSettings are the variables set by LoadConfigFile, with their value,
+// which a later load, typically on Reload, may change or unset unless they
+// were changed since.
+var (
+	fileSettingsMu sync.Mutex
+	fileSettings   = map[string]string{}
+)
+
+// LoadConfigFile reads the settings of a YAML, JSON or TOML file, by its
+// extension, into the environment, where ConfigFromEnv and the sinks read
+// them. Variables already set in the environment take precedence over the
+// file.
+//
+// The keys of nested tables are joined with underscores and upper-cased
+// into the variable names, so that
+//
+//	log:
+//	  level: info
+//	logstash:
+//	  use_tls: true
+//
+// sets LOG_LEVEL and LOGSTASH_USE_TLS. Lists of scalars are joined with
+// commas, as LOG_BAGGAGE_FIELDS expects. The variables holding JSON, the
+// LOG_SINK_MAPPING_<SINK> and LOG_RETRY_POLICY_<SINK> tables and lists of
+// tables such as LOG_DROP_FILTERS, are encoded as JSON.
+//
+// Loading a file again updates the variables it set, and unsets those
+// removed from it. ConfigFromEnv loads the file named by LOG_CONFIG_FILE,
+// if any.
+func LoadConfigFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read the config file: %v", err)
+	}
+
+	var settings map[string]interface{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &settings)
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		err = dec.Decode(&settings)
+	case ".toml":
+		err = toml.Unmarshal(data, &settings)
+	default:
+		return fmt.Errorf("unsupported config file format %q, expected .yaml, .yml, .json or .toml", ext)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid config file %s: %v", path, err)
+	}
+
+	vars := map[string]string{}
+	if err := flattenSettings("", settings, vars); err != nil {
+		return fmt.Errorf("invalid config file %s: %v", path, err)
+	}
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fileSettingsMu.Lock()
+	defer fileSettingsMu.Unlock()
+	owned := func(name string) bool {
+		current, set := os.LookupEnv(name)
+		previous, ok := fileSettings[name]
+		return !set || ok && previous == current
+	}
+	for name := range fileSettings {
+		if _, ok := vars[name]; !ok && owned(name) {
+			os.Unsetenv(name)
+			delete(fileSettings, name)
+		}
+	}
+	for _, name := range names {
+		if owned(name) {
+			os.Setenv(name, vars[name])
+			fileSettings[name] = vars[name]
+		}
+	}
+	return nil
+}
+
+// flattenSettings adds the variables of the table settings, their names
+// prefixed by prefix, to vars.
+func flattenSettings(prefix string, settings map[string]interface{}, vars map[string]string) error {
+	for key, value := range settings {
+		name := strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(key))
+		if prefix != "" {
+			name = prefix + "_" + name
+		}
+		if table, ok := value.(map[string]interface{}); ok && !jsonSetting(name) {
+			if err := flattenSettings(name, table, vars); err != nil {
+				return err
+			}
+			continue
+		}
+		s, err := settingValue(name, value)
+		if err != nil {
+			return err
+		}
+		vars[name] = s
+	}
+	return nil
+}
+
+// jsonSetting reports whether the variable name holds a JSON table.
+func jsonSetting(name string) bool {
+	for _, prefix := range []string{"LOG_SINK_MAPPING_", "LOG_RETRY_POLICY_"} {
+		if strings.HasPrefix(name, prefix) && len(name) > len(prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// settingValue returns value as the content of the variable name.
+func settingValue(name string, value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case json.Number:
+		return v.String(), nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			switch item.(type) {
+			case map[string]interface{}, []interface{}:
+				return jsonSettingValue(name, v)
+			}
+			s, err := settingValue(name, item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, s)
+		}
+		return strings.Join(items, ","), nil
+	case map[string]interface{}, []map[string]interface{}:
+		return jsonSettingValue(name, v)
+	default:
+		return "", fmt.Errorf("unsupported value of %s: %T", name, value)
+	}
+}
+
+func jsonSettingValue(name string, value interface{}) (string, error) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid value of %s: %v", name, err)
+	}
+	return string(b), nil
+}
@@ -0,0 +1,92 @@
+// sad-go-logger/logger/console_color.go
+
+package logger
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// ANSI sequences of the colored console, the palette of sadlog.
+const (
+	colorReset = "\x1b[0m"
+	colorDim   = "\x1b[2m"
+	colorCyan  = "\x1b[36m"
+)
+
+// colorCallerWidth is the width the caller column is padded to, so that the
+// messages line up.
+const colorCallerWidth = 24
+
+// colorLevel returns the ANSI sequence of level.
+func colorLevel(level zapcore.Level) string {
+	switch {
+	case level <= zapcore.DebugLevel:
+		return "\x1b[35m" // magenta
+	case level == zapcore.InfoLevel:
+		return "\x1b[34m" // blue
+	case level == zapcore.WarnLevel:
+		return "\x1b[33m" // yellow
+	case level == zapcore.ErrorLevel:
+		return "\x1b[31m" // red
+	default:
+		return "\x1b[1;31m" // bold red
+	}
+}
+
+// colorEncoderConfig returns cfg rendering the console columns in color,
+// enabled by LOG_COLOR: a dim timestamp, the level in the color of its
+// severity and the caller padded to fixed widths, so that the messages line
+// up, separated by spaces.
+func colorEncoderConfig(cfg zapcore.EncoderConfig) zapcore.EncoderConfig {
+	cfg.ConsoleSeparator = " "
+	encodeTime := cfg.EncodeTime
+	cfg.EncodeTime = func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+		encodeTime(t, dimEncoder{enc})
+	}
+	cfg.EncodeLevel = func(level zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+		enc.AppendString(colorLevel(level) + fmt.Sprintf("%-5s", level.CapitalString()) + colorReset)
+	}
+	cfg.EncodeCaller = func(caller zapcore.EntryCaller, enc zapcore.PrimitiveArrayEncoder) {
+		enc.AppendString(colorDim + fmt.Sprintf("%-*s", colorCallerWidth, caller.TrimmedPath()) + colorReset)
+	}
+	return cfg
+}
+
+// dimEncoder renders the timestamps appended to its encoder dimmed.
+type dimEncoder struct {
+	zapcore.PrimitiveArrayEncoder
+}
+
+func (e dimEncoder) AppendString(s string) {
+	e.PrimitiveArrayEncoder.AppendString(colorDim + s + colorReset)
+}
+
+func (e dimEncoder) AppendInt64(v int64) {
+	e.AppendString(strconv.FormatInt(v, 10))
+}
+
+func (e dimEncoder) AppendFloat64(v float64) {
+	e.AppendString(strconv.FormatFloat(v, 'f', -1, 64))
+}
+
+// colorKey returns the key of a field for the colored console.
+func colorKey(key string) string {
+	return colorCyan + key + colorReset
+}
+
+// isTerminal reports whether f is a terminal, where colors are rendered.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// colorSet reports whether the environment enables or disables colors.
+func colorSet() bool {
+	return strings.TrimSpace(os.Getenv("LOG_COLOR")) != ""
+}
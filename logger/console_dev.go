@@ -0,0 +1,107 @@
+// sad-go-logger/logger/console_dev.go
+
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// devBlockIndent prefixes the lines of the blocks rendered under an entry
+// by the dev console encoder.
+const devBlockIndent = "    "
+
+// devInlineMax is the length beyond which a string field is rendered as a
+// block rather than inline.
+const devInlineMax = 120
+
+// devConsoleEncoder renders entries like the console encoder, but moves
+// stack traces, nested objects and arrays, and long or multi-line strings
+// into indented blocks under the entry line, for reading during
+// development. Fields added with Logger.With stay inline.
+type devConsoleEncoder struct {
+	zapcore.Encoder
+
+	// color renders the keys of the blocks in color, see LOG_COLOR.
+	color bool
+}
+
+// newDevConsoleEncoder returns the encoder selected by
+// LOG_CONSOLE_FORMAT=dev.
+func newDevConsoleEncoder(cfg zapcore.EncoderConfig, color bool) zapcore.Encoder {
+	cfg.StacktraceKey = ""
+	return &devConsoleEncoder{Encoder: zapcore.NewConsoleEncoder(cfg), color: color}
+}
+
+func (e *devConsoleEncoder) Clone() zapcore.Encoder {
+	return &devConsoleEncoder{Encoder: e.Encoder.Clone(), color: e.color}
+}
+
+func (e *devConsoleEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	var inline, blocks []zapcore.Field
+	for _, f := range fields {
+		if devBlockField(f) {
+			blocks = append(blocks, f)
+		} else {
+			inline = append(inline, f)
+		}
+	}
+
+	line, err := e.Encoder.EncodeEntry(ent, inline)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range blocks {
+		line.AppendString(devBlockIndent + e.key(f.Key) + ":\n")
+		devAppendBlock(line, devBlockValue(f))
+	}
+	if ent.Stack != "" {
+		line.AppendString(devBlockIndent + e.key("stacktrace") + ":\n")
+		devAppendBlock(line, ent.Stack)
+	}
+	return line, nil
+}
+
+// key returns the key of a block.
+func (e *devConsoleEncoder) key(key string) string {
+	if e.color {
+		return colorKey(key)
+	}
+	return key
+}
+
+// devBlockField reports whether f is rendered as a block.
+func devBlockField(f zapcore.Field) bool {
+	switch f.Type {
+	case zapcore.ObjectMarshalerType, zapcore.ArrayMarshalerType, zapcore.ReflectType:
+		return true
+	case zapcore.StringType:
+		return len(f.String) > devInlineMax || strings.Contains(f.String, "\n")
+	}
+	return false
+}
+
+// devBlockValue renders the value of f, nested values as indented JSON.
+func devBlockValue(f zapcore.Field) string {
+	if f.Type == zapcore.StringType {
+		return f.String
+	}
+	enc := zapcore.NewMapObjectEncoder()
+	f.AddTo(enc)
+	b, err := json.MarshalIndent(enc.Fields[f.Key], "", "  ")
+	if err != nil {
+		return fmt.Sprintf("%v", enc.Fields[f.Key])
+	}
+	return string(b)
+}
+
+// devAppendBlock appends the lines of s, indented twice.
+func devAppendBlock(line *buffer.Buffer, s string) {
+	for _, l := range strings.Split(strings.TrimRight(s, "\n"), "\n") {
+		line.AppendString(devBlockIndent + devBlockIndent + l + "\n")
+	}
+}
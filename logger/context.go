@@ -0,0 +1,164 @@
+// sad-go-logger/logger/context.go
+
+package logger
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+type contextKey struct{}
+
+// loggerScope is the logger carried by a context. Append replaces it with
+// one holding more fields, for every holder of the context.
+type loggerScope struct {
+	mu     sync.RWMutex
+	logger *zap.Logger
+
+	// span, set by newScope, is the span whose trace fields logger
+	// carries, see spanKey, empty for none. traced is logger with the
+	// trace fields of tracedSpan, the last other span FromContext was
+	// called with.
+	span       string
+	tracedSpan string
+	traced     *zap.Logger
+}
+
+// NewContext returns a copy of ctx carrying l, to be retrieved with
+// FromContext further down the call chain. Fields added with Append are
+// seen by every holder of the returned context and of the contexts derived
+// from it.
+//
+// If ctx carries a span, l is expected to carry its trace fields, as the
+// loggers of FromContext do. FromContext adds the fields of the spans
+// started later, in the contexts derived from the returned one.
+func NewContext(ctx context.Context, l *zap.Logger) context.Context {
+	return newScope(ctx, l, spanKey(TraceFields(ctx)))
+}
+
+// newScope returns a copy of ctx carrying l, which carries the trace fields
+// of span.
+func newScope(ctx context.Context, l *zap.Logger, span string) context.Context {
+	return context.WithValue(ctx, contextKey{}, &loggerScope{logger: l, span: span})
+}
+
+// scopeLogger returns the logger carried by ctx or, if there is none, the
+// global Log with the baggage fields of ctx, without the trace fields that
+// FromContext adds, and the span whose fields it carries nonetheless, as
+// given to NewContext. The middlewares derive the request loggers from it.
+func scopeLogger(ctx context.Context) (*zap.Logger, string) {
+	if s, ok := ctx.Value(contextKey{}).(*loggerScope); ok {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		return s.logger, s.span
+	}
+	if fields := BaggageFields(ctx); len(fields) > 0 {
+		return Log.With(fields...), ""
+	}
+	return Log, ""
+}
+
+// ToContext returns a copy of ctx carrying the logger of FromContext(ctx)
+// with fields added, such as the user, request or tenant id, so that every
+// entry logged through FromContext with the returned context or the
+// contexts derived from it, in any goroutine, carries them:
+//
+//	ctx = logger.ToContext(ctx, zap.String("tenant", tenant))
+//	go process(ctx) // logger.FromContext(ctx) logs the tenant
+//
+// Unlike Append, the logger of ctx itself is left alone.
+func ToContext(ctx context.Context, fields ...zap.Field) context.Context {
+	l, span := scopeLogger(ctx)
+	return newScope(ctx, l.With(fields...), span)
+}
+
+// FromContext returns the logger carried by ctx or, if there is none, the
+// global Log with the baggage fields of ctx, see SetBaggageFields. Either
+// way, the logger carries the trace ids of the span of ctx, see
+// TraceFields, including a span started after the logger was put in a
+// parent context, whose ids replace those of the span of the parent.
+func FromContext(ctx context.Context) *zap.Logger {
+	fields := TraceFields(ctx)
+	s, ok := ctx.Value(contextKey{}).(*loggerScope)
+	if !ok {
+		fields = append(fields, BaggageFields(ctx)...)
+		if len(fields) > 0 {
+			return Log.With(fields...)
+		}
+		return Log
+	}
+	return s.tracedLogger(spanKey(fields), fields)
+}
+
+// tracedLogger returns the logger of s with fields, the trace fields of
+// span, built once per span.
+func (s *loggerScope) tracedLogger(span string, fields []zap.Field) *zap.Logger {
+	s.mu.RLock()
+	l, traced, tracedSpan := s.logger, s.traced, s.tracedSpan
+	s.mu.RUnlock()
+	if span == s.span {
+		return l
+	}
+	if traced != nil && span == tracedSpan {
+		return traced
+	}
+
+	traced = l.With(fields...)
+	s.mu.Lock()
+	if s.logger == l {
+		s.traced, s.tracedSpan = traced, span
+	}
+	s.mu.Unlock()
+	return traced
+}
+
+// spanKey identifies the span of fields, returned by TraceFields.
+func spanKey(fields []zap.Field) string {
+	var b strings.Builder
+	for _, f := range fields {
+		b.WriteString(f.String)
+		b.WriteByte('-')
+	}
+	return b.String()
+}
+
+// Append adds fields to the logger carried by ctx, so that every entry
+// subsequently logged through FromContext(ctx) carries them, e.g. once a
+// handler has authenticated the user:
+//
+//	logger.Append(ctx, zap.String("user_id", id))
+//
+// Loggers retrieved before the call keep their fields. Append does nothing
+// if ctx carries no logger; see NewContext and ScopeMiddleware.
+func Append(ctx context.Context, fields ...zap.Field) {
+	if s, ok := ctx.Value(contextKey{}).(*loggerScope); ok && len(fields) > 0 {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.logger = s.logger.With(fields...)
+		s.traced = nil
+	}
+}
+
+// ScopeMiddleware gives every request its own logger in the request
+// context, derived from the logger already there or the global Log, so
+// that handlers can accumulate request fields with Append. A request
+// without a span in its context is correlated with the trace of its W3C
+// traceparent header, if any, see ContextWithTraceparent.
+func ScopeMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(ScopeContext(requestContext(r))))
+	})
+}
+
+// ScopeContext returns a copy of ctx carrying its own logger, derived from
+// the logger of ctx or the global Log, that Append extends, as
+// ScopeMiddleware does for every request. Framework middlewares and
+// message consumers call it once per request or message.
+func ScopeContext(ctx context.Context) context.Context {
+	l, span := scopeLogger(ctx)
+	return newScope(ctx, l, span)
+}
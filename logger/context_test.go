@@ -0,0 +1,90 @@
+// sad-go-logger/logger/context_test.go
+
+package logger
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// withSpan returns ctx carrying the span spanID of the trace traceID.
+func withSpan(t *testing.T, ctx context.Context, traceID, spanID string) context.Context {
+	t.Helper()
+	tid, err := trace.TraceIDFromHex(traceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sid, err := trace.SpanIDFromHex(spanID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: tid, SpanID: sid, TraceFlags: trace.FlagsSampled})
+	return trace.ContextWithSpanContext(ctx, sc)
+}
+
+const (
+	testTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testSpanID  = "00f067aa0ba902b7"
+	testChildID = "b7ad6b7169203331"
+)
+
+func TestFromContextReplacesTheSpanOfTheScope(t *testing.T) {
+	remote := &recordingWriter{}
+	useRemoteTestLogger(t, remote)
+
+	handler := ScopeMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Append(r.Context(), zap.String("user_id", "u1"))
+		FromContext(r.Context()).Info("request")
+		FromContext(withSpan(t, r.Context(), testTraceID, testChildID)).Info("child")
+	}))
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(TraceparentHeader, "00-"+testTraceID+"-"+testSpanID+"-01")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	lines := strings.Split(strings.TrimSpace(remote.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d entries, want 2: %q", len(lines), remote.String())
+	}
+	for i, span := range []string{testSpanID, testChildID} {
+		if !strings.Contains(lines[i], `"span_id":"`+span+`"`) || strings.Count(lines[i], `"span_id"`) != 1 {
+			t.Errorf("entry %d = %s, want the span_id %s alone", i, lines[i], span)
+		}
+		if !strings.Contains(lines[i], `"trace_id":"`+testTraceID+`"`) || !strings.Contains(lines[i], `"user_id":"u1"`) {
+			t.Errorf("entry %d = %s, want the trace_id and the fields of the scope", i, lines[i])
+		}
+	}
+}
+
+func TestFromContextAddsTheSpanMissingFromTheScope(t *testing.T) {
+	remote := &recordingWriter{}
+	useRemoteTestLogger(t, remote)
+
+	ctx := ToContext(context.Background(), zap.String("request_id", "r1"))
+	FromContext(ctx).Info("before")
+	spanCtx := withSpan(t, ctx, testTraceID, testChildID)
+	FromContext(spanCtx).Warn("in span")
+	Append(ctx, zap.String("user_id", "u1"))
+	FromContext(spanCtx).Warn("after append")
+
+	lines := strings.Split(strings.TrimSpace(remote.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d entries, want 3: %q", len(lines), remote.String())
+	}
+	if strings.Contains(lines[0], `"trace_id"`) {
+		t.Errorf("entry 0 = %s, want no trace fields outside the span", lines[0])
+	}
+	for _, line := range lines[1:] {
+		if !strings.Contains(line, `"span_id":"`+testChildID+`"`) || !strings.Contains(line, `"request_id":"r1"`) {
+			t.Errorf("entry = %s, want the span and the request_id", line)
+		}
+	}
+	if !strings.Contains(lines[2], `"user_id":"u1"`) {
+		t.Errorf("entry 2 = %s, want the field appended to the scope", lines[2])
+	}
+}
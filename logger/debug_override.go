@@ -0,0 +1,102 @@
+// sad-go-logger/logger/debug_override.go
+
+package logger
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// DebugHeader is the request header checked by DebugOverrideMiddleware.
+const DebugHeader = "X-Debug-Log"
+
+// debugOverrideCore carries, next to the regular core, a copy of the sinks
+// that writes every level, so that a single logger can be switched to debug
+// without raising the global level. Both cores receive the same fields.
+type debugOverrideCore struct {
+	zapcore.Core
+
+	// debug writes every level, unsampled.
+	debug zapcore.Core
+
+	// forced selects the debug core.
+	forced bool
+
+	// fields are the fields added with With, kept so that the logger can
+	// be moved to other cores, see builtLogger.withTail.
+	fields []zapcore.Field
+}
+
+func (c *debugOverrideCore) active() zapcore.Core {
+	if c.forced {
+		return c.debug
+	}
+	return c.Core
+}
+
+func (c *debugOverrideCore) Enabled(level zapcore.Level) bool {
+	return c.active().Enabled(level)
+}
+
+func (c *debugOverrideCore) With(fields []zapcore.Field) zapcore.Core {
+	return &debugOverrideCore{
+		Core:   c.Core.With(fields),
+		debug:  c.debug.With(fields),
+		forced: c.forced,
+		fields: append(c.fields[:len(c.fields):len(c.fields)], fields...),
+	}
+}
+
+func (c *debugOverrideCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return c.active().Check(ent, ce)
+}
+
+func (c *debugOverrideCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.active().Write(ent, fields)
+}
+
+func (c *debugOverrideCore) Sync() error {
+	return c.active().Sync()
+}
+
+// ForceDebug returns a copy of l, built by Config.Build, that writes debug
+// entries to every sink regardless of the configured level, keeping the
+// fields of l. Other loggers, such as the ones from NewTest, are returned
+// unchanged.
+func ForceDebug(l *zap.Logger) *zap.Logger {
+	return l.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		if c, ok := core.(*debugOverrideCore); ok {
+			return &debugOverrideCore{Core: c.Core, debug: c.debug, forced: true, fields: c.fields}
+		}
+		return core
+	}))
+}
+
+// DebugOverrideMiddleware elevates the logger of selected requests to debug,
+// so that a single user's reproduction is captured in full without raising
+// the global verbosity. A request is selected when its X-Debug-Log header
+// equals token, or when flag, if not nil, returns true, e.g. after a feature
+// flag lookup. An empty token disables the header check.
+//
+// The elevated logger, tagged with debugOverride=true, is stored in the
+// request context; handlers retrieve it with FromContext.
+func DebugOverrideMiddleware(token string, flag func(*http.Request) bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get(DebugHeader)
+			selected := token != "" && subtle.ConstantTimeCompare([]byte(header), []byte(token)) == 1
+			if !selected && flag != nil {
+				selected = flag(r)
+			}
+			if selected {
+				ctx := requestContext(r)
+				l, span := scopeLogger(ctx)
+				r = r.WithContext(newScope(ctx, ForceDebug(l).With(zap.Bool("debugOverride", true)), span))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
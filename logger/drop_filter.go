@@ -0,0 +1,197 @@
+// sad-go-logger/logger/drop_filter.go
+
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// DropFilter drops or downgrades the entries matching all of its
+// conditions before they reach any sink, e.g. to silence the "context
+// canceled" warnings of a dependency:
+//
+//	{"message": "context canceled", "logger": "grpc", "action": "downgrade", "to": "debug"}
+type DropFilter struct {
+	// Message is a regular expression matched against the message.
+	Message string `json:"message,omitempty"`
+
+	// Logger matches entries of the named logger (see zap.Logger.Named)
+	// and of its children: "grpc" matches "grpc" and "grpc.client".
+	Logger string `json:"logger,omitempty"`
+
+	// Fields must all be passed with the entry with the given string
+	// values. Fields added with Logger.With are not matched.
+	Fields map[string]string `json:"fields,omitempty"`
+
+	// Action is "drop" (the default) or "downgrade".
+	Action string `json:"action,omitempty"`
+
+	// To is the level of downgraded entries (default: "debug"). Entries
+	// already at or below it are left unchanged.
+	To string `json:"to,omitempty"`
+}
+
+// dropFilter is a compiled DropFilter.
+type dropFilter struct {
+	DropFilter
+	message *regexp.Regexp
+	drop    bool
+	to      zapcore.Level
+	matched atomic.Int64
+}
+
+var (
+	dropFilters        atomic.Pointer[[]*dropFilter]
+	dropFilterHookOnce sync.Once
+)
+
+// SetDropFilters replaces the drop filters applied by every logger built
+// with Config.Build or NewTest. The first matching filter applies. Filters can also be
+// set by Init with LOG_DROP_FILTERS, a JSON array of filters, and managed at
+// runtime through DropFilterHandler.
+func SetDropFilters(filters []DropFilter) error {
+	compiled := make([]*dropFilter, 0, len(filters))
+	for i, f := range filters {
+		c := &dropFilter{DropFilter: f, to: zapcore.DebugLevel}
+		if f.Message != "" {
+			re, err := regexp.Compile(f.Message)
+			if err != nil {
+				return fmt.Errorf("drop filter %d: invalid message pattern: %v", i, err)
+			}
+			c.message = re
+		}
+		switch f.Action {
+		case "", "drop":
+			c.drop = true
+		case "downgrade":
+		default:
+			return fmt.Errorf("drop filter %d: unknown action %q, expected drop or downgrade", i, f.Action)
+		}
+		if f.To != "" {
+			var err error
+			if c.to, err = ParseLevel(f.To); err != nil {
+				return fmt.Errorf("drop filter %d: invalid level %q", i, f.To)
+			}
+		}
+		compiled = append(compiled, c)
+	}
+
+	dropFilterHookOnce.Do(func() {
+		RegisterHook(PreWrite, applyDropFilters)
+	})
+	dropFilters.Store(&compiled)
+	return nil
+}
+
+// DropFilterStatus is a drop filter and the number of entries it matched.
+type DropFilterStatus struct {
+	DropFilter
+	Matched int64 `json:"matched"`
+}
+
+// DropFilters returns the current drop filters.
+func DropFilters() []DropFilterStatus {
+	filters := dropFilters.Load()
+	if filters == nil {
+		return []DropFilterStatus{}
+	}
+	status := make([]DropFilterStatus, len(*filters))
+	for i, f := range *filters {
+		status[i] = DropFilterStatus{DropFilter: f.DropFilter, Matched: f.matched.Load()}
+	}
+	return status
+}
+
+// errDropped vetoes the entries dropped by a filter.
+var errDropped = errors.New("dropped by filter")
+
+// applyDropFilters is the PreWrite hook applying the drop filters.
+func applyDropFilters(e *Entry) error {
+	filters := dropFilters.Load()
+	if filters == nil {
+		return nil
+	}
+	for _, f := range *filters {
+		if !f.match(e) {
+			continue
+		}
+		f.matched.Add(1)
+		if f.drop {
+			return errDropped
+		}
+		if e.Level > f.to {
+			e.Level = f.to
+		}
+		return nil
+	}
+	return nil
+}
+
+func (f *dropFilter) match(e *Entry) bool {
+	if f.message != nil && !f.message.MatchString(e.Message) {
+		return false
+	}
+	if f.Logger != "" && e.LoggerName != f.Logger && !strings.HasPrefix(e.LoggerName, f.Logger+".") {
+		return false
+	}
+	if len(f.Fields) > 0 {
+		enc := zapcore.NewMapObjectEncoder()
+		for _, field := range e.Fields {
+			if _, ok := f.Fields[field.Key]; ok {
+				field.AddTo(enc)
+			}
+		}
+		for key, want := range f.Fields {
+			got, ok := enc.Fields[key]
+			if !ok || fmt.Sprint(got) != want {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// dropFiltersFromEnv applies the filters of LOG_DROP_FILTERS, if set.
+func dropFiltersFromEnv(value string) error {
+	var filters []DropFilter
+	if err := json.Unmarshal([]byte(value), &filters); err != nil {
+		return fmt.Errorf("invalid LOG_DROP_FILTERS: %v", err)
+	}
+	return SetDropFilters(filters)
+}
+
+// DropFilterHandler serves the drop filters as JSON: GET lists them with
+// their match counts, PUT replaces them with the JSON array in the body.
+// The handler does no authentication of its own.
+func DropFilterHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var filters []DropFilter
+			if err := json.NewDecoder(r.Body).Decode(&filters); err != nil {
+				http.Error(w, fmt.Sprintf("invalid filters: %v", err), http.StatusBadRequest)
+				return
+			}
+			if err := SetDropFilters(filters); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(DropFilters())
+	})
+}
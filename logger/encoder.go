@@ -0,0 +1,64 @@
+// sad-go-logger/logger/encoder.go
+
+package logger
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// EncoderConstructor builds an encoder from the package's encoder config.
+type EncoderConstructor func(zapcore.EncoderConfig) zapcore.Encoder
+
+var (
+	encodersMu sync.RWMutex
+	encoders   = map[string]EncoderConstructor{
+		"console": zapcore.NewConsoleEncoder,
+		"ecs":     newConvertEncoder(logfileConverter("ecs")),
+		"gelf":    newConvertEncoder(writeGELF),
+		"json":    zapcore.NewJSONEncoder,
+		"logfmt":  newConvertEncoder(logfileConverter("logfmt")),
+	}
+)
+
+// RegisterEncoder makes an encoder format available under name, so it can
+// be selected by sinks and is covered by the golden-file harness. The
+// built-in formats are "console", "ecs", "gelf", "json" and "logfmt".
+func RegisterEncoder(name string, constructor EncoderConstructor) error {
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+
+	if _, ok := encoders[name]; ok {
+		return fmt.Errorf("encoder %q is already registered", name)
+	}
+	encoders[name] = constructor
+	return nil
+}
+
+// EncoderNames returns the names of all registered encoder formats, sorted.
+func EncoderNames() []string {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+
+	names := make([]string, 0, len(encoders))
+	for name := range encoders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// newEncoder returns the encoder registered under name, built from cfg.
+func newEncoder(name string, cfg zapcore.EncoderConfig) (zapcore.Encoder, error) {
+	encodersMu.RLock()
+	constructor, ok := encoders[name]
+	encodersMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown encoder %q", name)
+	}
+	return constructor(cfg), nil
+}
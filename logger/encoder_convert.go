@@ -0,0 +1,139 @@
+// sad-go-logger/logger/encoder_convert.go
+
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/sadco-io/sad-go-logger/logger/logfile"
+)
+
+// gelfVersion is the GELF version stamped on GELF output.
+const gelfVersion = "1.1"
+
+var convertPool = buffer.NewPool()
+
+// convertEncoder renders entries in a format of their own by encoding them
+// to JSON with the logger's keys, then converting the decoded record, as
+// sadlog convert does for stored logs. The keys and time format of the
+// config are not used: the format defines its own.
+type convertEncoder struct {
+	// Encoder is the JSON encoder holding the fields added with With.
+	zapcore.Encoder
+	convert func(logfile.Record, io.Writer) error
+}
+
+// newConvertEncoder returns the constructor of the encoder writing the
+// records converted by convert.
+func newConvertEncoder(convert func(logfile.Record, io.Writer) error) EncoderConstructor {
+	return func(cfg zapcore.EncoderConfig) zapcore.Encoder {
+		cfg.MessageKey = logfile.MessageKey
+		cfg.LevelKey = logfile.LevelKey
+		cfg.TimeKey = logfile.TimeKey
+		cfg.EncodeLevel = zapcore.LowercaseLevelEncoder
+		cfg.EncodeTime = zapcore.RFC3339NanoTimeEncoder
+		return &convertEncoder{Encoder: zapcore.NewJSONEncoder(cfg), convert: convert}
+	}
+}
+
+// logfileConverter returns a convert function writing format with the
+// converter of the logfile package.
+func logfileConverter(format string) func(logfile.Record, io.Writer) error {
+	return func(r logfile.Record, w io.Writer) error {
+		conv, err := logfile.NewConverter(format, w, nil)
+		if err != nil {
+			return err
+		}
+		if err := conv.Write(r); err != nil {
+			return err
+		}
+		return conv.Flush()
+	}
+}
+
+// Clone implements zapcore.Encoder.
+func (e *convertEncoder) Clone() zapcore.Encoder {
+	return &convertEncoder{Encoder: e.Encoder.Clone(), convert: e.convert}
+}
+
+// EncodeEntry implements zapcore.Encoder.
+func (e *convertEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	encoded, err := e.Encoder.EncodeEntry(ent, fields)
+	if err != nil {
+		return nil, err
+	}
+	defer encoded.Free()
+
+	var r logfile.Record
+	if err := json.Unmarshal(encoded.Bytes(), &r); err != nil {
+		return nil, fmt.Errorf("failed to decode the encoded entry: %v", err)
+	}
+	out := convertPool.Get()
+	if err := e.convert(r, out); err != nil {
+		out.Free()
+		return nil, err
+	}
+	return out, nil
+}
+
+// gelfLevels maps the levels to their syslog severity, as GELF expects.
+var gelfLevels = map[zapcore.Level]int{
+	zapcore.DebugLevel:  7,
+	zapcore.InfoLevel:   6,
+	zapcore.WarnLevel:   4,
+	zapcore.ErrorLevel:  3,
+	zapcore.DPanicLevel: 2,
+	zapcore.PanicLevel:  2,
+	zapcore.FatalLevel:  2,
+}
+
+// writeGELF writes r as a GELF 1.1 message on one line. The hostname field
+// becomes the host, falling back to the host name of the machine, and the
+// other fields become additional fields, prefixed with an underscore.
+// Additional fields only hold strings and numbers: other values are
+// written in their string form.
+func writeGELF(r logfile.Record, w io.Writer) error {
+	msg := make(map[string]interface{}, len(r)+2)
+	for k, v := range r {
+		name := k
+		switch k {
+		case logfile.TimeKey, logfile.LevelKey, logfile.MessageKey, "hostname":
+			continue
+		case "id":
+			// _id is reserved by GELF.
+			name = "id_"
+		}
+		switch v.(type) {
+		case string, float64:
+		default:
+			v, _ = r.Field(k)
+		}
+		msg["_"+name] = v
+	}
+
+	host, _ := r["hostname"].(string)
+	if host == "" {
+		host, _ = os.Hostname()
+	}
+	msg["version"] = gelfVersion
+	msg["host"] = host
+	msg["short_message"] = r.Message()
+	msg["level"] = gelfLevels[r.Level()]
+	if t, ok := r.Time(); ok {
+		msg["timestamp"] = float64(t.UnixMilli()) / 1000
+	}
+
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	_, err = w.Write(b)
+	return err
+}
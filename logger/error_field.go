@@ -0,0 +1,114 @@
+// sad-go-logger/logger/error_field.go
+
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// maxErrorStackDepth bounds the frames of the stack traces of ErrorDetails.
+const maxErrorStackDepth = 64
+
+// errorDetails renders an error as a structured object in the shape of the
+// Elastic Common Schema error fields.
+type errorDetails struct {
+	err error
+	pcs []uintptr
+}
+
+// MarshalLogObject implements zapcore.ObjectMarshaler.
+func (d errorDetails) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("message", d.err.Error())
+	enc.AddString("type", fmt.Sprintf("%T", d.err))
+	if causes := errorCauses(d.err); len(causes) > 0 {
+		enc.AddArray("causes", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+			for _, cause := range causes {
+				arr.AppendObject(zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+					enc.AddString("message", cause.Error())
+					enc.AddString("type", fmt.Sprintf("%T", cause))
+					return nil
+				}))
+			}
+			return nil
+		}))
+	}
+	if len(d.pcs) > 0 {
+		enc.AddString("stack_trace", formatStack(d.pcs))
+	}
+	return nil
+}
+
+// errorCauses returns the errors wrapped by err, depth first, through
+// errors.Unwrap and the Unwrap() []error of errors.Join.
+func errorCauses(err error) []error {
+	var causes []error
+	var walk func(err error)
+	walk = func(err error) {
+		switch e := err.(type) {
+		case interface{ Unwrap() []error }:
+			for _, cause := range e.Unwrap() {
+				causes = append(causes, cause)
+				walk(cause)
+			}
+		default:
+			if cause := errors.Unwrap(err); cause != nil {
+				causes = append(causes, cause)
+				walk(cause)
+			}
+		}
+	}
+	walk(err)
+	return causes
+}
+
+// formatStack renders the program counters pcs as zap renders stack
+// traces: the function, then its file and line indented on the next line.
+func formatStack(pcs []uintptr) string {
+	var b strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(&b, "%s\n\t%s:%d", frame.Function, frame.File, frame.Line)
+		if !more {
+			return b.String()
+		}
+	}
+}
+
+// ErrorDetails constructs an error field that records err as an object
+// with its message, type, the message and type of the errors it wraps, and
+// the stack trace of the call, so that errors have the same error.message,
+// error.type, error.causes and error.stack_trace shape in every sink, e.g.
+//
+//	logger.Log.Error("Payment failed", logger.ErrorDetails(err))
+//
+// A nil error adds no field.
+func ErrorDetails(err error) zap.Field {
+	return errorDetailsField(err, 1)
+}
+
+// WithError returns the global Log with the ErrorDetails of err added to
+// every entry, e.g. logger.WithError(err).Error("Payment failed").
+func WithError(err error) *zap.Logger {
+	return Log.With(errorDetailsField(err, 1))
+}
+
+// errorDetailsField returns the field of ErrorDetails, the stack trace
+// starting skip frames above its caller.
+func errorDetailsField(err error, skip int) zap.Field {
+	if err == nil {
+		return zap.Skip()
+	}
+	pcs := make([]uintptr, maxErrorStackDepth)
+	n := runtime.Callers(skip+2, pcs)
+	return zap.Object("error", errorDetails{err: err, pcs: pcs[:n]})
+}
@@ -0,0 +1,117 @@
+// sad-go-logger/logger/event.go
+
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Keys of the event catalog fields.
+const (
+	EventKey        = "event"
+	EventVersionKey = "eventVersion"
+)
+
+// EventDefinition describes one event type of the catalog.
+type EventDefinition struct {
+	Name    string `json:"name"`
+	Version int    `json:"version"`
+
+	// Schema, if it lists fields, is registered with RegisterSchema.
+	Schema Schema `json:"schema"`
+}
+
+var (
+	eventsMu      sync.RWMutex
+	events        = map[string]EventDefinition{}
+	eventHookOnce sync.Once
+)
+
+// DefineEvent adds an event type to the catalog. Entries carrying the event
+// field with its name, see Event, are stamped with the eventVersion field,
+// so that consumers of the remote index can evolve their parsers safely as
+// the shape of the event changes: bump the version with every incompatible
+// change.
+func DefineEvent(def EventDefinition) error {
+	if def.Name == "" {
+		return fmt.Errorf("event name must not be empty")
+	}
+	if def.Version < 1 {
+		return fmt.Errorf("event %q: version must be at least 1", def.Name)
+	}
+	if len(def.Schema.Required) > 0 || len(def.Schema.Types) > 0 {
+		if err := RegisterSchema(def.Name, def.Schema); err != nil {
+			return err
+		}
+	}
+
+	eventsMu.Lock()
+	events[def.Name] = def
+	eventsMu.Unlock()
+
+	eventHookOnce.Do(func() {
+		RegisterHook(PreWrite, stampEventVersion)
+	})
+	return nil
+}
+
+// Event returns the field naming the event type of an entry.
+func Event(name string) zap.Field {
+	return zap.String(EventKey, name)
+}
+
+// Events returns the catalog, sorted by name.
+func Events() []EventDefinition {
+	eventsMu.RLock()
+	defer eventsMu.RUnlock()
+
+	defs := make([]EventDefinition, 0, len(events))
+	for _, def := range events {
+		defs = append(defs, def)
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Name < defs[j].Name })
+	return defs
+}
+
+// EventCatalogHandler serves the catalog as JSON, for the consumers of the
+// logs.
+func EventCatalogHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Events())
+	})
+}
+
+// stampEventVersion is the PreWrite hook adding the eventVersion field to
+// the entries of cataloged events.
+func stampEventVersion(e *Entry) error {
+	name := ""
+	for _, fields := range [][]zapcore.Field{e.Context, e.Fields} {
+		for _, f := range fields {
+			switch {
+			case f.Key == EventVersionKey:
+				return nil
+			case f.Key == EventKey && f.Type == zapcore.StringType:
+				name = f.String
+			}
+		}
+	}
+	if name == "" {
+		return nil
+	}
+
+	eventsMu.RLock()
+	def, ok := events[name]
+	eventsMu.RUnlock()
+	if ok {
+		e.Fields = append(e.Fields, zap.Int(EventVersionKey, def.Version))
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+// sad-go-logger/logger/expect.go
+
+package logger
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// Expectation describes entries a test expects to have been logged through
+// the logger returned by NewTest. Matchers are chained and all of them must
+// match the same entry:
+//
+//	logger.Expect(t).Level(zap.ErrorLevel).Message("payment failed").Field("order_id", 42)
+//
+// The expectation is verified when the test finishes, so it may be declared
+// before the code under test runs. Call Assert to verify it immediately.
+type Expectation struct {
+	t        testing.TB
+	obs      *Observer
+	matchers []entryMatcher
+	times    int // -1 means at least once
+	checked  bool
+}
+
+// entryMatcher is one condition of an Expectation, with a description used
+// in failure messages.
+type entryMatcher struct {
+	desc  string
+	match func(observer.LoggedEntry) bool
+}
+
+// Expect starts an expectation on the entries captured by the last logger
+// created with NewTest for t.
+func Expect(t testing.TB) *Expectation {
+	t.Helper()
+
+	v, ok := testObservers.Load(t)
+	if !ok {
+		t.Fatalf("logger.Expect: no test logger for %s, call logger.NewTest first", t.Name())
+		return nil
+	}
+
+	e := &Expectation{t: t, obs: v.(*Observer), times: -1}
+	t.Cleanup(func() {
+		if !e.checked {
+			e.t.Helper()
+			e.verify()
+		}
+	})
+	return e
+}
+
+// Level requires the entry to be logged at level.
+func (e *Expectation) Level(level zapcore.Level) *Expectation {
+	return e.with("level "+level.String(), func(le observer.LoggedEntry) bool {
+		return le.Level == level
+	})
+}
+
+// Message requires the entry message to be exactly msg.
+func (e *Expectation) Message(msg string) *Expectation {
+	return e.with(fmt.Sprintf("message %q", msg), func(le observer.LoggedEntry) bool {
+		return le.Message == msg
+	})
+}
+
+// MessageContains requires the entry message to contain snippet.
+func (e *Expectation) MessageContains(snippet string) *Expectation {
+	return e.with(fmt.Sprintf("message containing %q", snippet), func(le observer.LoggedEntry) bool {
+		return strings.Contains(le.Message, snippet)
+	})
+}
+
+// Field requires the entry to carry a field named key whose value equals
+// value once both are encoded, so Field("order_id", 42) matches
+// zap.Int64("order_id", 42) as well as zap.Int("order_id", 42).
+func (e *Expectation) Field(key string, value interface{}) *Expectation {
+	want := encodeFieldValue(zap.Any(key, value))
+	return e.with(fmt.Sprintf("field %s=%v", key, value), func(le observer.LoggedEntry) bool {
+		for _, f := range le.Context {
+			if f.Key == key && reflect.DeepEqual(encodeFieldValue(f), want) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// HasField requires the entry to carry a field named key, whatever its value.
+func (e *Expectation) HasField(key string) *Expectation {
+	return e.with("field "+key, func(le observer.LoggedEntry) bool {
+		for _, f := range le.Context {
+			if f.Key == key {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Times requires exactly n matching entries instead of at least one.
+func (e *Expectation) Times(n int) *Expectation {
+	e.times = n
+	return e
+}
+
+// Never requires that no entry matches.
+func (e *Expectation) Never() *Expectation {
+	return e.Times(0)
+}
+
+// Assert verifies the expectation now instead of when the test finishes.
+func (e *Expectation) Assert() {
+	e.t.Helper()
+	e.verify()
+}
+
+func (e *Expectation) with(desc string, match func(observer.LoggedEntry) bool) *Expectation {
+	e.matchers = append(e.matchers, entryMatcher{desc: desc, match: match})
+	return e
+}
+
+// verify reports a test error if the captured entries do not satisfy the
+// expectation.
+func (e *Expectation) verify() {
+	e.t.Helper()
+	e.checked = true
+
+	entries := e.obs.Entries()
+	count := 0
+	for _, le := range entries {
+		if e.matches(le) {
+			count++
+		}
+	}
+
+	if (e.times < 0 && count > 0) || count == e.times {
+		return
+	}
+
+	descs := make([]string, len(e.matchers))
+	for i, m := range e.matchers {
+		descs[i] = m.desc
+	}
+	want := "at least one entry"
+	if e.times >= 0 {
+		want = fmt.Sprintf("%d entries", e.times)
+	}
+
+	var captured strings.Builder
+	for _, le := range entries {
+		fmt.Fprintf(&captured, "\n\t%s %q %v", le.Level, le.Message, le.ContextMap())
+	}
+	e.t.Errorf("expected %s with %s, got %d; captured entries:%s", want, strings.Join(descs, ", "), count, captured.String())
+}
+
+func (e *Expectation) matches(le observer.LoggedEntry) bool {
+	for _, m := range e.matchers {
+		if !m.match(le) {
+			return false
+		}
+	}
+	return true
+}
+
+// encodeFieldValue returns the value f encodes to, so fields built with
+// different constructors can be compared.
+func encodeFieldValue(f zapcore.Field) interface{} {
+	enc := zapcore.NewMapObjectEncoder()
+	f.AddTo(enc)
+	return enc.Fields[f.Key]
+}
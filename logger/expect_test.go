@@ -0,0 +1,100 @@
+// sad-go-logger/logger/expect_test.go
+
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// recordingTB is a testing.TB recording the errors reported through it,
+// so that failing expectations can be tested.
+type recordingTB struct {
+	testing.TB
+	errors   []string
+	cleanups []func()
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Log(args ...interface{}) {}
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingTB) Cleanup(fn func()) {
+	r.cleanups = append(r.cleanups, fn)
+}
+
+// finish runs the cleanups, last registered first, as the testing package
+// does when a test finishes.
+func (r *recordingTB) finish() {
+	for i := len(r.cleanups) - 1; i >= 0; i-- {
+		r.cleanups[i]()
+	}
+}
+
+func TestExpectMatches(t *testing.T) {
+	log, _ := NewTest(t)
+
+	log.Error("payment failed", zap.Int64("order_id", 42), zap.String("reason", "card declined"))
+	log.Info("retrying", zap.Int("attempt", 1))
+	log.Info("retrying", zap.Int("attempt", 2))
+
+	Expect(t).Level(zap.ErrorLevel).Message("payment failed").Field("order_id", 42)
+	Expect(t).MessageContains("payment").HasField("reason")
+	Expect(t).Message("retrying").Times(2)
+	Expect(t).Message("retrying").Field("attempt", 2).Times(1).Assert()
+	Expect(t).Level(zap.WarnLevel).Never()
+}
+
+func TestExpectFailures(t *testing.T) {
+	tests := []struct {
+		name   string
+		expect func(*Expectation)
+		want   string
+	}{
+		{"missing message", func(e *Expectation) { e.Message("shipped") }, `expected at least one entry with message "shipped", got 0`},
+		{"wrong level", func(e *Expectation) { e.Level(zap.ErrorLevel).Message("retrying") }, "expected at least one entry with level error"},
+		{"wrong field value", func(e *Expectation) { e.Field("attempt", 3) }, "field attempt=3"},
+		{"wrong count", func(e *Expectation) { e.Message("retrying").Times(1) }, "expected 1 entries with message \"retrying\", got 2"},
+		{"never", func(e *Expectation) { e.MessageContains("retry").Never() }, "expected 0 entries"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recordingTB{TB: t}
+			log, _ := NewTest(rec)
+			log.Info("retrying", zap.Int("attempt", 1))
+			log.Info("retrying", zap.Int("attempt", 2))
+
+			tt.expect(Expect(rec))
+			rec.finish()
+
+			if len(rec.errors) != 1 {
+				t.Fatalf("got %d errors, want 1: %v", len(rec.errors), rec.errors)
+			}
+			if !strings.Contains(rec.errors[0], tt.want) {
+				t.Errorf("error = %q, want it to contain %q", rec.errors[0], tt.want)
+			}
+			if !strings.Contains(rec.errors[0], `info "retrying"`) {
+				t.Errorf("error does not list the captured entries: %q", rec.errors[0])
+			}
+		})
+	}
+}
+
+func TestExpectAssertVerifiesOnce(t *testing.T) {
+	rec := &recordingTB{TB: t}
+	NewTest(rec)
+
+	Expect(rec).Message("never logged").Assert()
+	rec.finish()
+
+	if len(rec.errors) != 1 {
+		t.Errorf("got %d errors, want 1 from Assert only: %v", len(rec.errors), rec.errors)
+	}
+}
@@ -0,0 +1,122 @@
+// sad-go-logger/logger/flight_recorder.go
+
+package logger
+
+import (
+	"encoding/json"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// defaultFlightRecorderSize is the number of entries kept when
+// LOG_FLIGHT_RECORDER_SIZE is not set.
+const defaultFlightRecorderSize = 100
+
+// flightRecorder keeps the last entries written, JSON-encoded, so that a
+// crash report can show what led to it. It is teed into every logger built
+// by Config.Build.
+type flightRecorder struct {
+	mu      sync.Mutex
+	entries [][]byte // ring buffer
+	next    int
+	full    bool
+
+	encoder zapcore.Encoder
+}
+
+// flight is the recorder shared by all loggers of the process.
+var flight = newFlightRecorder(defaultFlightRecorderSize)
+
+func newFlightRecorder(size int) *flightRecorder {
+	r := &flightRecorder{encoder: zapcore.NewJSONEncoder(newEncoderConfig())}
+	r.resize(size)
+	return r
+}
+
+// resize sets the number of entries kept, discarding the recorded ones. A
+// size of 0 disables the recorder.
+func (r *flightRecorder) resize(size int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries, r.next, r.full = make([][]byte, max(size, 0)), 0, false
+}
+
+func (r *flightRecorder) enabled() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.entries) > 0
+}
+
+func (r *flightRecorder) record(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := r.encoder.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	line := append([]byte(nil), buf.Bytes()...)
+	buf.Free()
+	if n := len(line); n > 0 && line[n-1] == '\n' {
+		line = line[:n-1]
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.entries) == 0 {
+		return nil
+	}
+	r.entries[r.next] = line
+	r.next = (r.next + 1) % len(r.entries)
+	r.full = r.full || r.next == 0
+	return nil
+}
+
+// snapshot returns the recorded entries, oldest first.
+func (r *flightRecorder) snapshot() []json.RawMessage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var entries []json.RawMessage
+	if r.full {
+		for _, e := range r.entries[r.next:] {
+			entries = append(entries, e)
+		}
+	}
+	for _, e := range r.entries[:r.next] {
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// flightCore is the zapcore.Core that feeds a flightRecorder with the
+// entries enabled by level.
+type flightCore struct {
+	recorder *flightRecorder
+	level    zapcore.LevelEnabler
+	fields   []zapcore.Field
+}
+
+func (c *flightCore) Enabled(level zapcore.Level) bool {
+	return c.level.Enabled(level) && c.recorder.enabled()
+}
+
+func (c *flightCore) With(fields []zapcore.Field) zapcore.Core {
+	return &flightCore{recorder: c.recorder, level: c.level, fields: append(append([]zapcore.Field(nil), c.fields...), fields...)}
+}
+
+func (c *flightCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *flightCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	all := fields
+	if len(c.fields) > 0 {
+		all = append(append([]zapcore.Field(nil), c.fields...), fields...)
+	}
+	return c.recorder.record(ent, all)
+}
+
+func (c *flightCore) Sync() error {
+	return nil
+}
@@ -0,0 +1,130 @@
+// sad-go-logger/logger/golden.go
+
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// GoldenEntry is one entry of the fixed set rendered by the golden-file
+// harness.
+type GoldenEntry struct {
+	Entry  zapcore.Entry
+	Fields []zapcore.Field
+}
+
+// GoldenEntries returns the fixed set of entries rendered by the golden-file
+// harness. It covers every level, common field types, nested objects,
+// errors, stack traces and characters that need escaping.
+func GoldenEntries() []GoldenEntry {
+	at := time.Date(2024, time.May, 1, 12, 30, 45, 123456789, time.UTC)
+	caller := zapcore.NewEntryCaller(0, "github.com/sadco-io/sad-go-logger/logger/golden.go", 42, true)
+	base := []zapcore.Field{
+		zap.String("hostname", "golden-host"),
+		zap.String("serviceName", "golden-service"),
+	}
+	with := func(fields ...zapcore.Field) []zapcore.Field {
+		return append(append([]zapcore.Field{}, base...), fields...)
+	}
+
+	return []GoldenEntry{
+		{
+			Entry:  zapcore.Entry{Level: zapcore.DebugLevel, Time: at, Message: "cache warmed", Caller: caller},
+			Fields: with(zap.Int("entries", 1024), zap.Duration("took", 1500*time.Millisecond)),
+		},
+		{
+			Entry:  zapcore.Entry{Level: zapcore.InfoLevel, Time: at.Add(time.Millisecond), Message: "User logged in", Caller: caller},
+			Fields: with(zap.String("user", "john"), zap.Bool("mfa", true), zap.Float64("score", 0.75)),
+		},
+		{
+			Entry: zapcore.Entry{Level: zapcore.WarnLevel, Time: at.Add(2 * time.Millisecond), Message: "quoted \"value\"\nand a second line\ttabbed", Caller: caller},
+			Fields: with(
+				zap.Strings("tags", []string{"a", "b"}),
+				zap.Any("nested", map[string]interface{}{"depth": 1, "inner": map[string]interface{}{"ok": true}}),
+			),
+		},
+		{
+			Entry: zapcore.Entry{
+				Level:   zapcore.ErrorLevel,
+				Time:    at.Add(3 * time.Millisecond),
+				Message: "payment failed",
+				Caller:  caller,
+				Stack:   "main.charge\n\t/app/main.go:10\nmain.main\n\t/app/main.go:3",
+			},
+			Fields: with(
+				zap.Int("order_id", 42),
+				zap.Error(errors.New("card declined")),
+				SecretField("card", NewSecret("4111111111111111")),
+				Binary("signature", []byte{0xde, 0xad, 0xbe, 0xef}),
+			),
+		},
+	}
+}
+
+// RenderGolden renders GoldenEntries with the encoder registered as format,
+// one entry per line.
+func RenderGolden(format string) ([]byte, error) {
+	enc, err := newEncoder(format, newEncoderConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	for _, ge := range GoldenEntries() {
+		buf, err := enc.Clone().EncodeEntry(ge.Entry, ge.Fields)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %q with %s: %v", ge.Entry.Message, format, err)
+		}
+		out.Write(buf.Bytes())
+		buf.Free()
+	}
+	return out.Bytes(), nil
+}
+
+// AssertGolden renders GoldenEntries through every registered encoder and
+// compares the output with dir/<format>.golden, failing t on any
+// difference. Set UPDATE_GOLDEN=true to (re)write the golden files instead.
+//
+// Downstream consumers can call it from their own tests to pin their
+// parsers to the output contract of the version of the logger they use.
+func AssertGolden(t testing.TB, dir string) {
+	t.Helper()
+
+	update := os.Getenv("UPDATE_GOLDEN") == "true"
+	for _, format := range EncoderNames() {
+		got, err := RenderGolden(format)
+		if err != nil {
+			t.Errorf("failed to render %s golden output: %v", format, err)
+			continue
+		}
+
+		path := filepath.Join(dir, format+".golden")
+		if update {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				t.Fatalf("failed to create golden directory %s: %v", dir, err)
+			}
+			if err := os.WriteFile(path, got, 0644); err != nil {
+				t.Errorf("failed to write %s: %v", path, err)
+			}
+			continue
+		}
+
+		want, err := os.ReadFile(path)
+		if err != nil {
+			t.Errorf("failed to read %s (run with UPDATE_GOLDEN=true to create it): %v", path, err)
+			continue
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s output does not match %s\n--- got ---\n%s\n--- want ---\n%s", format, path, got, want)
+		}
+	}
+}
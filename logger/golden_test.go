@@ -0,0 +1,25 @@
+// sad-go-logger/logger/golden_test.go
+
+package logger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGolden(t *testing.T) {
+	AssertGolden(t, "testdata")
+}
+
+func TestRenderGoldenUnknownFormat(t *testing.T) {
+	if _, err := RenderGolden("xml"); err == nil || !strings.Contains(err.Error(), `unknown encoder "xml"`) {
+		t.Errorf("RenderGolden(xml) error = %v, want unknown encoder", err)
+	}
+}
+
+func TestGoldenCoversBuiltinFormats(t *testing.T) {
+	registered := strings.Join(EncoderNames(), ",")
+	if want := "console,ecs,gelf,json,logfmt"; registered != want {
+		t.Errorf("EncoderNames() = %s, want %s", registered, want)
+	}
+}
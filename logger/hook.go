@@ -0,0 +1,186 @@
+// sad-go-logger/logger/hook.go
+
+package logger
+
+import (
+	"errors"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// HookStage selects when a hook registered with RegisterHook runs.
+type HookStage int
+
+const (
+	// PreWrite hooks run before an entry reaches any sink. They may change
+	// the entry, e.g. add fields or rewrite the message; returning an error
+	// drops the entry.
+	PreWrite HookStage = iota
+
+	// PostWrite hooks run once the entry was handed to every sink, with
+	// Entry.Err set to the delivery errors. Their own errors are ignored.
+	PostWrite
+)
+
+// Entry is a log entry seen by a hook.
+type Entry struct {
+	zapcore.Entry
+
+	// Fields are the fields passed with the entry.
+	Fields []zapcore.Field
+
+	// Context holds the fields added with Logger.With. They have already
+	// been encoded: changing them has no effect.
+	Context []zapcore.Field
+
+	// Err holds the errors returned by the sinks, for PostWrite hooks.
+	Err error
+}
+
+// Hook inspects or changes an entry, see HookStage.
+type Hook func(*Entry) error
+
+// registeredHook is a hook and the id that removes it.
+type registeredHook struct {
+	id uint64
+	fn Hook
+}
+
+var (
+	hooksMu sync.RWMutex
+	hooks   [PostWrite + 1][]registeredHook
+	hookID  uint64
+)
+
+// RegisterHook adds fn to the hooks run at stage by every logger built with
+// Config.Build or NewTest, in registration order. It returns a function
+// removing the hook.
+func RegisterHook(stage HookStage, fn Hook) (remove func()) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+
+	hookID++
+	id := hookID
+	hooks[stage] = append(hooks[stage], registeredHook{id: id, fn: fn})
+
+	return func() {
+		hooksMu.Lock()
+		defer hooksMu.Unlock()
+		// Copy the list: writes in progress may still range over it.
+		list := make([]registeredHook, 0, len(hooks[stage]))
+		for _, h := range hooks[stage] {
+			if h.id != id {
+				list = append(list, h)
+			}
+		}
+		hooks[stage] = list
+	}
+}
+
+// stageHooks returns the hooks registered for stage.
+func stageHooks(stage HookStage) []registeredHook {
+	hooksMu.RLock()
+	defer hooksMu.RUnlock()
+	return hooks[stage]
+}
+
+// hookCore tees the sinks of a logger, running the registered hooks around
+// every write, and redacts and scrubs the entries, see SetRedactedFields
+// and SetScrubbers, once the PreWrite hooks ran. Each sink's level is
+// checked again after the PreWrite hooks, so a hook may also change the
+// entry level.
+type hookCore struct {
+	cores   []zapcore.Core
+	context []zapcore.Field
+
+	// attached are the sinks added with AttachSink, receiving the entries
+	// enabled by level.
+	attached *attachedSinks
+	level    zapcore.LevelEnabler
+}
+
+func (c *hookCore) Enabled(level zapcore.Level) bool {
+	for _, core := range c.cores {
+		if core.Enabled(level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *hookCore) With(fields []zapcore.Field) zapcore.Core {
+	fields = scrubFields(redactFields(fields))
+	cores := make([]zapcore.Core, len(c.cores))
+	for i, core := range c.cores {
+		cores[i] = core.With(fields)
+	}
+	context := append(append([]zapcore.Field(nil), c.context...), fields...)
+	return &hookCore{cores: cores, context: context, attached: c.attached, level: c.level}
+}
+
+func (c *hookCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *hookCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	e := &Entry{Entry: ent, Fields: fields, Context: c.context}
+	if pre := stageHooks(PreWrite); len(pre) > 0 {
+		// Hooks may modify the fields; leave the caller's slice alone.
+		e.Fields = append([]zapcore.Field(nil), fields...)
+		for _, hook := range pre {
+			if err := hook.fn(e); err != nil {
+				return nil
+			}
+		}
+	}
+
+	e.Fields = redactFields(e.Fields)
+	scrubEntry(e)
+
+	var errs []error
+	for _, core := range c.cores {
+		if core.Enabled(e.Level) {
+			if err := core.Write(e.Entry, e.Fields); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	for _, s := range c.attached.cores() {
+		if !c.level.Enabled(e.Level) || !s.core.Enabled(e.Level) {
+			continue
+		}
+		// The sink may have been attached after Logger.With.
+		core := s.core
+		if len(c.context) > 0 {
+			core = core.With(c.context)
+		}
+		if err := core.Write(e.Entry, e.Fields); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	e.Err = errors.Join(errs...)
+
+	for _, hook := range stageHooks(PostWrite) {
+		hook.fn(e)
+	}
+	return e.Err
+}
+
+func (c *hookCore) Sync() error {
+	var errs []error
+	for _, core := range c.cores {
+		if err := core.Sync(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, s := range c.attached.cores() {
+		if err := s.core.Sync(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
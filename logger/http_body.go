@@ -0,0 +1,280 @@
+// sad-go-logger/logger/http_body.go
+
+package logger
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// redactedValue replaces redacted header values and body fields.
+const redactedValue = "[REDACTED]"
+
+// BodyLogOptions configures the capture of request and response bodies.
+// The zero value of a field selects its default.
+type BodyLogOptions struct {
+	// MaxBytes is the number of body bytes captured (default: 4096). The
+	// rest of the body is still read and written, but not logged.
+	MaxBytes int
+
+	// ContentTypes are the media types whose bodies are captured; a
+	// trailing "/*" matches a whole type, and "+json" suffixes match
+	// "application/json". Default: JSON, form, XML and text.
+	ContentTypes []string
+
+	// RedactHeaders are the headers, case-insensitive, whose values are
+	// replaced with "[REDACTED]". Default: Authorization, Proxy-Authorization,
+	// Cookie, Set-Cookie, X-Api-Key and X-Auth-Token.
+	RedactHeaders []string
+
+	// RedactFields are the JSON and form fields, case-insensitive, whose
+	// values are replaced with "[REDACTED]". Default: password, passwd,
+	// secret, token, access_token, refresh_token, api_key, apikey,
+	// client_secret, authorization and credit_card.
+	RedactFields []string
+}
+
+var (
+	defaultBodyContentTypes = []string{
+		"application/json", "application/x-www-form-urlencoded",
+		"application/xml", "text/*",
+	}
+	defaultRedactHeaders = []string{
+		"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie",
+		"X-Api-Key", "X-Auth-Token",
+	}
+	defaultRedactFields = []string{
+		"password", "passwd", "secret", "token", "access_token",
+		"refresh_token", "api_key", "apikey", "client_secret",
+		"authorization", "credit_card",
+	}
+)
+
+// bodyLogger captures bodies as configured by BodyLogOptions.
+type bodyLogger struct {
+	maxBytes     int
+	contentTypes []string
+	headers      map[string]bool // redacted, canonical
+	keys         map[string]bool // redacted, lower case
+	jsonField    *regexp.Regexp
+}
+
+func newBodyLogger(opts BodyLogOptions) *bodyLogger {
+	b := &bodyLogger{maxBytes: opts.MaxBytes, contentTypes: opts.ContentTypes, headers: map[string]bool{}, keys: map[string]bool{}}
+	if b.maxBytes <= 0 {
+		b.maxBytes = 4096
+	}
+	if b.contentTypes == nil {
+		b.contentTypes = defaultBodyContentTypes
+	}
+	headers, fields := opts.RedactHeaders, opts.RedactFields
+	if headers == nil {
+		headers = defaultRedactHeaders
+	}
+	if fields == nil {
+		fields = defaultRedactFields
+	}
+	for _, h := range headers {
+		b.headers[http.CanonicalHeaderKey(h)] = true
+	}
+	var quoted []string
+	for _, f := range fields {
+		b.keys[strings.ToLower(f)] = true
+		quoted = append(quoted, regexp.QuoteMeta(f))
+	}
+	if len(quoted) > 0 {
+		// A sensitive key and its string or scalar value. Matching the text
+		// rather than decoding it also redacts truncated bodies.
+		b.jsonField = regexp.MustCompile(`(?i)("(?:` + strings.Join(quoted, "|") + `)"\s*:\s*)("(?:[^"\\]|\\.)*"|[^,}\]\s]+)`)
+	}
+	return b
+}
+
+// captured reports whether bodies of contentType are captured.
+func (b *bodyLogger) captured(contentType string) bool {
+	media, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	if strings.HasPrefix(media, "application/") && strings.HasSuffix(media, "+json") {
+		media = "application/json"
+	}
+	for _, t := range b.contentTypes {
+		if t == media || (strings.HasSuffix(t, "/*") && strings.HasPrefix(media, t[:len(t)-1])) {
+			return true
+		}
+	}
+	return false
+}
+
+// header returns h with the sensitive values redacted.
+func (b *bodyLogger) header(h http.Header) map[string]string {
+	m := make(map[string]string, len(h))
+	for key, values := range h {
+		if b.headers[http.CanonicalHeaderKey(key)] {
+			m[key] = redactedValue
+		} else {
+			m[key] = strings.Join(values, ", ")
+		}
+	}
+	return m
+}
+
+// redact returns body with the values of the sensitive fields redacted.
+func (b *bodyLogger) redact(contentType string, body []byte) string {
+	media, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case media == "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return string(body)
+		}
+		for key := range values {
+			if b.keys[strings.ToLower(key)] {
+				values[key] = []string{redactedValue}
+			}
+		}
+		return values.Encode()
+	case b.jsonField != nil && (media == "application/json" || strings.HasSuffix(media, "+json")):
+		return b.jsonField.ReplaceAllString(string(body), `$1"`+redactedValue+`"`)
+	}
+	return string(body)
+}
+
+// fields returns the fields describing a captured body, under keys
+// prefixed with prefix.
+func (b *bodyLogger) fields(prefix string, h http.Header, body []byte, size int64) []zap.Field {
+	fields := []zap.Field{zap.Any(prefix+"Headers", b.header(h))}
+	contentType := h.Get("Content-Type")
+	if size == 0 || !b.captured(contentType) {
+		return append(fields, zap.Int64(prefix+"BodyBytes", size))
+	}
+	fields = append(fields, zap.String(prefix+"Body", b.redact(contentType, body)), zap.Int64(prefix+"BodyBytes", size))
+	if size > int64(len(body)) {
+		fields = append(fields, zap.Bool(prefix+"BodyTruncated", true))
+	}
+	return fields
+}
+
+// BodyRedactor renders the headers and bodies of servers other than
+// net/http, such as fasthttp, as BodyLogMiddleware does.
+type BodyRedactor struct {
+	body *bodyLogger
+}
+
+// NewBodyRedactor returns a BodyRedactor capturing and redacting as
+// configured by opts.
+func NewBodyRedactor(opts BodyLogOptions) *BodyRedactor {
+	return &BodyRedactor{body: newBodyLogger(opts)}
+}
+
+// Fields returns the headers h and the beginning of body, read in full, as
+// the fields of BodyLogMiddleware prefixed with prefix, "request" or
+// "response", e.g. "requestHeaders", "requestBody", "requestBodyBytes" and
+// "requestBodyTruncated".
+func (r *BodyRedactor) Fields(prefix string, h http.Header, body []byte) []zap.Field {
+	size := int64(len(body))
+	if len(body) > r.body.maxBytes {
+		body = body[:r.body.maxBytes]
+	}
+	return r.body.fields(prefix, h, body, size)
+}
+
+// RequestBodyFields returns the headers and the beginning of the body of r
+// as "requestHeaders", "requestBody", "requestBodyBytes" and
+// "requestBodyTruncated" fields, redacted as configured by opts. The body is
+// left readable by the handler. Bodies of unlisted content types are not
+// captured, only their size as far as it is known.
+func RequestBodyFields(r *http.Request, opts BodyLogOptions) []zap.Field {
+	return newBodyLogger(opts).request(r)
+}
+
+func (b *bodyLogger) request(r *http.Request) []zap.Field {
+	if r.Body == nil || r.Body == http.NoBody || !b.captured(r.Header.Get("Content-Type")) {
+		return b.fields("request", r.Header, nil, max(r.ContentLength, 0))
+	}
+
+	// Read one byte past the limit to tell whether the body is truncated.
+	head, _ := io.ReadAll(io.LimitReader(r.Body, int64(b.maxBytes)+1))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), r.Body), r.Body}
+
+	size := int64(len(head))
+	if r.ContentLength > size {
+		size = r.ContentLength
+	}
+	if len(head) > b.maxBytes {
+		head = head[:b.maxBytes]
+	}
+	return b.fields("request", r.Header, head, size)
+}
+
+// ResponseCapture is a ResponseWriter recording the status, headers and the
+// beginning of the body written, for logging with Fields.
+type ResponseCapture struct {
+	*statusRecorder
+	body *bodyLogger
+	head []byte
+}
+
+// NewResponseCapture returns a ResponseCapture writing to w and capturing
+// as configured by opts.
+func NewResponseCapture(w http.ResponseWriter, opts BodyLogOptions) *ResponseCapture {
+	return &ResponseCapture{statusRecorder: &statusRecorder{ResponseWriter: w, status: http.StatusOK}, body: newBodyLogger(opts)}
+}
+
+func (c *ResponseCapture) Write(p []byte) (int, error) {
+	if room := c.body.maxBytes - len(c.head); room > 0 {
+		c.head = append(c.head, p[:min(room, len(p))]...)
+	}
+	return c.statusRecorder.Write(p)
+}
+
+// Status returns the status code written.
+func (c *ResponseCapture) Status() int {
+	return c.status
+}
+
+// Fields returns the status, headers and the beginning of the body written
+// as "status", "responseHeaders", "responseBody", "responseBodyBytes" and
+// "responseBodyTruncated" fields.
+func (c *ResponseCapture) Fields() []zap.Field {
+	return append([]zap.Field{zap.Int("status", c.status)}, c.body.fields("response", c.Header(), c.head, c.bytes)...)
+}
+
+// BodyLogMiddleware logs a debug "HTTP exchange" entry for every request
+// with its headers and bodies, captured and redacted as configured by opts,
+// to debug API integrations. The entry is written through the request
+// logger, see FromContext, so that it can be enabled for a single request by
+// DebugOverrideMiddleware; nothing is captured when debug is disabled.
+func BodyLogMiddleware(opts BodyLogOptions) func(http.Handler) http.Handler {
+	b := newBodyLogger(opts)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			l := FromContext(r.Context())
+			if !l.Core().Enabled(zapcore.DebugLevel) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+			fields := append([]zap.Field{zap.String("method", r.Method), zap.String("uri", r.RequestURI)}, b.request(r)...)
+			rec := &ResponseCapture{statusRecorder: &statusRecorder{ResponseWriter: w, status: http.StatusOK}, body: b}
+			next.ServeHTTP(rec, r)
+
+			fields = append(fields, rec.Fields()...)
+			l.Debug("HTTP exchange", append(fields, zap.Duration("duration", time.Since(start)))...)
+		})
+	}
+}
@@ -0,0 +1,183 @@
+// sad-go-logger/logger/http_log.go
+
+package logger
+
+import (
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// HTTPLogOptions configures the request entries of an HTTPLogger.
+type HTTPLogOptions struct {
+	// SkipPaths are the paths whose requests are not logged, such as
+	// health checks; a trailing "*" matches a prefix, e.g. "/static/*".
+	SkipPaths []string
+
+	// SampleRates are the fractions of the successful requests logged, by
+	// path, with the patterns of SkipPaths, the longest matching pattern
+	// applying, e.g. {"/api/events": 0.01}. Requests with a status of 400 or
+	// above are always logged. Other paths are logged in full.
+	SampleRates map[string]float64
+
+	// TrustProxy takes the remote IP from the X-Forwarded-For or X-Real-IP
+	// header, for services behind a reverse proxy. Only enable it if the
+	// proxy overwrites these headers.
+	TrustProxy bool
+}
+
+// HTTPRequest is a request served, as logged by an HTTPLogger.
+type HTTPRequest struct {
+	Method    string
+	Path      string
+	Status    int
+	Bytes     int64
+	Latency   time.Duration
+	RemoteIP  string
+	UserAgent string
+}
+
+// Fields returns the request as the method, path, status, bytes, latency,
+// remoteIP and userAgent fields, shared by the middlewares of every
+// framework so that dashboards work across them.
+func (r HTTPRequest) Fields() []zap.Field {
+	fields := []zap.Field{
+		zap.String("method", r.Method),
+		zap.String("path", r.Path),
+		zap.Int("status", r.Status),
+		zap.Int64("bytes", r.Bytes),
+		zap.Duration("latency", r.Latency),
+		zap.String("remoteIP", r.RemoteIP),
+	}
+	if r.UserAgent != "" {
+		fields = append(fields, zap.String("userAgent", r.UserAgent))
+	}
+	return fields
+}
+
+// HTTPLogger logs the requests served as "HTTP request" entries, at info
+// level, warn for a status of 400 and above, and error for 500 and above.
+type HTTPLogger struct {
+	opts HTTPLogOptions
+}
+
+// NewHTTPLogger returns an HTTPLogger configured by opts.
+func NewHTTPLogger(opts HTTPLogOptions) *HTTPLogger {
+	return &HTTPLogger{opts: opts}
+}
+
+// defaultHTTPLogger is the logger of HTTPMiddleware.
+var defaultHTTPLogger = NewHTTPLogger(HTTPLogOptions{})
+
+// HTTPMiddleware logs every request served by next through the request
+// logger, see FromContext, with the fields of HTTPRequest. Unlike
+// AccessLogMiddleware, the entries go to the sinks of Log, with the
+// request id and trace fields of the request. NewHTTPLogger skips and
+// samples paths.
+func HTTPMiddleware(next http.Handler) http.Handler {
+	return defaultHTTPLogger.Middleware(next)
+}
+
+// Middleware logs every request served by next, see HTTPMiddleware.
+func (h *HTTPLogger) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.skipped(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+		r = r.WithContext(requestContext(r))
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		h.Log(FromContext(r.Context()), HTTPRequest{
+			Method:    r.Method,
+			Path:      r.URL.Path,
+			Status:    rec.status,
+			Bytes:     rec.bytes,
+			Latency:   time.Since(start),
+			RemoteIP:  RemoteIP(r, h.opts.TrustProxy),
+			UserAgent: r.UserAgent(),
+		})
+	})
+}
+
+// Log writes req to l, unless its path is skipped or sampled out, for the
+// middlewares of other frameworks.
+func (h *HTTPLogger) Log(l *zap.Logger, req HTTPRequest) {
+	if h.skipped(req.Path) {
+		return
+	}
+	if req.Status < 400 {
+		if rate, ok := h.sampleRate(req.Path); ok && rate < 1 && rand.Float64() >= rate {
+			return
+		}
+	}
+
+	switch {
+	case req.Status >= 500:
+		l.Error("HTTP request", req.Fields()...)
+	case req.Status >= 400:
+		l.Warn("HTTP request", req.Fields()...)
+	default:
+		l.Info("HTTP request", req.Fields()...)
+	}
+}
+
+// skipped reports whether the requests of path are not logged.
+func (h *HTTPLogger) skipped(path string) bool {
+	for _, pattern := range h.opts.SkipPaths {
+		if pathMatches(pattern, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// sampleRate returns the sample rate of the longest pattern matching path.
+func (h *HTTPLogger) sampleRate(path string) (float64, bool) {
+	var rate float64
+	longest := -1
+	for pattern, r := range h.opts.SampleRates {
+		if len(pattern) > longest && pathMatches(pattern, path) {
+			rate, longest = r, len(pattern)
+		}
+	}
+	return rate, longest >= 0
+}
+
+// pathMatches reports whether path matches pattern, a path or a prefix
+// followed by "*".
+func pathMatches(pattern, path string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(path, prefix)
+	}
+	return path == pattern
+}
+
+// RemoteIP returns the IP address of the client of r: the first address of
+// its X-Forwarded-For header, or its X-Real-IP header, if trustProxy is
+// set, or else the host of its RemoteAddr.
+func RemoteIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if v := r.Header.Get("X-Forwarded-For"); v != "" {
+			first, _, _ := strings.Cut(v, ",")
+			if ip := strings.TrimSpace(first); ip != "" {
+				return ip
+			}
+		}
+		if v := strings.TrimSpace(r.Header.Get("X-Real-IP")); v != "" {
+			return v
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
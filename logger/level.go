@@ -0,0 +1,102 @@
+// sad-go-logger/logger/level.go
+
+package logger
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// levelAliases are the names accepted by ParseLevel besides zap's own.
+var levelAliases = map[string]zapcore.Level{
+	"warning":  zapcore.WarnLevel,
+	"err":      zapcore.ErrorLevel,
+	"critical": zapcore.FatalLevel,
+	"crit":     zapcore.FatalLevel,
+}
+
+// ParseLevel parses a level name, case-insensitively: "debug", "info",
+// "warn", "error", "dpanic", "panic" or "fatal", the aliases "warning",
+// "err", "critical" and "crit", or zap's numeric value from -1 (debug) to 5
+// (fatal).
+func ParseLevel(s string) (zapcore.Level, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	if level, ok := levelAliases[name]; ok {
+		return level, nil
+	}
+	if n, err := strconv.Atoi(name); err == nil {
+		level := zapcore.Level(n)
+		if n < int(zapcore.DebugLevel) || n > int(zapcore.FatalLevel) {
+			return zapcore.InfoLevel, fmt.Errorf("invalid level %q, expected a number from %d to %d", s, zapcore.DebugLevel, zapcore.FatalLevel)
+		}
+		return level, nil
+	}
+	var level zapcore.Level
+	if name == "" || level.UnmarshalText([]byte(name)) != nil {
+		return zapcore.InfoLevel, fmt.Errorf("invalid level %q, expected debug, info, warn, error, dpanic, panic or fatal", s)
+	}
+	return level, nil
+}
+
+// SetLevel changes the minimum level of the global Log, in any form
+// accepted by ParseLevel, without a restart. The console, file and remote
+// sinks, and the loggers derived from Log, follow it immediately.
+func SetLevel(level string) error {
+	l, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	globalLevel().SetLevel(l)
+	Log.Warn("Log level changed", zap.String("level", l.String()))
+	return nil
+}
+
+// GetLevel returns the minimum level of the global Log, e.g. "info".
+func GetLevel() string {
+	return globalLevel().Level().String()
+}
+
+// globalLevel returns the level of the global Log, before Init included.
+func globalLevel() zap.AtomicLevel {
+	if global != nil {
+		return global.level
+	}
+	return consoleLevel
+}
+
+// LevelHandler serves the level of the global Log, see
+// zap.AtomicLevel.ServeHTTP: GET returns it as {"level":"info"}, and PUT
+// changes it with a level form value, or the same JSON document with an
+// application/json content type, e.g.
+//
+//	curl -X PUT -d level=debug localhost:8080/loglevel
+//
+// When LOG_LEVEL_HANDLER_TOKEN is set, requests must carry it in an
+// "Authorization: Bearer <token>" header; the handler does no other
+// authentication.
+func LevelHandler() http.Handler {
+	token := os.Getenv("LOG_LEVEL_HANDLER_TOKEN")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token != "" {
+			auth, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(auth), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+		level := globalLevel()
+		previous := level.Level()
+		level.ServeHTTP(w, r)
+		if current := level.Level(); current != previous {
+			Log.Warn("Log level changed", zap.String("level", current.String()), zap.String("remoteAddr", r.RemoteAddr))
+		}
+	})
+}
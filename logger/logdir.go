@@ -0,0 +1,75 @@
+// sad-go-logger/logger/logdir.go
+
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/sadco-io/sad-go-logger/logger/logfile"
+)
+
+var (
+	logDirMu   sync.Mutex
+	logDirPath string
+)
+
+// logDir returns the directory of the log files, created if needed: the
+// first usable of logfile.Dirs for the service, LOG_DIR when set. It is
+// resolved once, for the files of every logger of the process, and again
+// after resetLogDir.
+func logDir() string {
+	return resolveLogDir(serviceName)
+}
+
+// resolveLogDir is logDir for the given service, falling back to
+// SERVICE_NAME, when the directory is not resolved yet.
+func resolveLogDir(service string) string {
+	logDirMu.Lock()
+	defer logDirMu.Unlock()
+	if logDirPath == "" {
+		if service == "" {
+			service = os.Getenv("SERVICE_NAME")
+		}
+		dirs := logfile.Dirs(service)
+		for i, dir := range dirs {
+			err := os.MkdirAll(dir.Path, dir.Perm)
+			if err == nil {
+				err = checkWritable(dir.Path)
+			}
+			if err == nil || i == len(dirs)-1 {
+				if err != nil {
+					fmt.Printf("Warning: Unable to create log directory '%s': %v\n", dir.Path, err)
+				}
+				logDirPath = dir.Path
+				break
+			}
+		}
+	}
+	return logDirPath
+}
+
+// resetLogDir makes logDir resolve the directory again, see Reload.
+func resetLogDir() {
+	logDirMu.Lock()
+	defer logDirMu.Unlock()
+	logDirPath = ""
+}
+
+// logPath returns the path of the log file name.
+func logPath(name string) string {
+	return filepath.Join(logDir(), name)
+}
+
+// checkWritable reports an error if files cannot be created in dir, such
+// as a system directory provisioned for another user.
+func checkWritable(dir string) error {
+	f, err := os.CreateTemp(dir, ".sadlog-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
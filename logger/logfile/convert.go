@@ -0,0 +1,192 @@
+// sad-go-logger/logger/logfile/convert.go
+
+package logfile
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ECSVersion is the Elastic Common Schema version stamped on ECS output.
+const ECSVersion = "8.11.0"
+
+// Formats lists the output formats supported by NewConverter.
+var Formats = []string{"csv", "ecs", "logfmt", "ndjson"}
+
+// Converter writes records in another format.
+type Converter interface {
+	// Write converts and writes one record.
+	Write(r Record) error
+
+	// Flush writes any buffered output.
+	Flush() error
+}
+
+// NewConverter returns a Converter writing format to w. Columns are only
+// used by the CSV format; when empty, the CSV output has the timestamp,
+// level and message columns only.
+func NewConverter(format string, w io.Writer, columns []string) (Converter, error) {
+	switch format {
+	case "csv":
+		return newCSVConverter(w, columns)
+	case "ecs":
+		return &jsonConverter{w: bufio.NewWriter(w), convert: ToECS}, nil
+	case "logfmt":
+		return &logfmtConverter{w: bufio.NewWriter(w)}, nil
+	case "ndjson":
+		return &jsonConverter{w: bufio.NewWriter(w), convert: normalize}, nil
+	default:
+		return nil, fmt.Errorf("unknown format %q (supported: %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+// rfc3339Time returns the record timestamp as an RFC 3339 string in UTC, or
+// the raw value if it cannot be parsed.
+func rfc3339Time(r Record) interface{} {
+	if t, ok := r.Time(); ok {
+		return t.UTC().Format(time.RFC3339Nano)
+	}
+	return r[TimeKey]
+}
+
+// timeText returns the record timestamp as text for flat formats, empty if
+// the record has none.
+func timeText(r Record) string {
+	if _, ok := r[TimeKey]; !ok {
+		return ""
+	}
+	return FormatValue(rfc3339Time(r))
+}
+
+// normalize returns a copy of r with the timestamp in RFC 3339 UTC.
+func normalize(r Record) map[string]interface{} {
+	out := make(map[string]interface{}, len(r))
+	for k, v := range r {
+		out[k] = v
+	}
+	if _, ok := r[TimeKey]; ok {
+		out[TimeKey] = rfc3339Time(r)
+	}
+	return out
+}
+
+// ToECS maps a record to the Elastic Common Schema. The logger's own keys
+// are mapped to their ECS equivalents; other fields are kept as they are.
+func ToECS(r Record) map[string]interface{} {
+	out := make(map[string]interface{}, len(r)+2)
+	for k, v := range r {
+		switch k {
+		case TimeKey, LevelKey, MessageKey:
+		case "hostname":
+			out["host"] = map[string]interface{}{"hostname": v}
+		case "serviceName":
+			out["service"] = map[string]interface{}{"name": v}
+		case "error":
+			out["error"] = map[string]interface{}{"message": v}
+		default:
+			out[k] = v
+		}
+	}
+	out["@timestamp"] = rfc3339Time(r)
+	out["log"] = map[string]interface{}{"level": r.Level().String()}
+	out["message"] = r.Message()
+	out["ecs"] = map[string]interface{}{"version": ECSVersion}
+	return out
+}
+
+// jsonConverter writes one JSON object per line.
+type jsonConverter struct {
+	w       *bufio.Writer
+	convert func(Record) map[string]interface{}
+}
+
+func (c *jsonConverter) Write(r Record) error {
+	b, err := json.Marshal(c.convert(r))
+	if err != nil {
+		return err
+	}
+	c.w.Write(b)
+	return c.w.WriteByte('\n')
+}
+
+func (c *jsonConverter) Flush() error {
+	return c.w.Flush()
+}
+
+// logfmtConverter writes key=value lines.
+type logfmtConverter struct {
+	w *bufio.Writer
+}
+
+func (c *logfmtConverter) Write(r Record) error {
+	c.w.WriteString("time=" + logfmtValue(timeText(r)))
+	c.w.WriteString(" level=" + r.Level().String())
+	c.w.WriteString(" msg=" + logfmtValue(r.Message()))
+
+	keys := make([]string, 0, len(r))
+	for k := range r {
+		if k != TimeKey && k != LevelKey && k != MessageKey {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		value, _ := r.Field(k)
+		c.w.WriteString(" " + k + "=" + logfmtValue(value))
+	}
+	return c.w.WriteByte('\n')
+}
+
+func (c *logfmtConverter) Flush() error {
+	return c.w.Flush()
+}
+
+// logfmtValue quotes s if it is empty or contains spaces, quotes, equal
+// signs or control characters.
+func logfmtValue(s string) string {
+	if s == "" || strings.ContainsAny(s, " \"=\t\r\n") {
+		return fmt.Sprintf("%q", s)
+	}
+	return s
+}
+
+// csvConverter writes a header row followed by one row per record.
+type csvConverter struct {
+	w       *csv.Writer
+	columns []string
+}
+
+func newCSVConverter(w io.Writer, columns []string) (*csvConverter, error) {
+	header := append([]string{TimeKey, LevelKey, MessageKey}, columns...)
+	c := &csvConverter{w: csv.NewWriter(w), columns: header}
+	if err := c.w.Write(header); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *csvConverter) Write(r Record) error {
+	row := make([]string, len(c.columns))
+	for i, col := range c.columns {
+		switch col {
+		case TimeKey:
+			row[i] = timeText(r)
+		case LevelKey:
+			row[i] = r.Level().String()
+		default:
+			row[i], _ = r.Field(col)
+		}
+	}
+	return c.w.Write(row)
+}
+
+func (c *csvConverter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
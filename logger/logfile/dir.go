@@ -0,0 +1,151 @@
+// sad-go-logger/logger/logfile/dir.go
+
+package logfile
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FallbackDir is the log directory used when no other location is usable,
+// relative to the working directory.
+const FallbackDir = "./logs"
+
+// Dir is a candidate location of the log files.
+type Dir struct {
+	Path string
+
+	// Perm is the permission the directory is created with: private for
+	// the per-user locations, readable by log shippers for the system ones.
+	// It is ignored on Windows, where the directory inherits its ACL.
+	Perm os.FileMode
+}
+
+// Dirs returns the candidate locations of the log files of service, in
+// order of preference. LOG_DIR, when set, is the only one, created with the
+// octal permission LOG_DIR_MODE if set. Otherwise the platform defaults come
+// first:
+//   - Linux and other Unix systems: /var/log/<service>, then
+//     $XDG_STATE_HOME/<service> (default: ~/.local/state/<service>)
+//   - macOS: ~/Library/Logs/<service>
+//   - Windows: %ProgramData%\<service>\logs
+//
+// and FallbackDir last.
+func Dirs(service string) []Dir {
+	if dir := os.Getenv("LOG_DIR"); dir != "" {
+		perm := os.FileMode(0755)
+		if v, err := strconv.ParseUint(os.Getenv("LOG_DIR_MODE"), 8, 32); err == nil && v <= 0777 {
+			perm = os.FileMode(v)
+		}
+		return []Dir{{Path: dir, Perm: perm}}
+	}
+
+	// The service name is a single path element.
+	service = strings.NewReplacer("/", "_", `\`, "_").Replace(service)
+	if service == "" || service == "." || service == ".." {
+		service = "sad_service"
+	}
+	home, _ := os.UserHomeDir()
+
+	var dirs []Dir
+	switch runtime.GOOS {
+	case "windows":
+		if data := os.Getenv("ProgramData"); data != "" {
+			dirs = append(dirs, Dir{Path: filepath.Join(data, service, "logs"), Perm: 0755})
+		}
+	case "darwin", "ios":
+		if home != "" {
+			dirs = append(dirs, Dir{Path: filepath.Join(home, "Library", "Logs", service), Perm: 0755})
+		}
+	case "js", "wasip1", "plan9":
+	default:
+		dirs = append(dirs, Dir{Path: filepath.Join("/var/log", service), Perm: 0755})
+		if state := os.Getenv("XDG_STATE_HOME"); state != "" {
+			dirs = append(dirs, Dir{Path: filepath.Join(state, service), Perm: 0700})
+		} else if home != "" {
+			dirs = append(dirs, Dir{Path: filepath.Join(home, ".local", "state", service), Perm: 0700})
+		}
+	}
+	return append(dirs, Dir{Path: FallbackDir, Perm: 0755})
+}
+
+// FindDir returns the first of Dirs(service) holding a file named name, or
+// the last one, FallbackDir or LOG_DIR, if none does. It is meant for tools
+// reading the files of a running service.
+func FindDir(service, name string) string {
+	dirs := Dirs(service)
+	for _, dir := range dirs {
+		if _, err := os.Stat(filepath.Join(dir.Path, name)); err == nil {
+			return dir.Path
+		}
+	}
+	return dirs[len(dirs)-1].Path
+}
+
+// Layouts of the dates stamped by DatedName in the names of the files
+// rotated daily or hourly.
+const (
+	DailyLayout  = "2006-01-02"
+	HourlyLayout = "2006-01-02-15"
+)
+
+// DatedName returns the file name name stamped with date before its
+// extension: logs.txt and 2024-05-01 give logs-2024-05-01.txt.
+func DatedName(name, date string) string {
+	ext := filepath.Ext(name)
+	return strings.TrimSuffix(name, ext) + "-" + date + ext
+}
+
+// FindFile returns the path of the file named name in the first of
+// Dirs(service) holding it, or one of its dated variants, see DatedName. Of
+// the dated variants the most recently modified is returned. If no
+// directory holds any, it is the path of name in FindDir(service, name). An
+// absolute name is only looked for at its path.
+func FindFile(service, name string) string {
+	var paths []string
+	if filepath.IsAbs(name) {
+		paths = []string{name}
+	} else {
+		for _, dir := range Dirs(service) {
+			paths = append(paths, filepath.Join(dir.Path, name))
+		}
+	}
+	for _, path := range paths {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		if dated := latestDated(path); dated != "" {
+			return dated
+		}
+	}
+	if filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(FindDir(service, name), name)
+}
+
+// latestDated returns the most recently modified dated variant of the file
+// at path, or "" if there is none.
+func latestDated(path string) string {
+	ext := filepath.Ext(path)
+	prefix := filepath.Base(strings.TrimSuffix(path, ext)) + "-"
+	matches, _ := filepath.Glob(strings.TrimSuffix(path, ext) + "-*" + ext)
+	var latest string
+	var latestTime time.Time
+	for _, match := range matches {
+		date := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(match), prefix), ext)
+		if _, err := time.Parse(DailyLayout, date); err != nil {
+			if _, err := time.Parse(HourlyLayout, date); err != nil {
+				continue
+			}
+		}
+		if info, err := os.Stat(match); err == nil && (latest == "" || info.ModTime().After(latestTime)) {
+			latest, latestTime = match, info.ModTime()
+		}
+	}
+	return latest
+}
@@ -0,0 +1,274 @@
+// sad-go-logger/logger/logfile/index.go
+
+package logfile
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// IndexOptions configures an Index.
+type IndexOptions struct {
+	// Fields are the field keys indexed for equality queries, in addition
+	// to the message text, level and timestamp. Queries on other fields
+	// still work but scan every candidate record.
+	Fields []string
+}
+
+// indexedRecord locates one record in the log file.
+type indexedRecord struct {
+	offset int64
+	length int32
+	level  zapcore.Level
+	time   int64 // Unix nanoseconds, 0 if unknown
+}
+
+// Index is an in-memory inverted index over a JSON log file. It indexes the
+// message text, level, timestamp and selected fields of every record, and
+// keeps only their locations: records are read back from the file when a
+// query matches them. Call Refresh to index records appended since the
+// last call.
+type Index struct {
+	path   string
+	fields map[string]bool
+
+	mu      sync.RWMutex
+	size    int64 // bytes of the file indexed so far
+	records []indexedRecord
+	terms   map[string][]int32
+	values  map[string]map[string][]int32
+}
+
+// Query selects records from an Index. Zero fields do not constrain the
+// result.
+type Query struct {
+	// Text must match every word of the message, case-insensitively.
+	Text string
+
+	// MinLevel is the lowest matching level. Note that the zero value is
+	// zapcore.InfoLevel.
+	MinLevel zapcore.Level
+
+	// Since and Until bound the entry timestamps.
+	Since time.Time
+	Until time.Time
+
+	// Fields must all be present with the given string values.
+	Fields map[string]string
+
+	// Grep, when set, must match the message.
+	Grep *regexp.Regexp
+
+	// Limit caps the number of results; the most recent records are
+	// returned first. A limit of 0 returns every match.
+	Limit int
+}
+
+// OpenIndex indexes the log file at path.
+func OpenIndex(path string, opts IndexOptions) (*Index, error) {
+	idx := &Index{path: path, fields: map[string]bool{}}
+	for _, key := range opts.Fields {
+		idx.fields[key] = true
+	}
+	idx.reset()
+	if err := idx.Refresh(); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+func (idx *Index) reset() {
+	idx.size = 0
+	idx.records = nil
+	idx.terms = map[string][]int32{}
+	idx.values = map[string]map[string][]int32{}
+}
+
+// Refresh indexes the records appended to the file since the last call. If
+// the file shrank, e.g. because it was rotated, it is indexed from scratch.
+func (idx *Index) Refresh() error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	f, err := os.Open(idx.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() < idx.size {
+		idx.reset()
+	}
+	if _, err := f.Seek(idx.size, io.SeekStart); err != nil {
+		return err
+	}
+
+	reader := bufio.NewReaderSize(f, 64*1024)
+	offset := idx.size
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// Leave a partially written last line for the next refresh.
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if trimmed := bytes.TrimSpace(line); len(trimmed) > 0 {
+			idx.add(offset, int32(len(line)), ParseRecord(trimmed))
+		}
+		offset += int64(len(line))
+	}
+	idx.size = offset
+	return nil
+}
+
+// add indexes the record stored at offset.
+func (idx *Index) add(offset int64, length int32, r Record) {
+	id := int32(len(idx.records))
+	rec := indexedRecord{offset: offset, length: length, level: r.Level()}
+	if t, ok := r.Time(); ok {
+		rec.time = t.UnixNano()
+	}
+	idx.records = append(idx.records, rec)
+
+	seen := map[string]bool{}
+	for _, term := range tokenize(r.Message()) {
+		if !seen[term] {
+			seen[term] = true
+			idx.terms[term] = append(idx.terms[term], id)
+		}
+	}
+	for key := range idx.fields {
+		if value, ok := r.Field(key); ok {
+			if idx.values[key] == nil {
+				idx.values[key] = map[string][]int32{}
+			}
+			idx.values[key][value] = append(idx.values[key][value], id)
+		}
+	}
+}
+
+// Len returns the number of indexed records.
+func (idx *Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.records)
+}
+
+// Search returns the records matching q, most recent first.
+func (idx *Index) Search(q Query) ([]Record, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	// Intersect the postings of every indexed condition; nil means all.
+	var candidates []int32
+	all := true
+	intersect := func(postings []int32) {
+		if all {
+			candidates, all = postings, false
+			return
+		}
+		candidates = intersectPostings(candidates, postings)
+	}
+	for _, term := range tokenize(q.Text) {
+		intersect(idx.terms[term])
+	}
+	var unindexed map[string]string
+	for key, value := range q.Fields {
+		if idx.fields[key] {
+			intersect(idx.values[key][value])
+			continue
+		}
+		if unindexed == nil {
+			unindexed = map[string]string{}
+		}
+		unindexed[key] = value
+	}
+
+	n := len(idx.records)
+	if !all {
+		n = len(candidates)
+	}
+
+	f, err := os.Open(idx.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	filter := Filter{MinLevel: q.MinLevel, Grep: q.Grep, Fields: unindexed}
+	var results []Record
+	for i := n - 1; i >= 0; i-- {
+		id := int32(i)
+		if !all {
+			id = candidates[i]
+		}
+		rec := idx.records[id]
+		if rec.level < q.MinLevel {
+			continue
+		}
+		if (!q.Since.IsZero() || !q.Until.IsZero()) && rec.time == 0 {
+			continue
+		}
+		if !q.Since.IsZero() && rec.time < q.Since.UnixNano() {
+			continue
+		}
+		if !q.Until.IsZero() && rec.time > q.Until.UnixNano() {
+			continue
+		}
+
+		line := make([]byte, rec.length)
+		if _, err := f.ReadAt(line, rec.offset); err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		r := ParseRecord(bytes.TrimSpace(line))
+		if !filter.Match(r) {
+			continue
+		}
+		results = append(results, r)
+		if q.Limit > 0 && len(results) >= q.Limit {
+			break
+		}
+	}
+	return results, nil
+}
+
+// intersectPostings returns the ids present in both sorted lists.
+func intersectPostings(a, b []int32) []int32 {
+	out := make([]int32, 0, min(len(a), len(b)))
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			out = append(out, a[i])
+			i++
+			j++
+		case a[i] < b[j]:
+			i++
+		default:
+			j++
+		}
+	}
+	return out
+}
+
+// tokenize splits text into lower-case words of letters and digits.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,262 @@
+// sad-go-logger/logger/logfile/logfile.go
+
+// Package logfile reads the JSON log files written by the logger package.
+// It does not import the logger package, so tools built on it do not
+// initialize a logger of their own.
+package logfile
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Well-known keys written by the logger's JSON encoder.
+const (
+	MessageKey = "message"
+	LevelKey   = "level"
+	TimeKey    = "datetime"
+)
+
+// timeLayouts are the timestamp layouts recognised by Record.Time, in order.
+var timeLayouts = []string{
+	"2006-01-02 15:04:05.000",
+	time.RFC3339Nano,
+	time.RFC3339,
+}
+
+// Record is one decoded log entry.
+type Record map[string]interface{}
+
+// Message returns the entry message.
+func (r Record) Message() string {
+	s, _ := r[MessageKey].(string)
+	return s
+}
+
+// Level returns the entry level. Entries without a recognisable level are
+// reported at info.
+func (r Record) Level() zapcore.Level {
+	s, _ := r[LevelKey].(string)
+	var level zapcore.Level
+	if err := level.UnmarshalText([]byte(strings.ToLower(s))); err != nil {
+		return zapcore.InfoLevel
+	}
+	return level
+}
+
+// Time returns the entry timestamp and whether it could be parsed. String
+// timestamps in the logger's layouts and numeric epoch seconds,
+// milliseconds or nanoseconds, told apart by their magnitude, are
+// recognised; the default layout is interpreted in the local time zone, as
+// it is written.
+func (r Record) Time() (time.Time, bool) {
+	switch v := r[TimeKey].(type) {
+	case string:
+		for _, layout := range timeLayouts {
+			if t, err := time.ParseInLocation(layout, v, time.Local); err == nil {
+				return t, true
+			}
+		}
+	case float64:
+		switch {
+		case v < 1e11:
+			return time.Unix(0, int64(v*1e9)), true
+		case v < 1e14:
+			return time.UnixMilli(int64(v)), true
+		}
+		return time.Unix(0, int64(v)), true
+	}
+	return time.Time{}, false
+}
+
+// Field returns the string form of the value stored under key, and whether
+// it is present. Nested objects are rendered as JSON.
+func (r Record) Field(key string) (string, bool) {
+	v, ok := r[key]
+	if !ok {
+		return "", false
+	}
+	return FormatValue(v), true
+}
+
+// FormatValue renders a decoded JSON value as text.
+func FormatValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(b)
+	}
+}
+
+// ParseRecord decodes one line of a log file. Lines that are not JSON
+// objects are returned as a record holding only the raw line as message.
+func ParseRecord(line []byte) Record {
+	var r Record
+	if err := json.Unmarshal(line, &r); err != nil || r == nil {
+		return Record{MessageKey: string(line)}
+	}
+	return r
+}
+
+// Scan calls fn for every record read from r, stopping at the first error
+// returned by fn. Blank lines are skipped. Binary files, starting with
+// BinaryMagic, are read as well.
+func Scan(r io.Reader, fn func(Record) error) error {
+	br := bufio.NewReaderSize(r, 64*1024)
+	if magic, _ := br.Peek(len(BinaryMagic)); string(magic) == BinaryMagic {
+		br.Discard(len(magic))
+		return scanBinary(br, fn)
+	}
+
+	scanner := bufio.NewScanner(br)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if err := fn(ParseRecord(line)); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// ScanFile calls fn for every record of the file at path.
+func ScanFile(path string, fn func(Record) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return Scan(f, fn)
+}
+
+// Follow calls fn for every record appended to the file at path until ctx
+// is done, polling every interval. If fromStart is false, existing content
+// is skipped. A file that shrinks (e.g. after truncation or rotation) is
+// read again from the beginning.
+func Follow(ctx context.Context, path string, fromStart bool, interval time.Duration, fn func(Record) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { f.Close() }()
+
+	var offset int64
+	if !fromStart {
+		if offset, err = f.Seek(0, io.SeekEnd); err != nil {
+			return err
+		}
+	}
+
+	var pending []byte
+	buf := make([]byte, 32*1024)
+	for {
+		if info, err := os.Stat(path); err == nil && info.Size() < offset {
+			f.Close()
+			if f, err = os.Open(path); err != nil {
+				return err
+			}
+			offset, pending = 0, pending[:0]
+		}
+
+		for {
+			n, err := f.ReadAt(buf, offset)
+			offset += int64(n)
+			pending = append(pending, buf[:n]...)
+			for {
+				i := bytes.IndexByte(pending, '\n')
+				if i < 0 {
+					break
+				}
+				if line := bytes.TrimSpace(pending[:i]); len(line) > 0 {
+					if err := fn(ParseRecord(line)); err != nil {
+						return err
+					}
+				}
+				pending = pending[i+1:]
+			}
+			if err == io.EOF || n == 0 {
+				break
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// Filter selects records. The zero value matches every record at info level
+// and above; set MinLevel to zapcore.DebugLevel to include debug entries.
+type Filter struct {
+	// MinLevel is the lowest level that matches.
+	MinLevel zapcore.Level
+
+	// Since and Until bound the entry timestamps, when non-zero. Records
+	// without a parsable timestamp do not match a time bound.
+	Since time.Time
+	Until time.Time
+
+	// Grep, when set, must match the message.
+	Grep *regexp.Regexp
+
+	// Fields must all be present with the given string values.
+	Fields map[string]string
+}
+
+// Match reports whether r satisfies every condition of the filter.
+func (f Filter) Match(r Record) bool {
+	if r.Level() < f.MinLevel {
+		return false
+	}
+	if !f.Since.IsZero() || !f.Until.IsZero() {
+		t, ok := r.Time()
+		if !ok {
+			return false
+		}
+		if !f.Since.IsZero() && t.Before(f.Since) {
+			return false
+		}
+		if !f.Until.IsZero() && t.After(f.Until) {
+			return false
+		}
+	}
+	if f.Grep != nil && !f.Grep.MatchString(r.Message()) {
+		return false
+	}
+	for key, want := range f.Fields {
+		if got, ok := r.Field(key); !ok || got != want {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,127 @@
+// sad-go-logger/logger/logfile/proto.go
+
+package logfile
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/sadco-io/sad-go-logger/logger/logpb"
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Keys of the record fields moved to the resource of a logpb.LogEntry.
+const (
+	ServiceKey = "serviceName"
+	HostKey    = "hostname"
+)
+
+// BinaryMagic starts the binary log files: a sequence of logpb.LogEntry
+// messages, each preceded by its length as a varint. Scan and ScanFile read
+// them as well as JSON files.
+const BinaryMagic = "\x00SADLOG\x01"
+
+// Proto returns the record as a logpb.LogEntry. A record without a
+// parsable timestamp is stamped with the current time.
+func (r Record) Proto() (*logpb.LogEntry, error) {
+	t, ok := r.Time()
+	if !ok {
+		t = time.Now()
+	}
+	e := &logpb.LogEntry{Time: timestamppb.New(t), Resource: &logpb.Resource{}}
+	attributes := make(map[string]interface{}, len(r))
+	for key, value := range r {
+		switch key {
+		case TimeKey:
+		case LevelKey:
+			e.Level = FormatValue(value)
+		case MessageKey:
+			e.Message = FormatValue(value)
+		case ServiceKey:
+			e.Resource.Service = FormatValue(value)
+		case HostKey:
+			e.Resource.Host = FormatValue(value)
+		default:
+			attributes[key] = value
+		}
+	}
+	var err error
+	if e.Attributes, err = structpb.NewStruct(attributes); err != nil {
+		return nil, fmt.Errorf("failed to encode log entry: %v", err)
+	}
+	return e, nil
+}
+
+// RecordFromProto returns the record of e, its timestamp in the RFC 3339
+// layout.
+func RecordFromProto(e *logpb.LogEntry) Record {
+	r := Record{}
+	for key, value := range e.GetAttributes().AsMap() {
+		r[key] = value
+	}
+	if e.Time != nil {
+		r[TimeKey] = e.Time.AsTime().In(time.Local).Format(time.RFC3339Nano)
+	}
+	r[LevelKey] = e.Level
+	r[MessageKey] = e.Message
+	if res := e.GetResource(); res != nil {
+		r[ServiceKey] = res.Service
+		r[HostKey] = res.Host
+	}
+	return r
+}
+
+// AppendBinaryFile appends records to the binary log file at path,
+// creating it if needed.
+func AppendBinaryFile(path string, records []Record) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	if info.Size() == 0 {
+		w.WriteString(BinaryMagic)
+	}
+	for _, r := range records {
+		e, err := r.Proto()
+		if err != nil {
+			return err
+		}
+		if _, err := protodelim.MarshalTo(w, e); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// scanBinary calls fn for every entry of a binary file, read past its
+// BinaryMagic.
+func scanBinary(r *bufio.Reader, fn func(Record) error) error {
+	for {
+		var e logpb.LogEntry
+		if err := protodelim.UnmarshalFrom(r, &e); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("invalid binary log entry: %v", err)
+		}
+		if err := fn(RecordFromProto(&e)); err != nil {
+			return err
+		}
+	}
+}
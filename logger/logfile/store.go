@@ -0,0 +1,10 @@
+// sad-go-logger/logger/logfile/store.go
+
+package logfile
+
+// StoreOptions configures a Store.
+type StoreOptions struct {
+	// MaxSize bounds the space used by the entries, in bytes; the oldest
+	// entries are pruned beyond it. Zero disables pruning.
+	MaxSize int64
+}
@@ -0,0 +1,34 @@
+// sad-go-logger/logger/logfile/store_disabled.go
+
+//go:build !logsqlite
+
+package logfile
+
+import "errors"
+
+// errNoStore is returned by OpenStore in builds without SQLite support.
+var errNoStore = errors.New("SQLite store not available: build with -tags logsqlite")
+
+// Store is a SQLite database of log entries. This build does not include
+// SQLite; build with -tags logsqlite to enable it.
+type Store struct{}
+
+// OpenStore returns an error: this build does not include SQLite.
+func OpenStore(path string, opts StoreOptions) (*Store, error) {
+	return nil, errNoStore
+}
+
+// Insert returns an error: this build does not include SQLite.
+func (s *Store) Insert(records []Record) error {
+	return errNoStore
+}
+
+// Search returns an error: this build does not include SQLite.
+func (s *Store) Search(q Query) ([]Record, error) {
+	return nil, errNoStore
+}
+
+// Close does nothing.
+func (s *Store) Close() error {
+	return nil
+}
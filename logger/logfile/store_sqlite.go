@@ -0,0 +1,161 @@
+// sad-go-logger/logger/logfile/store_sqlite.go
+
+//go:build logsqlite
+
+package logfile
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	_ "modernc.org/sqlite"
+)
+
+// storeSchema creates the entries table and its full-text index, kept in
+// sync by triggers.
+const storeSchema = `
+CREATE TABLE IF NOT EXISTS entries (
+	id INTEGER PRIMARY KEY,
+	time INTEGER NOT NULL,
+	level INTEGER NOT NULL,
+	message TEXT NOT NULL,
+	record TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS entries_time ON entries (time);
+CREATE VIRTUAL TABLE IF NOT EXISTS entries_fts USING fts5(message, content='entries', content_rowid='id');
+CREATE TRIGGER IF NOT EXISTS entries_insert AFTER INSERT ON entries BEGIN
+	INSERT INTO entries_fts (rowid, message) VALUES (new.id, new.message);
+END;
+CREATE TRIGGER IF NOT EXISTS entries_delete AFTER DELETE ON entries BEGIN
+	INSERT INTO entries_fts (entries_fts, rowid, message) VALUES ('delete', old.id, old.message);
+END;
+`
+
+// Store is a SQLite database of log entries, in WAL mode so that searches
+// do not block writes. It indexes the message text, level and timestamp of
+// every entry; other fields are matched while reading the results back.
+type Store struct {
+	db  *sql.DB
+	max int64
+}
+
+// OpenStore opens the store at path, creating it if it does not exist.
+func OpenStore(path string, opts StoreOptions) (*Store, error) {
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)&_pragma=journal_size_limit(4194304)")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(storeSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create log store: %v", err)
+	}
+	return &Store{db: db, max: opts.MaxSize}, nil
+}
+
+// Insert adds records in a single transaction, then prunes the oldest
+// entries if the store grew beyond its maximum size.
+func (s *Store) Insert(records []Record) error {
+	txn, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer txn.Rollback()
+
+	stmt, err := txn.Prepare("INSERT INTO entries (time, level, message, record) VALUES (?, ?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, r := range records {
+		data, err := json.Marshal(r)
+		if err != nil {
+			return fmt.Errorf("failed to marshal log entry: %v", err)
+		}
+		var t int64
+		if ts, ok := r.Time(); ok {
+			t = ts.UnixNano()
+		}
+		if _, err := stmt.Exec(t, int(r.Level()), r.Message(), string(data)); err != nil {
+			return err
+		}
+	}
+	if err := txn.Commit(); err != nil {
+		return err
+	}
+	return s.prune()
+}
+
+// prune deletes the oldest entries until the store uses no more than 90%
+// of its maximum size. Freed pages are reused by later inserts, so the
+// database file stays about the maximum size.
+func (s *Store) prune() error {
+	if s.max <= 0 {
+		return nil
+	}
+	var used int64
+	err := s.db.QueryRow("SELECT (page_count - freelist_count) * page_size FROM pragma_page_count(), pragma_freelist_count(), pragma_page_size()").Scan(&used)
+	if err != nil || used <= s.max {
+		return err
+	}
+
+	var count int64
+	if err := s.db.QueryRow("SELECT count(*) FROM entries").Scan(&count); err != nil {
+		return err
+	}
+	n := count*(used-s.max*9/10)/used + 1
+	_, err = s.db.Exec("DELETE FROM entries WHERE id IN (SELECT id FROM entries ORDER BY id LIMIT ?)", n)
+	return err
+}
+
+// Search returns the records matching q, most recent first.
+func (s *Store) Search(q Query) ([]Record, error) {
+	query := "SELECT record FROM entries WHERE level >= ?"
+	args := []interface{}{int(q.MinLevel)}
+	if terms := tokenize(q.Text); len(terms) > 0 {
+		for i, term := range terms {
+			terms[i] = `"` + term + `"`
+		}
+		query += " AND id IN (SELECT rowid FROM entries_fts WHERE entries_fts MATCH ?)"
+		args = append(args, strings.Join(terms, " "))
+	}
+	if !q.Since.IsZero() {
+		query += " AND time >= ?"
+		args = append(args, q.Since.UnixNano())
+	}
+	if !q.Until.IsZero() {
+		query += " AND time > 0 AND time <= ?"
+		args = append(args, q.Until.UnixNano())
+	}
+	query += " ORDER BY id DESC"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	filter := Filter{MinLevel: q.MinLevel, Grep: q.Grep, Fields: q.Fields}
+	var results []Record
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		r := ParseRecord([]byte(data))
+		if !filter.Match(r) {
+			continue
+		}
+		results = append(results, r)
+		if q.Limit > 0 && len(results) >= q.Limit {
+			break
+		}
+	}
+	return results, rows.Err()
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
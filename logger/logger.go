@@ -1,139 +1,161 @@
-// sad-go-logger/logger/logger.go
-
-package logger
-
-import (
-	"fmt"
-	"os"
-	"time"
-
-	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
-)
-
-var Log *zap.Logger
-var hostname string
-var serviceName string
-var initLog map[string]interface{}
-
-func init() {
-	initLog = make(map[string]interface{})
-
-	var err error
-	hostname, err = os.Hostname()
-	if err != nil {
-		initLog["hostnameMessage"] = fmt.Sprintf("Error retrieving hostname: %v", err) + "Setting hostname to unkw"
-		hostname = "unkw"
-	}
-
-	serviceName = os.Getenv("SERVICE_NAME")
-	if serviceName == "" {
-		initLog["serviceNameMessage"] = "SERVICE_NAME is not set, using sad_service as default"
-		serviceName = "sad_service"
-	}
-
-	// Create logs directory if not exists
-	if _, err := os.Stat("./logs"); os.IsNotExist(err) {
-		if err := os.Mkdir("./logs", 0755); err != nil {
-			fmt.Printf("Warning: Unable to create log directory './logs': %v\n", err)
-		}
-	}
-
-	// Open or create log files in the logs directory
-	file, err := os.OpenFile("./logs/logs.txt", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		panic(err)
-	}
-	errorLog, err := os.OpenFile("./logs/errors.txt", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		panic(err)
-	}
-
-	logLevel := os.Getenv("LOG_LEVEL")
-	var zapLevel zapcore.Level
-	if logLevel == "" {
-		logLevel = "debug"
-	}
-	switch logLevel {
-	case "debug":
-		zapLevel = zap.DebugLevel
-	case "info":
-		zapLevel = zap.InfoLevel
-	case "warn":
-		zapLevel = zap.WarnLevel
-	case "error":
-		zapLevel = zap.ErrorLevel
-	case "fatal":
-		zapLevel = zap.FatalLevel
-	case "panic":
-		zapLevel = zap.PanicLevel
-	default:
-		zapLevel = zap.DebugLevel
-	}
-
-	// Create a custom encoder config
-	encoderConfig := zapcore.EncoderConfig{
-		MessageKey: "message",
-		LevelKey:   "level",
-		TimeKey:    "datetime",
-		EncodeTime: zapcore.TimeEncoder(func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
-			enc.AppendString(t.Format("2006-01-02 15:04:05.000"))
-		}),
-		EncodeLevel:      zapcore.CapitalLevelEncoder,
-		EncodeCaller:     zapcore.ShortCallerEncoder,
-		ConsoleSeparator: ". ", // Use dot and space as the separator
-	}
-
-	// Create a custom core that writes to both stdout and file
-	consoleEncoder := zapcore.NewConsoleEncoder(encoderConfig)
-	fileEncoder := zapcore.NewJSONEncoder(encoderConfig)
-
-	stdoutSink := zapcore.AddSync(os.Stdout)
-	fileSink := zapcore.AddSync(file)
-	errorFileSink := zapcore.AddSync(errorLog)
-
-	// Create a core for stdout and file
-	core := zapcore.NewTee(
-		zapcore.NewCore(consoleEncoder, stdoutSink, zapLevel),
-		zapcore.NewCore(fileEncoder, fileSink, zapLevel),
-		zapcore.NewCore(fileEncoder, errorFileSink, zap.ErrorLevel),
-	)
-
-	// Check if remote sync is enabled for ELK
-	if os.Getenv("ENABLE_REMOTE_SYNC_ELK") == "true" {
-		remoteSyncWriter := NewRemoteSyncWriter()
-		if remoteSyncWriter != nil {
-			remoteSink := zapcore.AddSync(remoteSyncWriter)
-			core = zapcore.NewTee(core, zapcore.NewCore(fileEncoder, remoteSink, zapLevel))
-		}
-	}
-
-	// Check if remote sync is enabled for New Relic
-	if os.Getenv("ENABLE_REMOTE_SYNC_NEWRELIC") == "true" {
-		newRelicWriter := NewNewRelicRemoteSyncWriter()
-		if newRelicWriter != nil {
-			newRelicSink := zapcore.AddSync(newRelicWriter)
-			core = zapcore.NewTee(core, zapcore.NewCore(fileEncoder, newRelicSink, zapLevel))
-		}
-	}
-
-	// Create the logger
-	Log = zap.New(core, zap.AddCaller(), zap.Fields(
-		zap.String("hostname", hostname),
-		zap.String("serviceName", serviceName),
-	))
-
-	Log.Debug("Logger initialized")
-
-	if len(initLog) > 0 {
-		for key, value := range initLog {
-			Log.Sugar().Infof("%s, %v", key, value)
-		}
-	}
-	Log.Info("Logger set to " + logLevel + " level")
-}
-
-// WithFields adds structured context to the logger.
-func WithFields(fields ...zap.Field) *zap.Logger {
-	return Log.With(fields...)
-}
+// sad-go-logger/logger/logger.go
+
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Log is the global logger. Until Init is called, it writes the info and
+// higher entries to the console only, at consoleLevel.
+var Log = zap.New(zapcore.NewCore(zapcore.NewConsoleEncoder(newEncoderConfig()), zapcore.Lock(os.Stdout), consoleLevel), zap.AddCaller())
+var consoleLevel = zap.NewAtomicLevelAt(zap.InfoLevel)
+var hostname string
+var serviceName string
+var initLog map[string]interface{}
+
+// global is the handle of the global Log once Init is called, and
+// globalConfig the configuration it was built from.
+var global *builtLogger
+var globalConfig Config
+
+// initMu serializes Init and guards global.
+var initMu sync.Mutex
+
+// current returns global, nil until Init is called.
+func current() *builtLogger {
+	initMu.Lock()
+	defer initMu.Unlock()
+	return global
+}
+
+func init() {
+	initLog = make(map[string]interface{})
+
+	var err error
+	hostname, err = os.Hostname()
+	if err != nil {
+		initLog["hostnameMessage"] = fmt.Sprintf("Error retrieving hostname: %v", err) + "Setting hostname to unkw"
+		hostname = "unkw"
+	}
+}
+
+// Init builds the global Log from cfg, usually ConfigFromEnv with some
+// fields overridden, and applies the LOG_SIGNAL_CONTROL,
+// LOG_RELOAD_ON_SIGHUP, LOG_FLIGHT_RECORDER_SIZE, LOG_DROP_FILTERS,
+// LOG_BAGGAGE_FIELDS, LOG_REMOTE_CONFIG, LOG_REDACT_FIELDS, LOG_SCRUBBERS
+// and LOG_SCRUB_PATTERNS environment variables. It creates the log files
+// and connects the remote sinks: importing the package does neither,
+// unless the autoinit package is imported as well. Init fails if the
+// global logger is already initialized.
+func Init(cfg Config) error {
+	initMu.Lock()
+	defer initMu.Unlock()
+	if global != nil {
+		return errors.New("logger already initialized")
+	}
+
+	b, err := cfg.build()
+	if err != nil {
+		return err
+	}
+	global, Log, globalConfig = b, b.logger, cfg
+	serviceName = cfg.ServiceName
+	setLevelOverrides(cfg.LevelOverrides)
+
+	Log.Debug("Logger initialized")
+
+	if len(initLog) > 0 {
+		for key, value := range initLog {
+			Log.Sugar().Infof("%s, %v", key, value)
+		}
+	}
+	Log.Info("Logger set to " + cfg.Level + " level")
+
+	if os.Getenv("LOG_SIGNAL_CONTROL") == "true" {
+		duration := defaultSignalDebugDuration
+		if s := os.Getenv("LOG_SIGNAL_DEBUG_DURATION"); s != "" {
+			if d, err := time.ParseDuration(s); err == nil {
+				duration = d
+			} else {
+				Log.Warn("Invalid LOG_SIGNAL_DEBUG_DURATION, using default", zap.String("value", s), zap.Duration("default", duration))
+			}
+		}
+		EnableSignalControl(duration)
+	}
+
+	if os.Getenv("LOG_RELOAD_ON_SIGHUP") == "true" {
+		ReloadOnSignal()
+	}
+
+	if s := os.Getenv("LOG_FLIGHT_RECORDER_SIZE"); s != "" {
+		if n, err := strconv.Atoi(s); err == nil && n >= 0 {
+			flight.resize(n)
+		} else {
+			Log.Warn("Invalid LOG_FLIGHT_RECORDER_SIZE, using default", zap.String("value", s), zap.Int("default", defaultFlightRecorderSize))
+		}
+	}
+
+	if s := os.Getenv("LOG_DROP_FILTERS"); s != "" {
+		if err := dropFiltersFromEnv(s); err != nil {
+			Log.Warn("Drop filters disabled", zap.Error(err))
+		}
+	}
+
+	if s := os.Getenv("LOG_REDACT_FIELDS"); s != "" {
+		redactedFieldsFromEnv(s)
+	}
+
+	if names, patterns := os.Getenv("LOG_SCRUBBERS"), os.Getenv("LOG_SCRUB_PATTERNS"); names != "" || patterns != "" {
+		if err := scrubbersFromEnv(names, patterns); err != nil {
+			Log.Warn("Scrubbers disabled", zap.Error(err))
+		}
+	}
+
+	if s := os.Getenv("LOG_BAGGAGE_FIELDS"); s != "" {
+		SetBaggageFields(strings.Split(s, ",")...)
+	}
+
+	if src, err := remoteConfigSourceFromEnv(); err != nil {
+		Log.Warn("Remote configuration disabled", zap.Error(err))
+	} else if src != nil {
+		WatchRemoteConfig(context.Background(), src)
+	}
+	return nil
+}
+
+// MustInit is like Init but panics if the logger cannot be initialized.
+func MustInit(cfg Config) {
+	if err := Init(cfg); err != nil {
+		panic(err)
+	}
+}
+
+// newEncoderConfig returns the encoder configuration shared by all sinks.
+func newEncoderConfig() zapcore.EncoderConfig {
+	return zapcore.EncoderConfig{
+		MessageKey:       "message",
+		LevelKey:         "level",
+		TimeKey:          "datetime",
+		NameKey:          "logger",
+		EncodeTime:       layoutTimeEncoder(defaultTimeLayout),
+		EncodeLevel:      zapcore.CapitalLevelEncoder,
+		EncodeCaller:     zapcore.ShortCallerEncoder,
+		ConsoleSeparator: ". ", // Use dot and space as the separator
+	}
+}
+
+// WithFields adds structured context to the logger.
+func WithFields(fields ...zap.Field) *zap.Logger {
+	return Log.With(fields...)
+}
@@ -0,0 +1,116 @@
+// sad-go-logger/logger/loggerecho/loggerecho.go
+
+// Package loggerecho logs the requests and errors of Echo servers through
+// the shared logger, with the entries and field names of
+// logger.HTTPMiddleware so that dashboards work across frameworks:
+//
+//	e := echo.New()
+//	e.HTTPErrorHandler = loggerecho.ErrorHandler(e.DefaultHTTPErrorHandler)
+//	e.Use(loggerecho.Middleware(logger.HTTPLogOptions{
+//		SkipPaths: []string{"/healthz"},
+//	}), loggerecho.Recover())
+package loggerecho
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/sadco-io/sad-go-logger/logger"
+	"go.uber.org/zap"
+)
+
+// Middleware logs every request as an "HTTP request" entry, see
+// logger.HTTPLogger. It gives the request a logger of its own in the
+// request context, correlated with the traceparent header, which handlers
+// retrieve with logger.FromContext(c.Request().Context()) and extend with
+// logger.Append. The error returned by the handler, if any, is handed to
+// the error handler of Echo first, so that the status logged is the one
+// responded.
+func Middleware(opts logger.HTTPLogOptions) echo.MiddlewareFunc {
+	h := logger.NewHTTPLogger(opts)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			r := c.Request()
+			ctx := logger.ContextWithTraceparent(r.Context(), r.Header.Get(logger.TraceparentHeader))
+			c.SetRequest(r.WithContext(logger.ScopeContext(ctx)))
+
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+
+			r, res := c.Request(), c.Response()
+			h.Log(logger.FromContext(r.Context()), logger.HTTPRequest{
+				Method:    r.Method,
+				Path:      r.URL.Path,
+				Status:    res.Status,
+				Bytes:     res.Size,
+				Latency:   time.Since(start),
+				RemoteIP:  logger.RemoteIP(r, opts.TrustProxy),
+				UserAgent: r.UserAgent(),
+			})
+			return nil
+		}
+	}
+}
+
+// Recover recovers the panics of the handlers, like the Recover
+// middleware of Echo, but logs them as "Panic" entries with their stack
+// trace through the request logger. The error handler then responds with a
+// 500 status.
+func Recover() echo.MiddlewareFunc {
+	return middleware.RecoverWithConfig(middleware.RecoverConfig{
+		DisableStackAll: true,
+		LogErrorFunc: func(c echo.Context, err error, stack []byte) error {
+			r := c.Request()
+			logger.FromContext(r.Context()).Error("Panic",
+				zap.String("method", r.Method),
+				zap.String("path", r.URL.Path),
+				zap.String("panic", err.Error()),
+				zap.String("stacktrace", string(stack)),
+			)
+			return &panicError{err: err}
+		},
+	})
+}
+
+// panicError is a panic recovered and logged by Recover.
+type panicError struct {
+	err error
+}
+
+func (e *panicError) Error() string {
+	return e.err.Error()
+}
+
+func (e *panicError) Unwrap() error {
+	return e.err
+}
+
+// ErrorHandler returns an Echo error handler logging the errors of the
+// handlers with a 5xx status, echo.HTTPError codes included, as "Request
+// failed" entries with logger.ErrorDetails, through the request logger,
+// before responding with next, usually e.DefaultHTTPErrorHandler. Client
+// errors are left to the request entries, and panics to Recover.
+func ErrorHandler(next echo.HTTPErrorHandler) echo.HTTPErrorHandler {
+	return func(err error, c echo.Context) {
+		status := http.StatusInternalServerError
+		var httpErr *echo.HTTPError
+		var panicErr *panicError
+		if errors.As(err, &httpErr) {
+			status = httpErr.Code
+		}
+		if status >= http.StatusInternalServerError && !errors.As(err, &panicErr) {
+			r := c.Request()
+			logger.FromContext(r.Context()).Error("Request failed",
+				zap.String("method", r.Method),
+				zap.String("path", r.URL.Path),
+				logger.ErrorDetails(err),
+			)
+		}
+		next(err, c)
+	}
+}
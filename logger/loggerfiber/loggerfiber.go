@@ -0,0 +1,131 @@
+// sad-go-logger/logger/loggerfiber/loggerfiber.go
+
+// Package loggerfiber logs the requests and panics of Fiber servers through
+// the shared logger, since the fasthttp context of Fiber does not work with
+// the net/http middlewares. The entries and field names are those of
+// logger.HTTPMiddleware, so that dashboards work across frameworks:
+//
+//	app := fiber.New()
+//	app.Use(loggerfiber.Middleware(loggerfiber.Options{
+//		HTTPLogOptions: logger.HTTPLogOptions{SkipPaths: []string{"/healthz"}},
+//	}), loggerfiber.Recover())
+//
+// Handlers retrieve the request logger with
+// logger.FromContext(c.UserContext()).
+package loggerfiber
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sadco-io/sad-go-logger/logger"
+	"go.uber.org/zap"
+)
+
+// Options configures Middleware.
+type Options struct {
+	logger.HTTPLogOptions
+
+	// Body, when set, logs a debug "HTTP exchange" entry for every request
+	// with its headers and bodies, captured and redacted as it says, like
+	// logger.BodyLogMiddleware.
+	Body *logger.BodyLogOptions
+}
+
+// Middleware gives every request an id, from its X-Request-ID header or
+// generated, as logger.RequestIDMiddleware does, and a logger of its own in
+// the user context, with the request_id field and correlated with the
+// traceparent header. It logs every request as an "HTTP request" entry, see
+// logger.HTTPLogger. The error returned by the handler, if any, is handed
+// to the error handler of the app first, so that the status logged is the
+// one responded.
+func Middleware(opts Options) fiber.Handler {
+	h := logger.NewHTTPLogger(opts.HTTPLogOptions)
+	var body *logger.BodyRedactor
+	if opts.Body != nil {
+		body = logger.NewBodyRedactor(*opts.Body)
+	}
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		id := logger.RequestID(strings.Clone(c.Get(logger.RequestIDHeader)))
+		c.Set(logger.RequestIDHeader, id)
+		ctx := logger.ContextWithTraceparent(c.UserContext(), c.Get(logger.TraceparentHeader))
+		c.SetUserContext(logger.ContextWithRequestID(ctx, id))
+
+		if err := c.Next(); err != nil {
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				c.SendStatus(http.StatusInternalServerError)
+			}
+		}
+
+		l := logger.FromContext(c.UserContext())
+		method, path := strings.Clone(c.Method()), strings.Clone(c.Path())
+		if body != nil && l.Core().Enabled(zap.DebugLevel) {
+			fields := []zap.Field{zap.String("method", method), zap.String("uri", strings.Clone(c.OriginalURL()))}
+			fields = append(fields, body.Fields("request", header(c.GetReqHeaders()), c.Body())...)
+			fields = append(fields, zap.Int("status", c.Response().StatusCode()))
+			fields = append(fields, body.Fields("response", header(c.GetRespHeaders()), c.Response().Body())...)
+			l.Debug("HTTP exchange", append(fields, zap.Duration("duration", time.Since(start)))...)
+		}
+		h.Log(l, logger.HTTPRequest{
+			Method:    method,
+			Path:      path,
+			Status:    c.Response().StatusCode(),
+			Bytes:     int64(len(c.Response().Body())),
+			Latency:   time.Since(start),
+			RemoteIP:  remoteIP(c, opts.TrustProxy),
+			UserAgent: strings.Clone(c.Get(fiber.HeaderUserAgent)),
+		})
+		return nil
+	}
+}
+
+// Recover recovers the panics of the handlers, logs them as "Panic"
+// entries with their stack trace, through the request logger, and returns
+// fiber.ErrInternalServerError to the error handler. It is used after
+// Middleware.
+func Recover() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				logger.FromContext(c.UserContext()).Error("Panic",
+					zap.String("method", strings.Clone(c.Method())),
+					zap.String("path", strings.Clone(c.Path())),
+					zap.String("panic", fmt.Sprint(p)),
+					zap.String("stacktrace", string(debug.Stack())),
+				)
+				err = fiber.ErrInternalServerError
+			}
+		}()
+		return c.Next()
+	}
+}
+
+// remoteIP returns the IP address of the client, see logger.RemoteIP.
+func remoteIP(c *fiber.Ctx, trustProxy bool) string {
+	if trustProxy {
+		if ips := c.IPs(); len(ips) > 0 && ips[0] != "" {
+			return strings.Clone(ips[0])
+		}
+		if v := strings.TrimSpace(c.Get("X-Real-IP")); v != "" {
+			return strings.Clone(v)
+		}
+	}
+	return c.Context().RemoteIP().String()
+}
+
+// header returns the headers of fasthttp, whose memory is reused once the
+// request is served, as an http.Header of their own.
+func header(h map[string][]string) http.Header {
+	copied := make(http.Header, len(h))
+	for key, values := range h {
+		for _, v := range values {
+			copied[strings.Clone(key)] = append(copied[key], strings.Clone(v))
+		}
+	}
+	return copied
+}
@@ -0,0 +1,87 @@
+// sad-go-logger/logger/loggergin/loggergin.go
+
+// Package loggergin logs the requests and panics of Gin servers through the
+// shared logger, with the entries and field names of logger.HTTPMiddleware
+// so that dashboards work across frameworks:
+//
+//	r := gin.New()
+//	r.Use(loggergin.Middleware(logger.HTTPLogOptions{
+//		SkipPaths: []string{"/healthz"},
+//	}), loggergin.Recovery())
+//
+// Used after Middleware, Recovery logs with the request logger, and the
+// request recovered is logged with its 500 status.
+package loggergin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sadco-io/sad-go-logger/logger"
+	"go.uber.org/zap"
+)
+
+// Middleware logs every request as an "HTTP request" entry, see
+// logger.HTTPLogger, with the errors added to the context by the handlers
+// as the errors field. It gives the request a logger of its own in the
+// request context, correlated with the traceparent header, which handlers
+// retrieve with logger.FromContext(c.Request.Context()) and extend with
+// logger.Append.
+func Middleware(opts logger.HTTPLogOptions) gin.HandlerFunc {
+	h := logger.NewHTTPLogger(opts)
+	return func(c *gin.Context) {
+		start := time.Now()
+		ctx := logger.ContextWithTraceparent(c.Request.Context(), c.GetHeader(logger.TraceparentHeader))
+		c.Request = c.Request.WithContext(logger.ScopeContext(ctx))
+
+		c.Next()
+
+		l := logger.FromContext(c.Request.Context())
+		if len(c.Errors) > 0 {
+			l = l.With(zap.Strings("errors", c.Errors.Errors()))
+		}
+		h.Log(l, logger.HTTPRequest{
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.Path,
+			Status:    c.Writer.Status(),
+			Bytes:     int64(max(c.Writer.Size(), 0)),
+			Latency:   time.Since(start),
+			RemoteIP:  logger.RemoteIP(c.Request, opts.TrustProxy),
+			UserAgent: c.Request.UserAgent(),
+		})
+	}
+}
+
+// Recovery recovers the panics of the handlers, logs them as "Panic"
+// entries with their stack trace, through the request logger, and responds
+// with a 500 status. A panic caused by a client gone away, a broken pipe
+// or a connection reset, is logged as a warning without stack trace, and
+// nothing is written to the connection.
+func Recovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			l := logger.FromContext(c.Request.Context()).With(
+				zap.String("method", c.Request.Method),
+				zap.String("path", c.Request.URL.Path),
+			)
+			if err, ok := p.(error); ok && (errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET)) {
+				l.Warn("Connection closed by the client", zap.Error(err))
+				c.Error(err)
+				c.Abort()
+				return
+			}
+			l.Error("Panic", zap.String("panic", fmt.Sprint(p)), zap.String("stacktrace", string(debug.Stack())))
+			c.AbortWithStatus(http.StatusInternalServerError)
+		}()
+		c.Next()
+	}
+}
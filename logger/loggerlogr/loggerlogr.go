@@ -0,0 +1,163 @@
+// sad-go-logger/logger/loggerlogr/loggerlogr.go
+
+// Package loggerlogr provides a logr.Logger writing to the shared logger,
+// for Kubernetes controllers and the libraries expecting one, such as
+// controller-runtime and client-go:
+//
+//	ctrl.SetLogger(loggerlogr.New())
+//	klog.SetLogger(loggerlogr.New())
+//
+// Verbosity 0 is logged at info level, and the levels of V(1) and above at
+// debug level, with their verbosity in the v field. The names of WithName
+// are the components of logger.Named, so that LOG_LEVEL_<NAME> and
+// logger.SetNamedLevel set the level of a controller, e.g.
+// LOG_LEVEL_CONTROLLER_RUNTIME_MANAGER for "controller-runtime.manager".
+package loggerlogr
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/go-logr/logr"
+	"github.com/sadco-io/sad-go-logger/logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// New returns a logr.Logger writing to logger.Log, or to the component of
+// its name once named.
+func New() logr.Logger {
+	return logr.New(&sink{depth: 1, cache: new(atomic.Pointer[sinkCache])})
+}
+
+// sink is the logr.LogSink of New.
+type sink struct {
+	// name is the dotted name of WithName, and base its component, or nil
+	// for logger.Log.
+	name string
+	base *zap.Logger
+
+	fields []zap.Field
+	depth  int
+
+	// cache is the base logger with the fields and call depth of the sink,
+	// built again once Init, or a test, replaces logger.Log.
+	cache *atomic.Pointer[sinkCache]
+}
+
+// sinkCache is the logger of a sink for a base logger.
+type sinkCache struct {
+	base *zap.Logger
+	with *zap.Logger
+}
+
+var (
+	_ logr.LogSink          = (*sink)(nil)
+	_ logr.CallDepthLogSink = (*sink)(nil)
+)
+
+// logger returns the logger of s.
+func (s *sink) logger() *zap.Logger {
+	base := s.base
+	if base == nil {
+		base = logger.Log
+	}
+	if c := s.cache.Load(); c != nil && c.base == base {
+		return c.with
+	}
+	with := base.WithOptions(zap.AddCallerSkip(s.depth)).With(s.fields...)
+	s.cache.Store(&sinkCache{base: base, with: with})
+	return with
+}
+
+// with returns a copy of s, with a cache of its own.
+func (s *sink) with(change func(*sink)) *sink {
+	c := *s
+	c.cache = new(atomic.Pointer[sinkCache])
+	change(&c)
+	return &c
+}
+
+func (s *sink) Init(info logr.RuntimeInfo) {
+	s.depth += info.CallDepth
+}
+
+func (s *sink) Enabled(level int) bool {
+	return s.logger().Core().Enabled(zapLevel(level))
+}
+
+func (s *sink) Info(level int, msg string, keysAndValues ...interface{}) {
+	ce := s.logger().Check(zapLevel(level), msg)
+	if ce == nil {
+		return
+	}
+	fields := make([]zap.Field, 0, len(keysAndValues)/2+1)
+	if level > 0 {
+		fields = append(fields, zap.Int("v", level))
+	}
+	ce.Write(appendFields(fields, keysAndValues)...)
+}
+
+func (s *sink) Error(err error, msg string, keysAndValues ...interface{}) {
+	ce := s.logger().Check(zapcore.ErrorLevel, msg)
+	if ce == nil {
+		return
+	}
+	fields := make([]zap.Field, 0, len(keysAndValues)/2+1)
+	ce.Write(appendFields(append(fields, zap.Error(err)), keysAndValues)...)
+}
+
+func (s *sink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	return s.with(func(c *sink) {
+		c.fields = appendFields(append([]zap.Field(nil), s.fields...), keysAndValues)
+	})
+}
+
+func (s *sink) WithName(name string) logr.LogSink {
+	if s.name != "" {
+		name = s.name + "." + name
+	}
+	return s.with(func(c *sink) {
+		c.name, c.base = name, logger.Named(name).Logger
+	})
+}
+
+func (s *sink) WithCallDepth(depth int) logr.LogSink {
+	return s.with(func(c *sink) {
+		c.depth += depth
+	})
+}
+
+// zapLevel returns the zap level of the logr verbosity level.
+func zapLevel(level int) zapcore.Level {
+	if level > 0 {
+		return zapcore.DebugLevel
+	}
+	return zapcore.InfoLevel
+}
+
+// appendFields appends the alternating keys and values of keysAndValues,
+// or zap fields, to fields. Keys other than strings are formatted, and a
+// key without a value gets "<no-value>".
+func appendFields(fields []zap.Field, keysAndValues []interface{}) []zap.Field {
+	for i := 0; i < len(keysAndValues); i++ {
+		if field, ok := keysAndValues[i].(zap.Field); ok {
+			fields = append(fields, field)
+			continue
+		}
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprint(keysAndValues[i])
+		}
+		if i+1 == len(keysAndValues) {
+			return append(fields, zap.String(key, "<no-value>"))
+		}
+		i++
+		value := keysAndValues[i]
+		if m, ok := value.(logr.Marshaler); ok {
+			value = m.MarshalLog()
+		}
+		fields = append(fields, zap.Any(key, value))
+	}
+	return fields
+}
@@ -0,0 +1,112 @@
+// sad-go-logger/logger/loggerlogrus/loggerlogrus.go
+
+// Package loggerlogrus forwards the entries of the dependencies still
+// logging through logrus to the shared logger, so that they end up in the
+// same files and remote sinks:
+//
+//	loggerlogrus.Install(logrus.StandardLogger())
+//
+// The entries keep their time, level, caller and fields, and carry the
+// source field set to "logrus". Those logged with a context, through
+// logrus.WithContext, are written through logger.FromContext, with the
+// request id and trace fields of the request. The level of the logrus
+// logger still filters the entries before they are forwarded.
+package loggerlogrus
+
+import (
+	"context"
+	"io"
+	"sort"
+
+	"github.com/sadco-io/sad-go-logger/logger"
+	"github.com/sirupsen/logrus"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Source is the value of the source field of the entries forwarded.
+const Source = "logrus"
+
+// Hook is a logrus.Hook writing the entries fired to logger.Log.
+type Hook struct{}
+
+var _ logrus.Hook = (*Hook)(nil)
+
+// NewHook returns a Hook, for logrus loggers keeping their own output.
+func NewHook() *Hook {
+	return &Hook{}
+}
+
+// Install adds a Hook to l and discards its own output, the entries being
+// written by the sinks of logger.Log instead.
+func Install(l *logrus.Logger) {
+	l.AddHook(NewHook())
+	l.SetOutput(io.Discard)
+}
+
+// Levels returns every level, the level of the logrus logger and then the
+// one of logger.Log filtering the entries.
+func (h *Hook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire writes e to the core of logger.Log, or of the logger of its context.
+// The fatal and panic entries are written at their level, without exiting
+// or panicking, which logrus does itself.
+func (h *Hook) Fire(e *logrus.Entry) error {
+	ctx := e.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ent := zapcore.Entry{Level: zapLevel(e.Level), Time: e.Time, Message: e.Message}
+	if e.Caller != nil {
+		ent.Caller = zapcore.NewEntryCaller(e.Caller.PC, e.Caller.File, e.Caller.Line, true)
+		ent.Caller.Function = e.Caller.Function
+	}
+	ce := logger.FromContext(ctx).Core().Check(ent, nil)
+	if ce == nil {
+		return nil
+	}
+	ce.Write(fields(e.Data)...)
+	return nil
+}
+
+// zapLevel returns the zap level of the logrus level l, trace entries
+// being logged at debug level.
+func zapLevel(l logrus.Level) zapcore.Level {
+	switch l {
+	case logrus.PanicLevel:
+		return zapcore.PanicLevel
+	case logrus.FatalLevel:
+		return zapcore.FatalLevel
+	case logrus.ErrorLevel:
+		return zapcore.ErrorLevel
+	case logrus.WarnLevel:
+		return zapcore.WarnLevel
+	case logrus.InfoLevel:
+		return zapcore.InfoLevel
+	default:
+		return zapcore.DebugLevel
+	}
+}
+
+// fields returns the fields of an entry, sorted by key, after the source
+// field. Errors are logged as zap.Error logs them.
+func fields(data logrus.Fields) []zap.Field {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fields := make([]zap.Field, 0, len(data)+1)
+	fields = append(fields, zap.String("source", Source))
+	for _, key := range keys {
+		if err, ok := data[key].(error); ok {
+			fields = append(fields, zap.NamedError(key, err))
+			continue
+		}
+		fields = append(fields, zap.Any(key, data[key]))
+	}
+	return fields
+}
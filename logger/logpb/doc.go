@@ -0,0 +1,8 @@
+// sad-go-logger/logger/logpb/doc.go
+
+// Package logpb holds the canonical protobuf form of a log entry, shared by
+// the gRPC sink and the binary spill files. logfile converts records to and
+// from it.
+package logpb
+
+//go:generate protoc -I.. --go_out=.. --go_opt=paths=source_relative logpb/log_entry.proto
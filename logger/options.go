@@ -0,0 +1,233 @@
+// sad-go-logger/logger/options.go
+
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Option configures a logger built by New.
+type Option func(*newOptions)
+
+type newOptions struct {
+	level       zapcore.LevelEnabler
+	serviceName string
+	sinks       []zapcore.WriteSyncer
+	files       []fileOption
+	noFiles     bool
+	fileMode    os.FileMode
+	dirMode     os.FileMode
+	encoder     zapcore.Encoder
+	encoderSet  bool
+
+	// caller is nil unless set by WithCaller.
+	caller     *bool
+	callerSkip int
+	stacktrace zapcore.LevelEnabler
+	zapOpts    []zap.Option
+}
+
+type fileOption struct {
+	path     string
+	rotation Rotation
+}
+
+// WithLevel sets the minimum level of the entries written, e.g.
+// zap.InfoLevel, or a zap.AtomicLevel to change it at runtime (default:
+// debug).
+func WithLevel(level zapcore.LevelEnabler) Option {
+	return func(o *newOptions) {
+		o.level = level
+	}
+}
+
+// WithServiceName sets the serviceName field of every entry (default:
+// "sad_service").
+func WithServiceName(name string) Option {
+	return func(o *newOptions) {
+		o.serviceName = name
+	}
+}
+
+// WithSinks sets the destinations of the entries, such as os.Stderr, a
+// file or a RemoteSyncWriter (default: stdout). Every sink receives every
+// entry; a failing sink does not keep the others from being written.
+func WithSinks(sinks ...zapcore.WriteSyncer) Option {
+	return func(o *newOptions) {
+		o.sinks = sinks
+	}
+}
+
+// WithFile adds a file sink appending to path, created if needed with its
+// parent directories and rotated as rotation says, in addition to the sinks
+// of WithSinks (call WithSinks() to write to the file alone), e.g.
+//
+//	logger.WithFile("app.log", logger.Rotation{MaxSize: 10 << 20, MaxBackups: 3})
+//
+// The file stays open for the life of the process, and is reopened with the
+// log files on Reload.
+func WithFile(path string, rotation Rotation) Option {
+	return func(o *newOptions) {
+		o.files = append(o.files, fileOption{path: path, rotation: rotation})
+	}
+}
+
+// WithFileSink(false) skips the files of WithFile, creating no file nor
+// directory, e.g. for read-only container filesystems, so that the same
+// options serve both deployments. Files are enabled by default.
+func WithFileSink(enabled bool) Option {
+	return func(o *newOptions) {
+		o.noFiles = !enabled
+	}
+}
+
+// WithFileMode sets the permission the files of WithFile are created with
+// (default: 0644).
+func WithFileMode(mode os.FileMode) Option {
+	return func(o *newOptions) {
+		o.fileMode = mode
+	}
+}
+
+// WithDirMode sets the permission the directories created for the files of
+// WithFile are created with (default: 0755).
+func WithDirMode(mode os.FileMode) Option {
+	return func(o *newOptions) {
+		o.dirMode = mode
+	}
+}
+
+// WithZapOptions applies opts to the logger after the other options, e.g.
+// zap.Hooks or zap.WrapCore to tee cores of your own. Multiple calls
+// accumulate.
+func WithZapOptions(opts ...zap.Option) Option {
+	return func(o *newOptions) {
+		o.zapOpts = append(o.zapOpts, opts...)
+	}
+}
+
+// WithEncoder sets the encoder of the entries (default: the console
+// rendering of the global Log). NewJSONEncoder returns the one of the log
+// files. The encoder writes the caller and stack traces if its
+// configuration has a CallerKey and a StacktraceKey.
+func WithEncoder(enc zapcore.Encoder) Option {
+	return func(o *newOptions) {
+		o.encoder, o.encoderSet = enc, true
+	}
+}
+
+// WithCaller(true) writes the file and line of the logging call, as the
+// caller field of the default encoder, and WithCaller(false) skips looking
+// it up (default: looked up for the encoders of WithEncoder only).
+func WithCaller(enabled bool) Option {
+	return func(o *newOptions) {
+		o.caller = &enabled
+	}
+}
+
+// WithCallerSkip skips skip more stack frames to find the caller, for
+// helpers wrapping the logger to report their own callers (default: 0).
+func WithCallerSkip(skip int) Option {
+	return func(o *newOptions) {
+		o.callerSkip = skip
+	}
+}
+
+// WithStacktrace attaches a stack trace to the entries enabled by level,
+// e.g. zap.ErrorLevel, as the stacktrace field of the default encoder
+// (default: none).
+func WithStacktrace(level zapcore.LevelEnabler) Option {
+	return func(o *newOptions) {
+		o.stacktrace = level
+	}
+}
+
+// NewJSONEncoder returns the encoder of the log files, for WithEncoder.
+func NewJSONEncoder() zapcore.Encoder {
+	return zapcore.NewJSONEncoder(newEncoderConfig())
+}
+
+// New returns a logger configured by opts alone: unlike Config.Build, it
+// reads no environment variables, creates no files but those of WithFile
+// and leaves the global Log alone, so that libraries can build isolated
+// instances. Registered hooks, such as schema validation and drop filters,
+// run as for built loggers.
+func New(opts ...Option) (*zap.Logger, error) {
+	o := newOptions{
+		level:       zapcore.DebugLevel,
+		serviceName: "sad_service",
+		fileMode:    defaultFileMode,
+		dirMode:     defaultDirMode,
+		sinks:       []zapcore.WriteSyncer{zapcore.Lock(os.Stdout)},
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if !o.encoderSet {
+		cfg := newEncoderConfig()
+		if o.caller != nil && *o.caller {
+			cfg.CallerKey = "caller"
+		}
+		if o.stacktrace != nil {
+			cfg.StacktraceKey = "stacktrace"
+		}
+		o.encoder = zapcore.NewConsoleEncoder(cfg)
+	}
+	if o.level == nil {
+		return nil, errors.New("no level")
+	}
+	if o.encoder == nil {
+		return nil, errors.New("no encoder")
+	}
+	if o.noFiles {
+		o.files = nil
+	}
+	if len(o.sinks) == 0 && len(o.files) == 0 {
+		return nil, errors.New("no sinks")
+	}
+	for _, sink := range o.sinks {
+		if sink == nil {
+			return nil, errors.New("nil sink")
+		}
+	}
+
+	sinks := o.sinks
+	for _, f := range o.files {
+		err := os.MkdirAll(filepath.Dir(f.path), o.dirMode)
+		var file *rotatingFile
+		if err == nil {
+			file, err = openRotatingFile(f.path, o.fileMode, f.rotation)
+		}
+		if err != nil {
+			for _, opened := range sinks[len(o.sinks):] {
+				opened.(*rotatingFile).Close()
+			}
+			return nil, fmt.Errorf("failed to open %s: %v", f.path, err)
+		}
+		sinks = append(sinks[:len(sinks):len(sinks)], file)
+	}
+
+	cores := make([]zapcore.Core, 0, len(sinks))
+	for _, sink := range sinks {
+		cores = append(cores, zapcore.NewCore(o.encoder.Clone(), sink, o.level))
+	}
+	core := &hookCore{cores: cores, level: o.level}
+	zapOpts := []zap.Option{
+		zap.WithCaller(o.caller == nil && o.encoderSet || o.caller != nil && *o.caller),
+		zap.AddCallerSkip(o.callerSkip),
+		zap.Fields(
+			zap.String("hostname", hostname),
+			zap.String("serviceName", o.serviceName),
+		),
+	}
+	if o.stacktrace != nil {
+		zapOpts = append(zapOpts, zap.AddStacktrace(o.stacktrace))
+	}
+	return zap.New(core, append(zapOpts, o.zapOpts...)...), nil
+}
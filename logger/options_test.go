@@ -0,0 +1,38 @@
+// sad-go-logger/logger/options_test.go
+
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestNewCaller(t *testing.T) {
+	callerConfig := newEncoderConfig()
+	callerConfig.CallerKey = "caller"
+
+	tests := []struct {
+		name string
+		opts []Option
+		want bool
+	}{
+		{"default", nil, false},
+		{"WithCaller", []Option{WithCaller(true)}, true},
+		{"WithEncoder", []Option{WithEncoder(zapcore.NewJSONEncoder(callerConfig))}, true},
+		{"WithEncoder and WithCaller(false)", []Option{WithEncoder(zapcore.NewJSONEncoder(callerConfig)), WithCaller(false)}, false},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		log, err := New(append(tt.opts, WithSinks(zapcore.AddSync(&buf)))...)
+		if err != nil {
+			t.Fatalf("%s: New: %v", tt.name, err)
+		}
+		log.Info("hello")
+		if got := strings.Contains(buf.String(), "options_test.go"); got != tt.want {
+			t.Errorf("%s: wrote %q, want caller %v", tt.name, buf.String(), tt.want)
+		}
+	}
+}
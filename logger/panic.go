@@ -0,0 +1,48 @@
+// sad-go-logger/logger/panic.go
+
+package logger
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"go.uber.org/zap"
+)
+
+// Go runs fn in a new goroutine. If fn panics, the panic is logged with its
+// stack trace and the entries that preceded it, every sink is flushed, and
+// the panic is raised again, so that the crash is not lost with the
+// entries still queued for the remote sinks.
+func Go(fn func()) {
+	go func() {
+		defer RecoverPanic()
+		fn()
+	}()
+}
+
+// RecoverPanic logs and rethrows a panic like Go does, for goroutines not
+// started with Go. It must be deferred directly, typically first thing in
+// main:
+//
+//	func main() {
+//		defer logger.RecoverPanic()
+//		...
+//	}
+func RecoverPanic() {
+	if p := recover(); p != nil {
+		logPanic(p, debug.Stack())
+		panic(p)
+	}
+}
+
+// logPanic logs a recovered panic and flushes the sinks.
+func logPanic(p interface{}, stack []byte) {
+	fields := []zap.Field{zap.String("panic", fmt.Sprint(p)), zap.String("stacktrace", string(stack))}
+	if recent := flight.snapshot(); len(recent) > 0 {
+		fields = append(fields, zap.Reflect("recentEntries", recent))
+	}
+	Log.Error("Panic", fields...)
+	// Syncing a terminal fails harmlessly; the other sinks are flushed
+	// regardless.
+	Log.Sync()
+}
@@ -0,0 +1,68 @@
+// sad-go-logger/logger/presets.go
+
+package logger
+
+import "os"
+
+// productionSampling is the sampling of ProductionPreset: per second and
+// message, the first 100 entries, then every 100th.
+var productionSampling = SamplingConfig{Initial: 100, Thereafter: 100}
+
+// DevelopmentPreset returns ConfigFromEnv with the defaults suited to a
+// developer's terminal: debug level, the "dev" console rendering, with
+// stack traces for errors, in color if stdout is a terminal, the caller of
+// every entry and no sampling. The environment variables set still take
+// precedence, and Reload keeps the preset.
+func DevelopmentPreset() Config {
+	return developmentDefaults(ConfigFromEnv())
+}
+
+// developmentDefaults applies the defaults of DevelopmentPreset to cfg.
+func developmentDefaults(cfg Config) Config {
+	if os.Getenv("LOG_LEVEL") == "" {
+		cfg.Level = "debug"
+	}
+	if !consoleFormatSet() {
+		cfg.ConsoleFormat = "dev"
+	}
+	if os.Getenv("LOG_CALLER") == "" {
+		cfg.Caller = true
+	}
+	if !colorSet() {
+		cfg.Color = isTerminal(os.Stdout)
+	}
+	return cfg
+}
+
+// ProductionPreset returns ConfigFromEnv with the defaults suited to
+// production: info level, JSON on stdout for log collectors, the caller of
+// every entry and sampling of repeated entries, see productionSampling. The
+// environment variables set still take precedence, and Reload keeps the
+// preset.
+func ProductionPreset() Config {
+	return productionDefaults(ConfigFromEnv())
+}
+
+// productionDefaults applies the defaults of ProductionPreset to cfg.
+func productionDefaults(cfg Config) Config {
+	if os.Getenv("LOG_LEVEL") == "" {
+		cfg.Level = "info"
+	}
+	if !consoleFormatSet() {
+		cfg.ConsoleFormat = "json"
+	}
+	if os.Getenv("LOG_CALLER") == "" {
+		cfg.Caller = true
+	}
+	if cfg.Sampling == nil {
+		sampling := productionSampling
+		cfg.Sampling = &sampling
+	}
+	return cfg
+}
+
+// consoleFormatSet reports whether the environment selects the console
+// rendering.
+func consoleFormatSet() bool {
+	return os.Getenv("LOG_CONSOLE_FORMAT") != "" || os.Getenv("LOG_FORMAT") != ""
+}
@@ -0,0 +1,164 @@
+// sad-go-logger/logger/ratelimit.go
+
+package logger
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sadco-io/sad-go-logger/logger/logfile"
+)
+
+// tokenBucket allows rate events per second on average, in bursts of up to
+// burst events.
+type tokenBucket struct {
+	rate, burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64) *tokenBucket {
+	// Allow one second worth of events at once.
+	burst := rate
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{rate: rate, burst: burst, tokens: burst, last: time.Now()}
+}
+
+// take removes n tokens, going into debt if needed, and returns how long
+// to wait until the debt is repaid. Requests larger than the burst are
+// allowed, after their wait.
+func (b *tokenBucket) take(n float64) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill()
+	b.tokens -= n
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// available reports whether n tokens can be taken without waiting.
+func (b *tokenBucket) available(n float64) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill()
+	return b.tokens >= n || (b.tokens >= b.burst && n > b.burst)
+}
+
+// refill adds the tokens accrued since the last call. b.mu must be held.
+func (b *tokenBucket) refill() {
+	now := time.Now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+}
+
+// rateLimiter limits the requests and bytes per second sent to a sink.
+// Either bucket may be nil.
+type rateLimiter struct {
+	requests, bytes *tokenBucket
+}
+
+// wait blocks until a request of size bytes may be sent.
+func (l *rateLimiter) wait(size int) {
+	var d time.Duration
+	if l.requests != nil {
+		d = max(d, l.requests.take(1))
+	}
+	if l.bytes != nil {
+		d = max(d, l.bytes.take(float64(size)))
+	}
+	if d > 0 {
+		time.Sleep(d)
+	}
+}
+
+// allow reports whether a request of size bytes may be sent now, and
+// takes its tokens if so.
+func (l *rateLimiter) allow(size int) bool {
+	if (l.requests != nil && !l.requests.available(1)) || (l.bytes != nil && !l.bytes.available(float64(size))) {
+		return false
+	}
+	if l.requests != nil {
+		l.requests.take(1)
+	}
+	if l.bytes != nil {
+		l.bytes.take(float64(size))
+	}
+	return true
+}
+
+// blocked reports whether no request can be sent now, whatever its size.
+// It is cheaper than allow.
+func (l *rateLimiter) blocked() bool {
+	return (l.requests != nil && !l.requests.available(1)) || (l.bytes != nil && !l.bytes.available(1))
+}
+
+// rateLimitedWriter is implemented by the remote sinks supporting rate
+// limits.
+type rateLimitedWriter interface {
+	// setRateLimit sets the limiter, and the file receiving the entries
+	// that overflow the queue, if any. It is called before the first
+	// write.
+	setRateLimit(l *rateLimiter, spillPath string) error
+}
+
+// rateLimitFromEnv returns the limits of sink configured by:
+//   - LOG_RATE_LIMIT_<SINK>_REQUESTS: Requests per second
+//   - LOG_RATE_LIMIT_<SINK>_BYTES: Request body bytes per second, before
+//     compression
+//   - LOG_RATE_LIMIT_<SINK>_OVERFLOW: "queue" (default) to drop the oldest
+//     entries once the queue is full, or "spill" to append them to
+//     spill-<sink>.bin in the log directory, from which they can be replayed
+//
+// It returns a nil limiter if no limit is set.
+func rateLimitFromEnv(sink string) (*rateLimiter, string, error) {
+	prefix := "LOG_RATE_LIMIT_" + strings.ToUpper(sink) + "_"
+	l := &rateLimiter{}
+	for _, v := range []struct {
+		name   string
+		bucket **tokenBucket
+	}{{"REQUESTS", &l.requests}, {"BYTES", &l.bytes}} {
+		s := os.Getenv(prefix + v.name)
+		if s == "" {
+			continue
+		}
+		rate, err := strconv.ParseFloat(s, 64)
+		if err != nil || rate <= 0 {
+			return nil, "", fmt.Errorf("invalid %s%s: %s", prefix, v.name, s)
+		}
+		*v.bucket = newTokenBucket(rate)
+	}
+
+	var spillPath string
+	switch overflow := os.Getenv(prefix + "OVERFLOW"); overflow {
+	case "", "queue":
+	case "spill":
+		spillPath = logPath("spill-" + sink + ".bin")
+	default:
+		return nil, "", fmt.Errorf("invalid %sOVERFLOW %q, expected queue or spill", prefix, overflow)
+	}
+
+	if l.requests == nil && l.bytes == nil {
+		l = nil
+	}
+	return l, spillPath, nil
+}
+
+// spillEntries appends entries to the binary log file at path, see
+// logfile.BinaryMagic.
+func spillEntries(path string, entries []map[string]interface{}) error {
+	records := make([]logfile.Record, len(entries))
+	for i, entry := range entries {
+		records[i] = entry
+	}
+	return logfile.AppendBinaryFile(path, records)
+}
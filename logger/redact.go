@@ -0,0 +1,518 @@
+// sad-go-logger/logger/redact.go
+
+package logger
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// RedactTag is the struct tag marking the fields of the objects logged
+// whose value is redacted, e.g.
+//
+//	type User struct {
+//		Email string `json:"email" log:"redact"`
+//	}
+const RedactTag = "redact"
+
+// maxRedactDepth bounds the nesting of the values walked for redaction.
+const maxRedactDepth = 32
+
+var defaultRedactedFields = []string{"password", "token", "ssn", "authorization"}
+
+// redactedFields is the set of the lowercased names of the redacted fields.
+var redactedFields atomic.Pointer[map[string]bool]
+
+func init() {
+	SetRedactedFields(defaultRedactedFields...)
+}
+
+// SetRedactedFields sets the names of the fields whose values are replaced
+// with "[REDACTED]" before an entry reaches any sink of the loggers built
+// with Config.Build or NewTest, matched case-insensitively: the fields
+// passed with an entry or added with Logger.With, and the keys of the
+// objects, maps and structs they hold, at any depth. The struct fields
+// tagged log:"redact" are redacted whatever their name. The names default
+// to password, token, ssn and authorization; LOG_REDACT_FIELDS sets the
+// initial list, comma-separated, or "none". Calling SetRedactedFields
+// without names leaves only the tagged struct fields redacted.
+func SetRedactedFields(names ...string) {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			set[strings.ToLower(name)] = true
+		}
+	}
+	redactedFields.Store(&set)
+}
+
+// RedactedFields returns the names of the redacted fields, lowercased.
+func RedactedFields() []string {
+	set := *redactedFields.Load()
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	return names
+}
+
+// redactedFieldsFromEnv sets the redacted fields of LOG_REDACT_FIELDS.
+func redactedFieldsFromEnv(value string) {
+	if strings.TrimSpace(value) == "none" {
+		SetRedactedFields()
+		return
+	}
+	SetRedactedFields(strings.Split(value, ",")...)
+}
+
+// redactedKey reports whether the values of key are redacted.
+func redactedKey(set map[string]bool, key string) bool {
+	return len(set) > 0 && set[strings.ToLower(key)]
+}
+
+// redactFields returns fields with the redacted values replaced, or fields
+// itself when nothing is redacted.
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	set := *redactedFields.Load()
+	var redacted []zapcore.Field
+	for i, f := range fields {
+		r, changed := redactField(set, f)
+		if !changed {
+			continue
+		}
+		if redacted == nil {
+			redacted = append([]zapcore.Field(nil), fields...)
+		}
+		redacted[i] = r
+	}
+	if redacted == nil {
+		return fields
+	}
+	return redacted
+}
+
+// redactField returns f redacted, and whether it changed. Objects and
+// arrays are redacted as they are encoded.
+func redactField(set map[string]bool, f zapcore.Field) (zapcore.Field, bool) {
+	switch f.Type {
+	case zapcore.SkipType, zapcore.NamespaceType:
+		return f, false
+	}
+	if redactedKey(set, f.Key) {
+		return zap.String(f.Key, redactedValue), true
+	}
+	switch f.Type {
+	case zapcore.ObjectMarshalerType:
+		return zap.Object(f.Key, redactedObject{m: f.Interface.(zapcore.ObjectMarshaler), set: set}), true
+	case zapcore.ArrayMarshalerType:
+		return zap.Array(f.Key, redactedArray{m: f.Interface.(zapcore.ArrayMarshaler), set: set}), true
+	case zapcore.ReflectType:
+		if v, changed := redactValue(set, reflect.ValueOf(f.Interface), 0); changed {
+			return zap.Reflect(f.Key, v), true
+		}
+	}
+	return f, false
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// redactValue returns the value of v with the redacted keys and struct
+// fields replaced, as JSON would encode it, and whether anything was
+// redacted. Values encoding themselves, such as times, are left alone.
+func redactValue(set map[string]bool, v reflect.Value, depth int) (interface{}, bool) {
+	if !v.IsValid() || depth > maxRedactDepth {
+		return nil, false
+	}
+	t := v.Type()
+	if t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) ||
+		reflect.PointerTo(t).Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) {
+		return nil, false
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil, false
+		}
+		return redactValue(set, v.Elem(), depth+1)
+	case reflect.Struct:
+		members, changed := redactStruct(set, v, depth)
+		if !changed {
+			return nil, false
+		}
+		return members, true
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String || v.IsNil() {
+			return nil, false
+		}
+		var m map[string]interface{}
+		iter := v.MapRange()
+		for iter.Next() {
+			key := iter.Key().String()
+			if redactedKey(set, key) {
+				m = copyMap(m, v)
+				m[key] = redactedValue
+			} else if r, changed := redactValue(set, iter.Value(), depth+1); changed {
+				m = copyMap(m, v)
+				m[key] = r
+			}
+		}
+		return m, m != nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return nil, false
+		}
+		var s []interface{}
+		for i := 0; i < v.Len(); i++ {
+			if r, changed := redactValue(set, v.Index(i), depth+1); changed {
+				if s == nil {
+					s = make([]interface{}, v.Len())
+					for j := range s {
+						s[j] = v.Index(j).Interface()
+					}
+				}
+				s[i] = r
+			}
+		}
+		return s, s != nil
+	}
+	return nil, false
+}
+
+// copyMap returns m, or a copy of the map v as a map of interfaces if m is
+// nil.
+func copyMap(m map[string]interface{}, v reflect.Value) map[string]interface{} {
+	if m != nil {
+		return m
+	}
+	m = make(map[string]interface{}, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		m[iter.Key().String()] = iter.Value().Interface()
+	}
+	return m
+}
+
+// structMember is a field of a redacted struct, under its JSON name.
+type structMember struct {
+	name  string
+	value interface{}
+}
+
+// structMembers is a redacted struct, encoded as a JSON object keeping the
+// order of its fields.
+type structMembers []structMember
+
+// MarshalJSON implements json.Marshaler.
+func (s structMembers) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, m := range s {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		name, _ := json.Marshal(m.name)
+		b.Write(name)
+		b.WriteByte(':')
+		value, err := json.Marshal(m.value)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// redactStruct returns the exported fields of the struct v under their
+// JSON names, following the json tags, with the tagged and redacted ones
+// replaced, and whether any was.
+func redactStruct(set map[string]bool, v reflect.Value, depth int) (structMembers, bool) {
+	t := v.Type()
+	members := make(structMembers, 0, t.NumField())
+	changed := false
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		fv := v.Field(i)
+		if sf.Anonymous && name == "" {
+			embedded := fv
+			if embedded.Kind() == reflect.Pointer {
+				if embedded.IsNil() {
+					continue
+				}
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				inner, innerChanged := redactStruct(set, embedded, depth+1)
+				members = append(members, inner...)
+				changed = changed || innerChanged
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		if !fv.CanInterface() || strings.Contains(","+opts+",", ",omitempty,") && emptyValue(fv) {
+			continue
+		}
+		switch {
+		case sf.Tag.Get("log") == RedactTag || redactedKey(set, name):
+			members = append(members, structMember{name: name, value: redactedValue})
+			changed = true
+		default:
+			if r, fieldChanged := redactValue(set, fv, depth+1); fieldChanged {
+				members = append(members, structMember{name: name, value: r})
+				changed = true
+			} else {
+				members = append(members, structMember{name: name, value: fv.Interface()})
+			}
+		}
+	}
+	return members, changed
+}
+
+// emptyValue reports whether v is empty as omitempty means it.
+func emptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return v.IsZero()
+	case reflect.Interface, reflect.Pointer:
+		return v.IsNil()
+	}
+	return false
+}
+
+// redactedObject encodes an ObjectMarshaler with the redacted keys
+// replaced, at any depth.
+type redactedObject struct {
+	m   zapcore.ObjectMarshaler
+	set map[string]bool
+}
+
+// MarshalLogObject implements zapcore.ObjectMarshaler.
+func (o redactedObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	return o.m.MarshalLogObject(&redactingEncoder{ObjectEncoder: enc, set: o.set})
+}
+
+// redactedArray encodes an ArrayMarshaler with the redacted keys of its
+// objects replaced.
+type redactedArray struct {
+	m   zapcore.ArrayMarshaler
+	set map[string]bool
+}
+
+// MarshalLogArray implements zapcore.ArrayMarshaler.
+func (a redactedArray) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	return a.m.MarshalLogArray(&redactingArrayEncoder{ArrayEncoder: enc, set: a.set})
+}
+
+// redactingEncoder is the ObjectEncoder of redactedObject, writing
+// "[REDACTED]" for the redacted keys.
+type redactingEncoder struct {
+	zapcore.ObjectEncoder
+	set map[string]bool
+}
+
+// redacted writes the placeholder under key if it is redacted.
+func (e *redactingEncoder) redacted(key string) bool {
+	if redactedKey(e.set, key) {
+		e.ObjectEncoder.AddString(key, redactedValue)
+		return true
+	}
+	return false
+}
+
+func (e *redactingEncoder) AddArray(key string, m zapcore.ArrayMarshaler) error {
+	if e.redacted(key) {
+		return nil
+	}
+	return e.ObjectEncoder.AddArray(key, redactedArray{m: m, set: e.set})
+}
+
+func (e *redactingEncoder) AddObject(key string, m zapcore.ObjectMarshaler) error {
+	if e.redacted(key) {
+		return nil
+	}
+	return e.ObjectEncoder.AddObject(key, redactedObject{m: m, set: e.set})
+}
+
+func (e *redactingEncoder) AddReflected(key string, value interface{}) error {
+	if e.redacted(key) {
+		return nil
+	}
+	if r, changed := redactValue(e.set, reflect.ValueOf(value), 0); changed {
+		value = r
+	}
+	return e.ObjectEncoder.AddReflected(key, value)
+}
+
+func (e *redactingEncoder) AddBinary(key string, value []byte) {
+	if !e.redacted(key) {
+		e.ObjectEncoder.AddBinary(key, value)
+	}
+}
+
+func (e *redactingEncoder) AddByteString(key string, value []byte) {
+	if !e.redacted(key) {
+		e.ObjectEncoder.AddByteString(key, value)
+	}
+}
+
+func (e *redactingEncoder) AddBool(key string, value bool) {
+	if !e.redacted(key) {
+		e.ObjectEncoder.AddBool(key, value)
+	}
+}
+
+func (e *redactingEncoder) AddComplex128(key string, value complex128) {
+	if !e.redacted(key) {
+		e.ObjectEncoder.AddComplex128(key, value)
+	}
+}
+
+func (e *redactingEncoder) AddComplex64(key string, value complex64) {
+	if !e.redacted(key) {
+		e.ObjectEncoder.AddComplex64(key, value)
+	}
+}
+
+func (e *redactingEncoder) AddDuration(key string, value time.Duration) {
+	if !e.redacted(key) {
+		e.ObjectEncoder.AddDuration(key, value)
+	}
+}
+
+func (e *redactingEncoder) AddFloat64(key string, value float64) {
+	if !e.redacted(key) {
+		e.ObjectEncoder.AddFloat64(key, value)
+	}
+}
+
+func (e *redactingEncoder) AddFloat32(key string, value float32) {
+	if !e.redacted(key) {
+		e.ObjectEncoder.AddFloat32(key, value)
+	}
+}
+
+func (e *redactingEncoder) AddInt(key string, value int) {
+	if !e.redacted(key) {
+		e.ObjectEncoder.AddInt(key, value)
+	}
+}
+
+func (e *redactingEncoder) AddInt64(key string, value int64) {
+	if !e.redacted(key) {
+		e.ObjectEncoder.AddInt64(key, value)
+	}
+}
+
+func (e *redactingEncoder) AddInt32(key string, value int32) {
+	if !e.redacted(key) {
+		e.ObjectEncoder.AddInt32(key, value)
+	}
+}
+
+func (e *redactingEncoder) AddInt16(key string, value int16) {
+	if !e.redacted(key) {
+		e.ObjectEncoder.AddInt16(key, value)
+	}
+}
+
+func (e *redactingEncoder) AddInt8(key string, value int8) {
+	if !e.redacted(key) {
+		e.ObjectEncoder.AddInt8(key, value)
+	}
+}
+
+func (e *redactingEncoder) AddString(key, value string) {
+	if !e.redacted(key) {
+		e.ObjectEncoder.AddString(key, value)
+	}
+}
+
+func (e *redactingEncoder) AddTime(key string, value time.Time) {
+	if !e.redacted(key) {
+		e.ObjectEncoder.AddTime(key, value)
+	}
+}
+
+func (e *redactingEncoder) AddUint(key string, value uint) {
+	if !e.redacted(key) {
+		e.ObjectEncoder.AddUint(key, value)
+	}
+}
+
+func (e *redactingEncoder) AddUint64(key string, value uint64) {
+	if !e.redacted(key) {
+		e.ObjectEncoder.AddUint64(key, value)
+	}
+}
+
+func (e *redactingEncoder) AddUint32(key string, value uint32) {
+	if !e.redacted(key) {
+		e.ObjectEncoder.AddUint32(key, value)
+	}
+}
+
+func (e *redactingEncoder) AddUint16(key string, value uint16) {
+	if !e.redacted(key) {
+		e.ObjectEncoder.AddUint16(key, value)
+	}
+}
+
+func (e *redactingEncoder) AddUint8(key string, value uint8) {
+	if !e.redacted(key) {
+		e.ObjectEncoder.AddUint8(key, value)
+	}
+}
+
+func (e *redactingEncoder) AddUintptr(key string, value uintptr) {
+	if !e.redacted(key) {
+		e.ObjectEncoder.AddUintptr(key, value)
+	}
+}
+
+// redactingArrayEncoder is the ArrayEncoder of redactedArray.
+type redactingArrayEncoder struct {
+	zapcore.ArrayEncoder
+	set map[string]bool
+}
+
+func (e *redactingArrayEncoder) AppendArray(m zapcore.ArrayMarshaler) error {
+	return e.ArrayEncoder.AppendArray(redactedArray{m: m, set: e.set})
+}
+
+func (e *redactingArrayEncoder) AppendObject(m zapcore.ObjectMarshaler) error {
+	return e.ArrayEncoder.AppendObject(redactedObject{m: m, set: e.set})
+}
+
+func (e *redactingArrayEncoder) AppendReflected(value interface{}) error {
+	if r, changed := redactValue(e.set, reflect.ValueOf(value), 0); changed {
+		value = r
+	}
+	return e.ArrayEncoder.AppendReflected(value)
+}
@@ -0,0 +1,143 @@
+// sad-go-logger/logger/redact_test.go
+
+package logger
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// fieldsJSON returns fields encoded as one JSON object, keys sorted.
+func fieldsJSON(t *testing.T, fields []zapcore.Field) string {
+	t.Helper()
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	b, err := json.Marshal(enc.Fields)
+	if err != nil {
+		t.Fatalf("failed to encode fields: %v", err)
+	}
+	return string(b)
+}
+
+// restoreRedactedFields restores the default redacted fields once the test
+// finishes.
+func restoreRedactedFields(t *testing.T) {
+	t.Cleanup(func() { SetRedactedFields(defaultRedactedFields...) })
+}
+
+type account struct {
+	Name   string `json:"name"`
+	Email  string `json:"email" log:"redact"`
+	Token  string `json:"token,omitempty"`
+	Notes  string `json:"-"`
+	secret string
+}
+
+type loginCredentials struct {
+	user, password string
+}
+
+func (c loginCredentials) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("user", c.user)
+	enc.AddString("password", c.password)
+	return nil
+}
+
+func TestRedactFields(t *testing.T) {
+	tests := []struct {
+		name  string
+		field zapcore.Field
+		want  string
+	}{
+		{"key", zap.String("password", "hunter2"), `{"password":"[REDACTED]"}`},
+		{"key case-insensitive", zap.String("Authorization", "Basic abc"), `{"Authorization":"[REDACTED]"}`},
+		{"non-string value", zap.Int("ssn", 123456789), `{"ssn":"[REDACTED]"}`},
+		{"nested map", zap.Any("request", map[string]interface{}{"user": "john", "headers": map[string]string{"Authorization": "Bearer x"}}),
+			`{"request":{"headers":{"Authorization":"[REDACTED]"},"user":"john"}}`},
+		{"slice of maps", zap.Any("items", []map[string]string{{"token": "a"}, {"id": "b"}}),
+			`{"items":[{"token":"[REDACTED]"},{"id":"b"}]}`},
+		{"tagged struct", zap.Any("account", account{Name: "john", Email: "john@example.com", Notes: "n", secret: "s"}),
+			`{"account":{"name":"john","email":"[REDACTED]"}}`},
+		{"struct field by name", zap.Any("account", &account{Name: "john", Email: "e", Token: "t"}),
+			`{"account":{"name":"john","email":"[REDACTED]","token":"[REDACTED]"}}`},
+		{"object marshaler", zap.Object("login", loginCredentials{user: "john", password: "hunter2"}),
+			`{"login":{"password":"[REDACTED]","user":"john"}}`},
+		{"array marshaler", zap.Objects("logins", []loginCredentials{{user: "a", password: "b"}}),
+			`{"logins":[{"password":"[REDACTED]","user":"a"}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fieldsJSON(t, redactFields([]zapcore.Field{tt.field})); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactFieldsUnchanged(t *testing.T) {
+	at := time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+	fields := []zapcore.Field{
+		zap.String("user", "john"),
+		zap.Any("meta", map[string]int{"count": 1}),
+		zap.Any("at", struct{ Time time.Time }{at}),
+		zap.Time("time", at),
+	}
+	got := redactFields(fields)
+	if &got[0] != &fields[0] {
+		t.Error("redactFields copied fields without redacting any")
+	}
+}
+
+func TestRedactFieldsDoesNotChangeTheCaller(t *testing.T) {
+	fields := []zapcore.Field{zap.String("user", "john"), zap.String("token", "abc")}
+	redactFields(fields)
+	if fields[1].String != "abc" {
+		t.Errorf("redactFields changed the caller's fields: %v", fields[1])
+	}
+}
+
+func TestSetRedactedFields(t *testing.T) {
+	restoreRedactedFields(t)
+
+	SetRedactedFields(" Card ", "")
+	if got := fieldsJSON(t, redactFields([]zapcore.Field{zap.String("card", "4111"), zap.String("password", "p")})); got != `{"card":"[REDACTED]","password":"p"}` {
+		t.Errorf("got %s, want only card redacted", got)
+	}
+
+	SetRedactedFields()
+	got := fieldsJSON(t, redactFields([]zapcore.Field{zap.String("card", "4111"), zap.Any("account", account{Email: "e"})}))
+	if want := `{"account":{"name":"","email":"[REDACTED]"},"card":"4111"}`; got != want {
+		t.Errorf("got %s, want only the tagged field redacted: %s", got, want)
+	}
+}
+
+func TestRedactedFieldsFromEnv(t *testing.T) {
+	restoreRedactedFields(t)
+
+	redactedFieldsFromEnv("apiKey, session")
+	if got := fieldsJSON(t, redactFields([]zapcore.Field{zap.String("apikey", "k"), zap.String("session", "s"), zap.String("token", "t")})); got != `{"apikey":"[REDACTED]","session":"[REDACTED]","token":"t"}` {
+		t.Errorf("got %s", got)
+	}
+
+	redactedFieldsFromEnv("none")
+	if names := RedactedFields(); len(names) != 0 {
+		t.Errorf("RedactedFields() = %v after none, want none", names)
+	}
+}
+
+func TestRedactThroughLogger(t *testing.T) {
+	log, obs := NewTest(t)
+
+	log.With(zap.String("token", "abc")).Info("login", zap.String("user", "john"), zap.String("password", "hunter2"))
+
+	fields := obs.Entries()[0].ContextMap()
+	if fields["token"] != redactedValue || fields["password"] != redactedValue || fields["user"] != "john" {
+		t.Errorf("captured fields = %v, want token and password redacted", fields)
+	}
+}
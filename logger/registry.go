@@ -0,0 +1,444 @@
+// sad-go-logger/logger/registry.go
+
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// NamedConfig configures a logger of the registry, see Register.
+type NamedConfig struct {
+	// Level is the minimum level of the logger, in any form accepted by
+	// ParseLevel. Empty means LOG_LEVEL_<NAME>, the name upper-cased with
+	// dots and dashes replaced by underscores, or else the level of the
+	// nearest parent in the registry, "api" being the parent of "api.auth",
+	// or the override of the name or a parent without a logger, see
+	// SetLevelOverrides, or else the level of Log, following their changes;
+	// "-" follows the level of Log regardless of the variable, parents and
+	// overrides.
+	Level string
+
+	// Sinks are written in addition to the sinks of Log, e.g. a file of the
+	// subsystem, and to those of the nearest parent in the registry when
+	// the logger is created. Each sink is a zapcore.Core, or a
+	// RemoteSyncWriter or any other io.Writer receiving the entries as JSON,
+	// as for AttachSink.
+	Sinks []interface{}
+
+	// Isolated writes to Sinks alone instead of the sinks of Log too. The
+	// children of an isolated logger are isolated.
+	Isolated bool
+}
+
+// namedLogger is a logger of the registry.
+type namedLogger struct {
+	logger *zap.Logger
+	level  *namedLevel
+
+	// sinks and isolated are the configuration of the logger, its parent's
+	// included, inherited by its children.
+	sinks    []interface{}
+	isolated bool
+
+	// component is the logger of Named, built on first use.
+	component *zap.Logger
+
+	// explicit is set when the level was given to Register or
+	// SetNamedLevel, instead of resolved by resolveLevel.
+	explicit bool
+}
+
+// namedLevel is the level of a named logger: its own, or while follow is
+// set the one of its parent, or of Log without a parent.
+type namedLevel struct {
+	own    zap.AtomicLevel
+	global zap.AtomicLevel
+	parent atomic.Pointer[namedLevel]
+	follow atomic.Bool
+}
+
+func (l *namedLevel) Enabled(level zapcore.Level) bool {
+	return l.Level().Enabled(level)
+}
+
+// Level returns the current level, see zapcore.LevelOf.
+func (l *namedLevel) Level() zapcore.Level {
+	if l.follow.Load() {
+		if parent := l.parent.Load(); parent != nil {
+			return parent.Level()
+		}
+		return l.global.Level()
+	}
+	return l.own.Level()
+}
+
+// followParent makes the level follow the one of parent, or of Log if nil.
+func (l *namedLevel) followParent(parent *namedLevel) {
+	l.parent.Store(parent)
+	l.follow.Store(true)
+}
+
+// set sets the level of the logger, which stops following another one.
+func (l *namedLevel) set(level zapcore.Level) {
+	l.own.SetLevel(level)
+	l.follow.Store(false)
+}
+
+// registry holds the loggers of Get and Register, by name, and
+// levelOverrides the levels of SetLevelOverrides.
+var (
+	registryMu     sync.Mutex
+	registry       = map[string]*namedLogger{}
+	levelOverrides map[string]zapcore.Level
+)
+
+// Get returns the logger of the subsystem name, e.g. "payments", created on
+// first use with the level of LOG_LEVEL_<NAME> or of the level overrides if
+// set, see NamedConfig, unless Register configured it. It writes to the sinks of Log, remote
+// sinks included, with the logger field set to name, so that drop filters
+// and schemas can select it, and its level is changed with SetNamedLevel.
+//
+// Before Init, Get returns the console logger that Log is, named but not
+// kept: call it once the logger is initialized.
+func Get(name string) *zap.Logger {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if named, ok := registry[name]; ok {
+		return named.logger
+	}
+	g := current()
+	if g == nil {
+		return Log.Named(name)
+	}
+	named, err := newNamedLogger(g, name, NamedConfig{})
+	if err != nil {
+		Log.Warn("Invalid named logger level, using the level of Log", zap.Error(err))
+		named, _ = newNamedLogger(g, name, NamedConfig{Level: "-"})
+		named.explicit = false
+	}
+	registry[name] = named
+	resolveLevels()
+	return named.logger
+}
+
+// Register configures the logger of the subsystem name, returned by Get
+// from then on. It fails if the logger is not initialized, if cfg is
+// invalid or if Get or Register already created the logger, whose
+// holders would keep writing to the previous configuration.
+func Register(name string, cfg NamedConfig) (*zap.Logger, error) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	g := current()
+	if g == nil {
+		return nil, errors.New("logger not initialized")
+	}
+	if _, ok := registry[name]; ok {
+		return nil, fmt.Errorf("logger %q already created", name)
+	}
+	named, err := newNamedLogger(g, name, cfg)
+	if err != nil {
+		return nil, err
+	}
+	registry[name] = named
+	resolveLevels()
+	return named.logger, nil
+}
+
+// SetNamedLevel changes the minimum level of the logger name, creating it
+// if needed, in any form accepted by ParseLevel. The logger stops following
+// the level of Log, LOG_LEVEL_<NAME> and the level overrides.
+func SetNamedLevel(name, level string) error {
+	l, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	logger := Get(name)
+
+	registryMu.Lock()
+	named, ok := registry[name]
+	if ok {
+		named.level.set(l)
+		named.explicit = true
+	}
+	registryMu.Unlock()
+	if !ok {
+		return errors.New("logger not initialized")
+	}
+	logger.Warn("Log level changed", zap.String("level", l.String()))
+	return nil
+}
+
+// Names returns the names of the loggers of the registry, sorted, those of
+// Named included.
+func Names() []string {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	return registryNames()
+}
+
+// NamedLevels returns the level of every logger of the registry, by name.
+func NamedLevels() map[string]string {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	levels := make(map[string]string, len(registry))
+	for name, named := range registry {
+		levels[name] = named.level.Level().String()
+	}
+	return levels
+}
+
+// newNamedLogger builds the logger name from cfg and the configuration of
+// its parent, on the sinks of g. registryMu is held.
+func newNamedLogger(g *builtLogger, name string, cfg NamedConfig) (*namedLogger, error) {
+	level := &namedLevel{own: zap.NewAtomicLevel(), global: g.level}
+	level.follow.Store(true)
+	named := &namedLogger{level: level, explicit: cfg.Level != "", sinks: cfg.Sinks, isolated: cfg.Isolated}
+	if parent := namedParent(name); parent != nil {
+		named.sinks = append(append([]interface{}(nil), parent.sinks...), cfg.Sinks...)
+		named.isolated = parent.isolated || cfg.Isolated
+	}
+	switch cfg.Level {
+	case "":
+		if err := named.resolveLevel(name); err != nil {
+			return nil, err
+		}
+	case "-":
+	default:
+		l, err := ParseLevel(cfg.Level)
+		if err != nil {
+			return nil, err
+		}
+		level.set(l)
+	}
+
+	extra := make([]zapcore.Core, 0, len(named.sinks))
+	for _, sink := range named.sinks {
+		core, err := sinkCore(sink, level)
+		if err != nil {
+			return nil, err
+		}
+		extra = append(extra, core)
+	}
+
+	var core zapcore.Core
+	if named.isolated {
+		if len(extra) == 0 {
+			return nil, errors.New("no sinks")
+		}
+		core = &samplingCore{Core: &hookCore{cores: extra, level: level}, control: g.sampling}
+	} else {
+		core = g.newCore(level, nil, extra)
+	}
+	named.logger = zap.New(core, g.opts...).Named(name)
+	return named, nil
+}
+
+// namedLevelVar returns the variable setting the level of the logger name.
+func namedLevelVar(name string) string {
+	return "LOG_LEVEL_" + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(name))
+}
+
+// resolveLevel sets the level of the logger name from LOG_LEVEL_<NAME>, or
+// else the override of the name, or else the nearest parent in the registry
+// or override of a parent, "storage" being the parent of "storage.s3", or
+// else the level of Log. The level is kept on error. registryMu is held.
+func (n *namedLogger) resolveLevel(name string) error {
+	if value := os.Getenv(namedLevelVar(name)); value != "" {
+		l, err := ParseLevel(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %v", namedLevelVar(name), err)
+		}
+		n.level.set(l)
+		return nil
+	}
+	for parent := name; ; {
+		if l, ok := levelOverrides[parent]; ok {
+			n.level.set(l)
+			return nil
+		}
+		i := strings.LastIndexByte(parent, '.')
+		if i < 0 {
+			n.level.followParent(nil)
+			return nil
+		}
+		parent = parent[:i]
+		if named, ok := registry[parent]; ok {
+			n.level.followParent(named.level)
+			return nil
+		}
+	}
+}
+
+// namedParent returns the nearest parent of the logger name in the
+// registry, or nil. registryMu is held.
+func namedParent(name string) *namedLogger {
+	for {
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			return nil
+		}
+		name = name[:i]
+		if named, ok := registry[name]; ok {
+			return named
+		}
+	}
+}
+
+// SetLevelOverrides replaces the level overrides of the named loggers, see
+// Config.LevelOverrides, e.g.
+//
+//	logger.SetLevelOverrides(map[string]string{"storage": "debug", "http": "warn"})
+//
+// The loggers of Get follow at once, except for those whose level was
+// given to Register or SetNamedLevel, or set by LOG_LEVEL_<NAME>. Init and
+// Reload replace the overrides with those of the configuration.
+func SetLevelOverrides(overrides map[string]string) error {
+	if _, err := parseLevelOverrides(overrides); err != nil {
+		return err
+	}
+	return setLevelOverrides(overrides)
+}
+
+// LevelOverrides returns the level overrides of the named loggers, by name.
+func LevelOverrides() map[string]string {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	overrides := make(map[string]string, len(levelOverrides))
+	for name, l := range levelOverrides {
+		overrides[name] = l.String()
+	}
+	return overrides
+}
+
+// setLevelOverrides replaces the level overrides, already validated, and
+// resolves the levels of the loggers again. It returns the errors of the
+// LOG_LEVEL_<NAME> variables.
+func setLevelOverrides(overrides map[string]string) error {
+	parsed, _ := parseLevelOverrides(overrides)
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	levelOverrides = parsed
+	return resolveLevels()
+}
+
+// resolveLevels resolves the levels of the loggers again, once the
+// overrides changed or a logger, possibly the parent of others, was
+// created. It returns the errors of the LOG_LEVEL_<NAME> variables.
+// registryMu is held.
+func resolveLevels() error {
+	var errs []string
+	for _, name := range registryNames() {
+		if named := registry[name]; !named.explicit {
+			if err := named.resolveLevel(name); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// registryNames returns the names of the registry, sorted. registryMu is
+// held.
+func registryNames() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseLevelOverrides parses level overrides written as comma-separated
+// name=level pairs, such as "storage=debug,http=warn", the format of
+// LOG_LEVEL_OVERRIDES. On error, it returns the valid pairs too.
+func ParseLevelOverrides(s string) (map[string]string, error) {
+	overrides := map[string]string{}
+	var errs []string
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		name, level, ok := strings.Cut(pair, "=")
+		name, level = strings.TrimSpace(name), strings.TrimSpace(level)
+		if !ok || name == "" {
+			errs = append(errs, fmt.Sprintf("invalid level override %q, expected name=level", pair))
+			continue
+		}
+		if _, err := ParseLevel(level); err != nil {
+			errs = append(errs, fmt.Sprintf("invalid level override of %s: %v", name, err))
+			continue
+		}
+		overrides[name] = level
+	}
+	if len(errs) > 0 {
+		return overrides, errors.New(strings.Join(errs, "; "))
+	}
+	return overrides, nil
+}
+
+// parseLevelOverrides returns the levels of overrides.
+func parseLevelOverrides(overrides map[string]string) (map[string]zapcore.Level, error) {
+	parsed := make(map[string]zapcore.Level, len(overrides))
+	for name, level := range overrides {
+		l, err := ParseLevel(level)
+		if err != nil {
+			return nil, fmt.Errorf("invalid level override of %s: %v", name, err)
+		}
+		parsed[name] = l
+	}
+	return parsed, nil
+}
+
+// Component is a logger of the registry returned by Named, writing its name
+// as the component field.
+type Component struct {
+	*zap.Logger
+	name string
+}
+
+// Named returns the logger of the component name, the logger of Get(name)
+// with the component field set to name. Its Named method returns the
+// children of the component, e.g.
+//
+//	auth := logger.Named("api").Named("auth") // component "api.auth"
+//
+// which inherit the level of their parent, unless set otherwise, and the
+// sinks it was registered with. Names lists the components, and
+// SetNamedLevel changes their level at runtime, their children following.
+func Named(name string) *Component {
+	logger := Get(name)
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	named, ok := registry[name]
+	if !ok {
+		return &Component{Logger: logger.With(zap.String("component", name)), name: name}
+	}
+	if named.component == nil {
+		named.component = logger.With(zap.String("component", name))
+	}
+	return &Component{Logger: named.component, name: name}
+}
+
+// Named returns the child component name of c, see the Named function.
+func (c *Component) Named(name string) *Component {
+	return Named(c.name + "." + name)
+}
+
+// Name returns the dotted name of the component, e.g. "api.auth".
+func (c *Component) Name() string {
+	return c.name
+}
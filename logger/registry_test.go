@@ -0,0 +1,169 @@
+// sad-go-logger/logger/registry_test.go
+
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// useTestRegistry empties the registry and the level overrides, restoring
+// them once the test finishes.
+func useTestRegistry(t *testing.T) {
+	t.Helper()
+	registryMu.Lock()
+	previous, previousOverrides := registry, levelOverrides
+	registry, levelOverrides = map[string]*namedLogger{}, nil
+	registryMu.Unlock()
+	t.Cleanup(func() {
+		registryMu.Lock()
+		registry, levelOverrides = previous, previousOverrides
+		registryMu.Unlock()
+	})
+}
+
+func TestGetLevels(t *testing.T) {
+	remote := &recordingWriter{}
+	useRemoteTestLogger(t, remote)
+	useTestRegistry(t)
+	t.Setenv("LOG_LEVEL_PAYMENTS_DB", "warn")
+
+	db := Get("payments.db")
+	if Get("payments.db") != db {
+		t.Error("Get returned a new logger for the same name")
+	}
+	db.Info("query")
+	db.Warn("slow query")
+	Get("cache").Debug("miss")
+
+	out := remote.String()
+	if strings.Contains(out, `"query"`) || !strings.Contains(out, `"logger":"payments.db"`) {
+		t.Errorf("payments.db wrote %q, want the warning alone", out)
+	}
+	if !strings.Contains(out, `"miss"`) {
+		t.Errorf("cache wrote %q, want the debug entry at the level of Log", out)
+	}
+
+	if err := SetNamedLevel("cache", "error"); err != nil {
+		t.Fatalf("SetNamedLevel: %v", err)
+	}
+	if got := NamedLevels(); got["cache"] != "error" || got["payments.db"] != "warn" {
+		t.Errorf("NamedLevels() = %v, want cache at error and payments.db at warn", got)
+	}
+	if err := SetNamedLevel("cache", "loud"); err == nil {
+		t.Error("SetNamedLevel accepted an invalid level")
+	}
+}
+
+func TestRegisterIsolated(t *testing.T) {
+	remote := &recordingWriter{}
+	useRemoteTestLogger(t, remote)
+	useTestRegistry(t)
+
+	own := &recordingWriter{}
+	audit, err := Register("audit", NamedConfig{Level: "info", Sinks: []interface{}{zapcore.AddSync(own)}, Isolated: true})
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	audit.Debug("hidden")
+	audit.Info("user created")
+
+	if out := own.String(); !strings.Contains(out, `"user created"`) || strings.Contains(out, `"hidden"`) {
+		t.Errorf("the sink of audit got %q, want the info entry alone", out)
+	}
+	if out := remote.String(); out != "" {
+		t.Errorf("the sinks of Log got %q from an isolated logger", out)
+	}
+	if Get("audit") != audit {
+		t.Error("Get did not return the registered logger")
+	}
+	if _, err := Register("audit", NamedConfig{}); err == nil {
+		t.Error("Register accepted a logger already created")
+	}
+	if _, err := Register("empty", NamedConfig{Isolated: true}); err == nil {
+		t.Error("Register accepted an isolated logger without sinks")
+	}
+}
+
+func TestLevelOverrides(t *testing.T) {
+	useRemoteTestLogger(t, &recordingWriter{})
+	useTestRegistry(t)
+	t.Setenv("LOG_LEVEL_STORAGE_CACHE", "debug")
+
+	if err := SetLevelOverrides(map[string]string{"storage": "warn", "storage.s3.upload": "error"}); err != nil {
+		t.Fatalf("SetLevelOverrides: %v", err)
+	}
+	Get("storage.s3")
+	Get("storage.s3.upload.part")
+	Get("storage.cache")
+	Get("http")
+	SetNamedLevel("pinned", "info")
+
+	want := map[string]string{
+		"storage.s3":             "warn",
+		"storage.s3.upload.part": "error",
+		"storage.cache":          "debug",
+		"http":                   "debug",
+		"pinned":                 "info",
+	}
+	if got := NamedLevels(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("NamedLevels() = %v, want %v", got, want)
+	}
+
+	if err := SetLevelOverrides(map[string]string{"http": "info", "pinned": "error"}); err != nil {
+		t.Fatalf("SetLevelOverrides: %v", err)
+	}
+	if got := NamedLevels(); got["storage.s3"] != "debug" || got["http"] != "info" || got["pinned"] != "info" {
+		t.Errorf("NamedLevels() = %v, want storage.s3 following Log again, http at info and pinned kept", got)
+	}
+	if err := SetLevelOverrides(map[string]string{"http": "loud"}); err == nil {
+		t.Error("SetLevelOverrides accepted an invalid level")
+	}
+}
+
+func TestParseLevelOverrides(t *testing.T) {
+	got, err := ParseLevelOverrides("storage=debug, http = WARN,,")
+	if err != nil || fmt.Sprint(got) != "map[http:WARN storage:debug]" {
+		t.Errorf("ParseLevelOverrides() = %v, %v, want storage and http", got, err)
+	}
+	got, err = ParseLevelOverrides("storage=debug,http,db=loud")
+	if err == nil || fmt.Sprint(got) != "map[storage:debug]" {
+		t.Errorf("ParseLevelOverrides() = %v, %v, want an error and the valid pair", got, err)
+	}
+}
+
+func TestNamedComponents(t *testing.T) {
+	remote := &recordingWriter{}
+	useRemoteTestLogger(t, remote)
+	useTestRegistry(t)
+
+	own := &recordingWriter{}
+	if _, err := Register("api", NamedConfig{Level: "warn", Sinks: []interface{}{zapcore.AddSync(own)}}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	auth := Named("api").Named("auth")
+	if auth.Name() != "api.auth" {
+		t.Errorf("Name() = %q, want api.auth", auth.Name())
+	}
+	auth.Info("hidden")
+	auth.Warn("token expired")
+
+	for sink, out := range map[string]string{"Log": remote.String(), "api": own.String()} {
+		if strings.Contains(out, `"hidden"`) || !strings.Contains(out, `"component":"api.auth"`) {
+			t.Errorf("the sinks of %s got %q, want the warning of api.auth alone", sink, out)
+		}
+	}
+
+	if err := SetNamedLevel("api", "debug"); err != nil {
+		t.Fatalf("SetNamedLevel: %v", err)
+	}
+	if !auth.Core().Enabled(zapcore.DebugLevel) {
+		t.Error("api.auth did not follow the level of api")
+	}
+	if got := fmt.Sprint(Names()); got != "[api api.auth]" {
+		t.Errorf("Names() = %s, want [api api.auth]", got)
+	}
+}
@@ -0,0 +1,119 @@
+// sad-go-logger/logger/reload.go
+
+package logger
+
+import (
+	"errors"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Reload applies the current configuration to the global Log without a
+// restart, the way syslog daemons do on SIGHUP: it reloads the file named by
+// LOG_CONFIG_FILE, applies the variables read by ConfigFromEnv on top of the
+// configuration given to Init, and replaces the sinks of Log. The log files
+// are reopened, in the log directory resolved again, the remote sinks
+// enabled since are added and the disabled ones closed, and the level,
+// level overrides and sampling are updated. The loggers derived from Log
+// follow, except for the service name, console format and caller and stack
+// trace settings, fixed by Init. The access and audit logs are reopened at
+// their path, for logrotate.
+//
+// Reload is called on SIGHUP when LOG_RELOAD_ON_SIGHUP is "true", see
+// ReloadOnSignal. On error, the previous sinks are kept.
+func Reload() error {
+	initMu.Lock()
+	defer initMu.Unlock()
+	if global == nil {
+		return errors.New("logger not initialized")
+	}
+
+	initLog = make(map[string]interface{})
+	cfg := configFromEnv(globalConfig)
+	level, err := cfg.level()
+	if err != nil {
+		return err
+	}
+	resetLogDir()
+	set, err := cfg.buildSinks(global.attached)
+	if err != nil {
+		return err
+	}
+
+	previous := global.sinks.Swap(set)
+	global.level.SetLevel(level)
+	global.sampling.set(cfg.Sampling)
+	globalConfig = cfg
+	previous.close()
+	reopenFiles()
+	if err := setLevelOverrides(cfg.LevelOverrides); err != nil {
+		initLog["namedLevelsMessage"] = err.Error()
+	}
+
+	for key, value := range initLog {
+		Log.Sugar().Infof("%s, %v", key, value)
+	}
+	Log.Info("Logger reloaded", zap.String("level", level.String()), zap.Strings("sinks", set.names))
+	return nil
+}
+
+// reloadCore writes to the current sinks of a builtLogger, so that the
+// loggers derived from it before a Reload write to the new sinks too. The
+// core of the sinks, with the fields added by Logger.With, is built on
+// first use, and again after every reload.
+type reloadCore struct {
+	logger *builtLogger
+	level  zapcore.LevelEnabler
+	tail   *tailBuffer
+	extra  []zapcore.Core
+	fields []zapcore.Field
+
+	built atomic.Pointer[reloadedCore]
+}
+
+// reloadedCore is the core a reloadCore built for a sinkSet.
+type reloadedCore struct {
+	sinks *sinkSet
+	core  zapcore.Core
+}
+
+func (c *reloadCore) current() zapcore.Core {
+	sinks := c.logger.sinks.Load()
+	if built := c.built.Load(); built != nil && built.sinks == sinks {
+		return built.core
+	}
+	core := sinks.newCore(c.level, c.tail, c.extra)
+	if len(c.fields) > 0 {
+		core = core.With(c.fields)
+	}
+	c.built.Store(&reloadedCore{sinks: sinks, core: core})
+	return core
+}
+
+func (c *reloadCore) Enabled(level zapcore.Level) bool {
+	return c.current().Enabled(level)
+}
+
+func (c *reloadCore) With(fields []zapcore.Field) zapcore.Core {
+	return &reloadCore{
+		logger: c.logger,
+		level:  c.level,
+		tail:   c.tail,
+		extra:  c.extra,
+		fields: append(append([]zapcore.Field(nil), c.fields...), fields...),
+	}
+}
+
+func (c *reloadCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return c.current().Check(ent, ce)
+}
+
+func (c *reloadCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.current().Write(ent, fields)
+}
+
+func (c *reloadCore) Sync() error {
+	return c.current().Sync()
+}
@@ -0,0 +1,163 @@
+// sad-go-logger/logger/reload_test.go
+
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// initForTest initializes the global logger from the environment, with
+// remote as a remote sink, and undoes it once the test finishes.
+func initForTest(t *testing.T, remote RemoteSyncWriter) {
+	t.Helper()
+	previous := Log
+	cfg := ConfigFromEnv()
+	cfg.RemoteWriters = map[string]RemoteSyncWriter{"recorder": remote}
+	if err := Init(cfg); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	t.Cleanup(func() {
+		initMu.Lock()
+		defer initMu.Unlock()
+		Log.Sync()
+		global.sinks.Load().close()
+		global, Log, globalConfig = nil, previous, Config{}
+		resetLogDir()
+	})
+}
+
+func TestReloadNotInitialized(t *testing.T) {
+	if err := Reload(); err == nil || !strings.Contains(err.Error(), "not initialized") {
+		t.Errorf("Reload() error = %v, want not initialized", err)
+	}
+}
+
+func TestReloadAppliesLevelToDerivedLoggers(t *testing.T) {
+	t.Setenv("LOG_DIR", t.TempDir())
+	t.Setenv("LOG_LEVEL", "info")
+	remote := &recordingWriter{}
+	initForTest(t, remote)
+
+	derived := Log.With(zap.String("component", "billing"))
+	derived.Debug("before reload")
+
+	t.Setenv("LOG_LEVEL", "debug")
+	if err := Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	derived.Debug("after reload")
+	Log.Sync()
+
+	got := remote.String()
+	if strings.Contains(got, "before reload") {
+		t.Error("debug entry written at info level")
+	}
+	if !strings.Contains(got, `"message":"after reload"`) || !strings.Contains(got, `"component":"billing"`) {
+		t.Errorf("derived logger did not follow the reload:\n%s", got)
+	}
+	if !strings.Contains(got, "Logger reloaded") {
+		t.Errorf("reload not logged:\n%s", got)
+	}
+}
+
+func TestReloadReopensFilesInNewDir(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	t.Setenv("LOG_DIR", first)
+	initForTest(t, &recordingWriter{})
+
+	Log.Info("in the first directory")
+	t.Setenv("LOG_DIR", second)
+	if err := Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	Log.Info("in the second directory")
+	Log.Sync()
+
+	for _, tt := range []struct {
+		dir, want, notWant string
+	}{
+		{first, "in the first directory", "in the second directory"},
+		{second, "in the second directory", "in the first directory"},
+	} {
+		b, err := os.ReadFile(filepath.Join(tt.dir, "logs.txt"))
+		if err != nil {
+			t.Fatalf("failed to read the log file: %v", err)
+		}
+		if !strings.Contains(string(b), tt.want) || strings.Contains(string(b), tt.notWant) {
+			t.Errorf("%s/logs.txt = %s, want %q only", tt.dir, b, tt.want)
+		}
+	}
+}
+
+func TestReloadKeepsSinksOnError(t *testing.T) {
+	t.Setenv("LOG_DIR", t.TempDir())
+	t.Setenv("LOG_LEVEL", "info")
+	remote := &recordingWriter{}
+	initForTest(t, remote)
+	sinks := global.sinks.Load()
+
+	// The log file cannot be created under a regular file.
+	notDir := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(notDir, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("LOG_LEVEL", "debug")
+	t.Setenv("LOG_DIR", filepath.Join(notDir, "logs"))
+	if err := Reload(); err == nil {
+		t.Fatal("Reload succeeded without its log file")
+	}
+	if global.sinks.Load() != sinks {
+		t.Error("Reload replaced the sinks although it failed")
+	}
+
+	Log.Debug("still at info")
+	Log.Info("still written")
+	Log.Sync()
+	got := remote.String()
+	if strings.Contains(got, "still at info") || !strings.Contains(got, "still written") {
+		t.Errorf("previous configuration not kept:\n%s", got)
+	}
+}
+
+func TestReloadKeepsInitConfig(t *testing.T) {
+	t.Setenv("LOG_DIR", t.TempDir())
+	t.Setenv("LOG_LEVEL", "")
+	remote := &recordingWriter{}
+	previous := Log
+	err := Init(Config{
+		ServiceName:   "billing",
+		Level:         "warn",
+		RemoteWriters: map[string]RemoteSyncWriter{"recorder": remote},
+	})
+	if err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	t.Cleanup(func() {
+		initMu.Lock()
+		defer initMu.Unlock()
+		Log.Sync()
+		global.sinks.Load().close()
+		global, Log, globalConfig = nil, previous, Config{}
+		resetLogDir()
+	})
+
+	if err := Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	Log.Info("below warn")
+	Log.Warn("after reload")
+	Log.Sync()
+
+	got := remote.String()
+	if strings.Contains(got, "below warn") || !strings.Contains(got, "after reload") {
+		t.Errorf("level given to Init not kept:\n%s", got)
+	}
+	if globalConfig.ServiceName != "billing" || globalConfig.Level != "warn" {
+		t.Errorf("Reload replaced the configuration given to Init: %+v", globalConfig)
+	}
+}
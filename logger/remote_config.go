@@ -0,0 +1,272 @@
+// sad-go-logger/logger/remote_config.go
+
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// RemoteConfig is the document stored in the Consul or etcd key watched by
+// WatchRemoteConfig, for example:
+//
+//	{"level": "debug", "sampling": {"initial": 100, "thereafter": 100}}
+//
+// An absent level leaves the current level unchanged; an absent or null
+// sampling disables sampling.
+type RemoteConfig struct {
+	Level    string          `json:"level"`
+	Sampling *SamplingConfig `json:"sampling"`
+}
+
+// RemoteConfigSource delivers the successive values of a remote
+// configuration key.
+type RemoteConfigSource interface {
+	// Watch calls apply with the current value of the key and then with
+	// every new value, until ctx is done.
+	Watch(ctx context.Context, apply func(value []byte)) error
+}
+
+// remoteConfigRetryInterval is the delay before a failed watch is retried.
+const remoteConfigRetryInterval = 10 * time.Second
+
+// WatchRemoteConfig applies the configuration stored in src to the global
+// logger, live, until ctx is done, so one write to the key reconfigures a
+// whole fleet. Invalid documents are reported and ignored.
+//
+// It is started by Init when LOG_REMOTE_CONFIG is "consul" or "etcd", see
+// remoteConfigSourceFromEnv.
+func WatchRemoteConfig(ctx context.Context, src RemoteConfigSource) {
+	go func() {
+		for {
+			err := src.Watch(ctx, applyRemoteConfig)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				Log.Warn("Remote configuration watch failed, will retry", zap.Error(err))
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(remoteConfigRetryInterval):
+			}
+		}
+	}()
+}
+
+// applyRemoteConfig parses and applies one remote configuration document.
+func applyRemoteConfig(value []byte) {
+	g := current()
+	if g == nil {
+		Log.Warn("Ignoring remote configuration, logger not initialized")
+		return
+	}
+	var rc RemoteConfig
+	if len(bytes.TrimSpace(value)) > 0 {
+		if err := json.Unmarshal(value, &rc); err != nil {
+			Log.Warn("Ignoring invalid remote configuration", zap.Error(err))
+			return
+		}
+	}
+
+	if rc.Level != "" {
+		level, err := ParseLevel(rc.Level)
+		if err != nil {
+			Log.Warn("Ignoring invalid remote configuration level", zap.String("level", rc.Level))
+			return
+		}
+		if level != g.level.Level() {
+			g.level.SetLevel(level)
+			Log.Info("Log level changed by remote configuration", zap.String("level", level.String()))
+		}
+	}
+	g.sampling.set(rc.Sampling)
+}
+
+// remoteConfigSourceFromEnv returns the source described by:
+//   - LOG_REMOTE_CONFIG: "consul" or "etcd"
+//   - LOG_REMOTE_CONFIG_ADDR: base URL of the Consul agent or etcd endpoint
+//     (default: "http://127.0.0.1:8500" for Consul, "http://127.0.0.1:2379" for etcd)
+//   - LOG_REMOTE_CONFIG_KEY: the key to watch (default: "sad-logger/<serviceName>")
+//   - LOG_REMOTE_CONFIG_TOKEN: Consul ACL token or etcd auth token (optional)
+//
+// It returns nil if LOG_REMOTE_CONFIG is not set.
+func remoteConfigSourceFromEnv() (RemoteConfigSource, error) {
+	kind := os.Getenv("LOG_REMOTE_CONFIG")
+	addr := os.Getenv("LOG_REMOTE_CONFIG_ADDR")
+	key := os.Getenv("LOG_REMOTE_CONFIG_KEY")
+	token := os.Getenv("LOG_REMOTE_CONFIG_TOKEN")
+	if key == "" {
+		key = "sad-logger/" + serviceName
+	}
+
+	switch kind {
+	case "":
+		return nil, nil
+	case "consul":
+		if addr == "" {
+			addr = "http://127.0.0.1:8500"
+		}
+		return NewConsulConfigSource(addr, key, token), nil
+	case "etcd":
+		if addr == "" {
+			addr = "http://127.0.0.1:2379"
+		}
+		return NewEtcdConfigSource(addr, key, token), nil
+	default:
+		return nil, fmt.Errorf("unknown LOG_REMOTE_CONFIG %q, expected consul or etcd", kind)
+	}
+}
+
+// consulConfigSource watches a Consul KV key with blocking queries.
+type consulConfigSource struct {
+	addr   string
+	key    string
+	token  string
+	client *http.Client
+}
+
+// consulWait is how long a Consul blocking query waits for a change.
+const consulWait = 5 * time.Minute
+
+// NewConsulConfigSource returns a source watching key in the KV store of
+// the Consul agent at addr, e.g. "http://127.0.0.1:8500".
+func NewConsulConfigSource(addr, key, token string) RemoteConfigSource {
+	return &consulConfigSource{
+		addr:   strings.TrimRight(addr, "/"),
+		key:    strings.TrimLeft(key, "/"),
+		token:  token,
+		client: &http.Client{Timeout: consulWait + 30*time.Second},
+	}
+}
+
+func (s *consulConfigSource) Watch(ctx context.Context, apply func([]byte)) error {
+	var index uint64
+	for {
+		u := fmt.Sprintf("%s/v1/kv/%s?raw&index=%d&wait=%s", s.addr, s.key, index, consulWait)
+		req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+		if err != nil {
+			return fmt.Errorf("failed to create request: %v", err)
+		}
+		if s.token != "" {
+			req.Header.Set("X-Consul-Token", s.token)
+		}
+
+		resp, err := s.client.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to query Consul: %v", err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read Consul response: %v", err)
+		}
+
+		newIndex, _ := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+		switch resp.StatusCode {
+		case http.StatusOK:
+			if newIndex != index {
+				apply(body)
+			}
+		case http.StatusNotFound:
+			// The key does not exist (yet): keep the current settings.
+		default:
+			return fmt.Errorf("consul returned unexpected status code: %d", resp.StatusCode)
+		}
+
+		// Reset the index if it goes backwards, as recommended by Consul.
+		if newIndex < index {
+			newIndex = 0
+		}
+		index = newIndex
+	}
+}
+
+// etcdConfigSource polls a key through the etcd v3 JSON gateway.
+type etcdConfigSource struct {
+	addr     string
+	key      string
+	token    string
+	interval time.Duration
+	client   *http.Client
+}
+
+// etcdPollInterval is how often the etcd key is read.
+const etcdPollInterval = 10 * time.Second
+
+// NewEtcdConfigSource returns a source watching key through the etcd v3
+// JSON gateway at addr, e.g. "http://127.0.0.1:2379".
+func NewEtcdConfigSource(addr, key, token string) RemoteConfigSource {
+	return &etcdConfigSource{
+		addr:     strings.TrimRight(addr, "/"),
+		key:      key,
+		token:    token,
+		interval: etcdPollInterval,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *etcdConfigSource) Watch(ctx context.Context, apply func([]byte)) error {
+	body, err := json.Marshal(map[string]string{"key": base64.StdEncoding.EncodeToString([]byte(s.key))})
+	if err != nil {
+		return err
+	}
+
+	revision := ""
+	for {
+		req, err := http.NewRequestWithContext(ctx, "POST", s.addr+"/v3/kv/range", bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("failed to create request: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if s.token != "" {
+			req.Header.Set("Authorization", s.token)
+		}
+
+		resp, err := s.client.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to query etcd: %v", err)
+		}
+		var result struct {
+			Kvs []struct {
+				Value       string `json:"value"`
+				ModRevision string `json:"mod_revision"`
+			} `json:"kvs"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("etcd returned unexpected status code: %d", resp.StatusCode)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to decode etcd response: %v", err)
+		}
+
+		if len(result.Kvs) > 0 && result.Kvs[0].ModRevision != revision {
+			value, err := base64.StdEncoding.DecodeString(result.Kvs[0].Value)
+			if err != nil {
+				return fmt.Errorf("failed to decode etcd value: %v", err)
+			}
+			revision = result.Kvs[0].ModRevision
+			apply(value)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(s.interval):
+		}
+	}
+}
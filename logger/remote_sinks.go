@@ -0,0 +1,100 @@
+// sad-go-logger/logger/remote_sinks.go
+
+package logger
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// remoteSinkType is a remote destination of the logger, enabled with
+// ENABLE_REMOTE_SYNC_<NAME>=true and configured by its own environment
+// variables.
+type remoteSinkType struct {
+	name string
+	open func() RemoteSyncWriter
+
+	// enableVar, when set, names a variable that also enables the sink
+	// when it is set, such as the sink's token.
+	enableVar string
+}
+
+// remoteSinkTypes are the supported remote destinations, in the order they
+// are added to the logger.
+var remoteSinkTypes = []remoteSinkType{
+	{name: "elk", open: NewRemoteSyncWriter},
+	{name: "newrelic", open: NewNewRelicRemoteSyncWriter},
+	{name: "clickhouse", open: NewClickHouseRemoteSyncWriter},
+	{name: "postgres", open: NewPostgresRemoteSyncWriter},
+	{name: "mongo", open: NewMongoRemoteSyncWriter},
+	{name: "honeycomb", open: NewHoneycombRemoteSyncWriter},
+	{name: "axiom", open: NewAxiomRemoteSyncWriter},
+	{name: "logtail", open: NewLogtailRemoteSyncWriter, enableVar: "LOGTAIL_SOURCE_TOKEN"},
+	{name: "victorialogs", open: NewVictoriaLogsRemoteSyncWriter},
+	{name: "loki", open: NewLokiRemoteSyncWriter},
+	{name: "grafanacloud", open: NewGrafanaCloudRemoteSyncWriter},
+	{name: "sumologic", open: NewSumoLogicRemoteSyncWriter},
+	{name: "syslog", open: NewSyslogRemoteSyncWriter},
+	{name: "papertrail", open: NewPapertrailRemoteSyncWriter},
+	{name: "coralogix", open: NewCoralogixRemoteSyncWriter},
+	{name: "mezmo", open: NewMezmoRemoteSyncWriter},
+	{name: "openobserve", open: NewOpenObserveRemoteSyncWriter},
+	{name: "unix", open: NewUnixSocketRemoteSyncWriter},
+	{name: "fifo", open: NewFIFORemoteSyncWriter},
+	{name: "grpc", open: NewGRPCRemoteSyncWriter},
+	{name: "kafka", open: NewKafkaRemoteSyncWriter},
+}
+
+// batchedWriter is implemented by the remote sinks sending their entries in
+// batches.
+type batchedWriter interface {
+	// setBatchSize sets the number of entries sent at once. It is called
+	// before the first write.
+	setBatchSize(n int)
+}
+
+// batchSizeFromEnv returns the batch size of sink set by
+// LOG_BATCH_SIZE_<SINK>, or 0 if it is not set.
+func batchSizeFromEnv(sink string) (int, error) {
+	name := "LOG_BATCH_SIZE_" + strings.ToUpper(sink)
+	s := os.Getenv(name)
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid %s: %s", name, s)
+	}
+	return n, nil
+}
+
+// RemoteSinkNames returns the names of the supported remote destinations.
+func RemoteSinkNames() []string {
+	names := make([]string, len(remoteSinkTypes))
+	for i, t := range remoteSinkTypes {
+		names[i] = t.name
+	}
+	return names
+}
+
+// NewRemoteSink creates the writer of the named remote destination from the
+// environment, whether or not it is enabled for the logger. The writer is
+// nil if its configuration is incomplete.
+func NewRemoteSink(name string) (RemoteSyncWriter, error) {
+	for _, t := range remoteSinkTypes {
+		if t.name == name {
+			return t.open(), nil
+		}
+	}
+	return nil, fmt.Errorf("unknown remote sink %q, expected one of %s", name, strings.Join(RemoteSinkNames(), ", "))
+}
+
+// enabled reports whether the destination is enabled.
+func (t remoteSinkType) enabled() bool {
+	if t.enableVar != "" && os.Getenv(t.enableVar) != "" {
+		return true
+	}
+	return os.Getenv("ENABLE_REMOTE_SYNC_"+strings.ToUpper(t.name)) == "true"
+}
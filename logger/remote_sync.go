@@ -1,8 +1,56 @@
-// sad-go-logger/logger/remote_sync.go
-
-package logger
-
-type RemoteSyncWriter interface {
-	Write(p []byte) (n int, err error)
-	Sync() error
-}
+// sad-go-logger/logger/remote_sync.go
+
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+)
+
+type RemoteSyncWriter interface {
+	Write(p []byte) (n int, err error)
+	Sync() error
+}
+
+// remoteWritersFromEnv returns the writers of a secondary pipeline, such as
+// the audit log, configured by environment variables named after prefix:
+//   - <prefix>_LOGSTASH_HOST, <prefix>_LOGSTASH_PORT and <prefix>_LOGSTASH_USE_TLS
+//   - <prefix>_NEW_RELIC_API_KEY and <prefix>_NEW_RELIC_LOGS_ENDPOINT
+func remoteWritersFromEnv(prefix string) []RemoteSyncWriter {
+	var writers []RemoteSyncWriter
+	host, port := os.Getenv(prefix+"_LOGSTASH_HOST"), os.Getenv(prefix+"_LOGSTASH_PORT")
+	if host != "" && port != "" {
+		writers = append(writers, newELKRemoteSyncWriter(host, port, os.Getenv(prefix+"_LOGSTASH_USE_TLS") == "true"))
+	}
+	if apiKey := os.Getenv(prefix + "_NEW_RELIC_API_KEY"); apiKey != "" {
+		writers = append(writers, newNewRelicRemoteSyncWriter(apiKey, os.Getenv(prefix+"_NEW_RELIC_LOGS_ENDPOINT")))
+	}
+	return writers
+}
+
+// decodeLogEntries splits the payload of a single Write call into log
+// entries. The payload may hold one or more concatenated JSON objects, as
+// produced by the JSON encoder. Anything that is not a JSON object, such as
+// console-encoded or multiline output, is wrapped as {"message": "<raw>"}
+// so that it is still shipped instead of being rejected.
+func decodeLogEntries(p []byte) []map[string]interface{} {
+	var entries []map[string]interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(p))
+	offset := 0
+	for {
+		var logEntry map[string]interface{}
+		err := decoder.Decode(&logEntry)
+		if err != nil || logEntry == nil {
+			break
+		}
+		entries = append(entries, logEntry)
+		offset = int(decoder.InputOffset())
+	}
+
+	if raw := bytes.TrimSpace(p[offset:]); len(raw) > 0 {
+		entries = append(entries, map[string]interface{}{"message": string(raw)})
+	}
+	return entries
+}
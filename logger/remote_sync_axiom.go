@@ -0,0 +1,70 @@
+// sad-go-logger/logger/remote_sync_axiom.go
+
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sadco-io/sad-go-logger/logger/logfile"
+)
+
+// NewAxiomRemoteSyncWriter creates and returns a writer sending batches of
+// log entries to the Axiom ingest API as gzipped NDJSON. It reads
+// configuration from environment variables:
+//   - AXIOM_TOKEN: An API token with ingest permission on the dataset
+//   - AXIOM_DATASET: The dataset
+//   - AXIOM_ORG_ID: The organization, required with personal tokens only
+//   - AXIOM_URL: The API URL (default: "https://api.axiom.co")
+//
+// If AXIOM_TOKEN or AXIOM_DATASET is not set, it returns nil.
+func NewAxiomRemoteSyncWriter() RemoteSyncWriter {
+	token := os.Getenv("AXIOM_TOKEN")
+	dataset := os.Getenv("AXIOM_DATASET")
+	if token == "" || dataset == "" {
+		fmt.Println("AXIOM_TOKEN or AXIOM_DATASET not set. Axiom logging disabled.")
+		return nil
+	}
+	base := os.Getenv("AXIOM_URL")
+	if base == "" {
+		base = "https://api.axiom.co"
+	}
+
+	headers := map[string]string{"Authorization": "Bearer " + token}
+	if org := os.Getenv("AXIOM_ORG_ID"); org != "" {
+		headers["X-Axiom-Org-Id"] = org
+	}
+	return newHTTPBatchWriter(httpBatchConfig{
+		batchConfig: batchConfig{name: "Axiom"},
+		endpoint:    strings.TrimRight(base, "/") + "/v1/datasets/" + url.PathEscape(dataset) + "/ingest",
+		headers:     headers,
+		encode:      encodeAxiomEvents,
+		gzip:        true,
+	})
+}
+
+// encodeAxiomEvents renders a batch as NDJSON, the timestamp of the entries
+// moving to Axiom's _time field.
+func encodeAxiomEvents(entries []map[string]interface{}) ([]byte, string, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, entry := range entries {
+		event := make(map[string]interface{}, len(entry))
+		for key, value := range entry {
+			event[key] = value
+		}
+		if t, ok := logfile.Record(entry).Time(); ok {
+			event["_time"] = t.Format(time.RFC3339Nano)
+			delete(event, logfile.TimeKey)
+		}
+		if err := enc.Encode(event); err != nil {
+			return nil, "", err
+		}
+	}
+	return buf.Bytes(), "application/x-ndjson", nil
+}
@@ -0,0 +1,499 @@
+// sad-go-logger/logger/remote_sync_batch.go
+
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sadco-io/sad-go-logger/logger/logfile"
+	"go.uber.org/zap"
+)
+
+// batchConfig describes a destination for batchWriter.
+type batchConfig struct {
+	// name identifies the destination in warnings.
+	name string
+
+	// send delivers one batch.
+	send func(entries []map[string]interface{}) error
+
+	// prepare, when set, is called before the first batch is sent, and
+	// again after a batch failed, e.g. to create a table.
+	prepare func() error
+
+	// batchSize is the number of entries sent at once (default: 100).
+	batchSize int
+
+	// flushInterval bounds how long an entry stays buffered (default: 5s).
+	flushInterval time.Duration
+
+	// retry is the retry policy of a failed batch, see RetryPolicy; zero
+	// fields take the defaults.
+	retry RetryPolicy
+
+	// maxBuffer caps the buffered entries while the destination is
+	// unreachable; the oldest are dropped first (default: 100 batches).
+	maxBuffer int
+
+	// rateLimited is set when send waits for the rate limit, see
+	// batchWriter.waitRateLimit.
+	rateLimited bool
+}
+
+// permanentError marks the failure of a batch that retrying cannot fix,
+// such as a request rejected by the destination. The batch is dropped.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+// batchWriter buffers log entries and sends them in batches from a
+// background goroutine, retrying failed batches as its RetryPolicy says.
+// It is shared by the sinks that are not streams, such as HTTP ingestion
+// APIs and databases.
+type batchWriter struct {
+	cfg batchConfig
+
+	mu       sync.Mutex
+	retry    RetryPolicy
+	buffer   []map[string]interface{}
+	prepared bool
+	closed   bool
+
+	// limiter, when set, limits the batches sent, and the entries dropped
+	// from a full buffer are appended to spillPath, when set.
+	limiter   *rateLimiter
+	spillPath string
+
+	// dropped and spillFailed count the entries that overflowed the buffer
+	// since the last report, see reportDrops, and spillErr is the last
+	// failure to spill them.
+	dropped     int
+	spillFailed int
+	spillErr    error
+
+	// threshold, high, down and lastErr are the backpressure state, see
+	// Pressure.
+	threshold int
+	high      bool
+	down      bool
+	lastErr   error
+
+	// outage, when set, accounts for the entries of an outage, from the
+	// first failed batch until the queue drains after the recovery.
+	outage *outage
+
+	// sendMu serializes the batches.
+	sendMu sync.Mutex
+
+	flushCh chan struct{}
+	done    chan struct{}
+}
+
+// newBatchWriter creates the writer and starts its flush loop.
+func newBatchWriter(cfg batchConfig) *batchWriter {
+	if cfg.batchSize <= 0 {
+		cfg.batchSize = 100
+	}
+	if cfg.flushInterval <= 0 {
+		cfg.flushInterval = 5 * time.Second
+	}
+	cfg.retry = cfg.retry.withDefaults()
+	if cfg.maxBuffer <= 0 {
+		cfg.maxBuffer = 100 * cfg.batchSize
+	}
+
+	w := &batchWriter{
+		cfg:       cfg,
+		retry:     cfg.retry,
+		threshold: backpressureThreshold(),
+		flushCh:   make(chan struct{}, 1),
+		done:      make(chan struct{}),
+	}
+	trackPressure(w)
+	go w.flushLoop()
+	return w
+}
+
+// Write implements the io.Writer interface. It buffers the entries, see
+// decodeLogEntries, and wakes the flush loop once a batch is full.
+func (w *batchWriter) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	entries := decodeLogEntries(p)
+	w.buffer = append(w.buffer, entries...)
+	if w.outage != nil && w.outage.recovered.IsZero() {
+		w.outage.buffered += len(entries)
+	}
+	if over := len(w.buffer) - w.cfg.maxBuffer; over > 0 {
+		if w.outage != nil {
+			if w.spillPath != "" {
+				w.outage.spilled += over
+			} else {
+				w.outage.dropped += over
+			}
+		}
+		if w.spillPath != "" {
+			if err := spillEntries(w.spillPath, w.buffer[:over]); err != nil {
+				w.spillFailed += over
+				w.spillErr = err
+			}
+		} else {
+			w.dropped += over
+		}
+		w.buffer = append(w.buffer[:0], w.buffer[over:]...)
+	}
+	full := len(w.buffer) >= w.cfg.batchSize
+	pressure, changed := w.updatePressure(w.down, w.lastErr)
+	w.mu.Unlock()
+
+	if changed {
+		notifyPressure(pressure)
+	}
+
+	if full {
+		select {
+		case w.flushCh <- struct{}{}:
+		default:
+		}
+	}
+	return len(p), nil
+}
+
+func (w *batchWriter) flushLoop() {
+	ticker := time.NewTicker(w.cfg.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			w.reportDrops()
+		case <-w.flushCh:
+		}
+		if err := w.flush(); err != nil {
+			fmt.Printf("Failed to send logs to %s: %v\n", w.cfg.name, err)
+		}
+	}
+}
+
+// flush sends every buffered entry. Entries of a batch that still fails
+// after the retries are put back in the buffer, unless the failure is
+// permanent.
+func (w *batchWriter) flush() error {
+	w.sendMu.Lock()
+	defer w.sendMu.Unlock()
+
+	for {
+		w.mu.Lock()
+		n := min(len(w.buffer), w.cfg.batchSize)
+		batch := append([]map[string]interface{}(nil), w.buffer[:n]...)
+		w.buffer = w.buffer[n:]
+		if len(batch) == 0 {
+			pressure, changed := w.updatePressure(false, nil)
+			var recovered *outage
+			if w.outage != nil && !w.outage.recovered.IsZero() {
+				recovered, w.outage = w.outage, nil
+			}
+			w.mu.Unlock()
+			if changed {
+				notifyPressure(pressure)
+			}
+			if recovered != nil {
+				recovered.report(w.cfg.name)
+			}
+			return nil
+		}
+		w.mu.Unlock()
+
+		start := time.Now()
+		if err := w.sendWithRetry(batch); err != nil {
+			var permanent *permanentError
+			if errors.As(err, &permanent) {
+				fmt.Printf("%s rejected %d log entries: %v\n", w.cfg.name, len(batch), err)
+				continue
+			}
+			w.mu.Lock()
+			w.buffer = append(batch, w.buffer...)
+			if w.outage == nil {
+				w.outage = &outage{since: start, buffered: len(w.buffer)}
+			} else {
+				w.outage.recovered = time.Time{}
+			}
+			pressure, changed := w.updatePressure(true, err)
+			w.mu.Unlock()
+			if changed {
+				notifyPressure(pressure)
+			}
+			return err
+		}
+
+		w.mu.Lock()
+		if w.outage != nil {
+			if w.outage.recovered.IsZero() {
+				w.outage.recovered = time.Now()
+			}
+			w.outage.replayedBytes += batchSize(batch)
+		}
+		w.mu.Unlock()
+	}
+}
+
+// sendWithRetry sends batch, retrying as the policy says. A failure the
+// policy does not retry is returned as a permanentError.
+func (w *batchWriter) sendWithRetry(batch []map[string]interface{}) error {
+	w.mu.Lock()
+	policy := w.retry
+	w.mu.Unlock()
+
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		err := w.send(batch)
+		if err == nil {
+			return nil
+		}
+		if !policy.retryable(err) {
+			var permanent *permanentError
+			if errors.As(err, &permanent) {
+				return err
+			}
+			return &permanentError{err}
+		}
+		wait := policy.backoff(attempt)
+		if policy.exhausted(attempt, start, wait) {
+			return err
+		}
+		w.mu.Lock()
+		w.prepared = false
+		w.mu.Unlock()
+		time.Sleep(wait)
+	}
+}
+
+// setRetryPolicy implements retryingWriter.
+func (w *batchWriter) setRetryPolicy(p RetryPolicy) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.retry = p.withDefaults()
+}
+
+func (w *batchWriter) send(batch []map[string]interface{}) error {
+	w.mu.Lock()
+	prepared := w.prepared
+	w.mu.Unlock()
+	if !prepared && w.cfg.prepare != nil {
+		if err := w.cfg.prepare(); err != nil {
+			return err
+		}
+	}
+	w.mu.Lock()
+	w.prepared = true
+	w.mu.Unlock()
+
+	return w.cfg.send(batch)
+}
+
+// setBatchSize implements batchedWriter. The queue keeps room for at least
+// 100 batches.
+func (w *batchWriter) setBatchSize(n int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.cfg.batchSize = n
+	w.cfg.maxBuffer = max(w.cfg.maxBuffer, 100*n)
+}
+
+// setRateLimit implements rateLimitedWriter. Only the sinks whose send
+// waits for the limit accept one; every sink may spill.
+func (w *batchWriter) setRateLimit(l *rateLimiter, spillPath string) error {
+	if l != nil && !w.cfg.rateLimited {
+		return fmt.Errorf("%s does not support rate limits", w.cfg.name)
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.limiter, w.spillPath = l, spillPath
+	return nil
+}
+
+// waitRateLimit blocks until a request of size bytes may be sent. Batches
+// meanwhile accumulate in the buffer.
+func (w *batchWriter) waitRateLimit(size int) {
+	w.mu.Lock()
+	l := w.limiter
+	w.mu.Unlock()
+	if l != nil {
+		l.wait(size)
+	}
+}
+
+// Pressure returns the backpressure state of the writer.
+func (w *batchWriter) Pressure() Pressure {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.pressure()
+}
+
+// pressure returns the backpressure state. w.mu must be held.
+func (w *batchWriter) pressure() Pressure {
+	return Pressure{
+		Sink:     w.cfg.name,
+		Queued:   len(w.buffer),
+		Capacity: w.cfg.maxBuffer,
+		High:     w.high,
+		Down:     w.down,
+		Err:      w.lastErr,
+	}
+}
+
+// updatePressure sets the Down state and updates the High state after the
+// queue changed, and reports whether either changed. w.mu must be held.
+func (w *batchWriter) updatePressure(down bool, err error) (p Pressure, changed bool) {
+	changed = down != w.down
+	w.down, w.lastErr = down, err
+
+	fill := len(w.buffer) * 100
+	switch {
+	case !w.high && fill >= w.threshold*w.cfg.maxBuffer:
+		w.high, changed = true, true
+	case w.high && fill < w.threshold*w.cfg.maxBuffer/2:
+		w.high, changed = false, true
+	}
+	if !changed {
+		return Pressure{}, false
+	}
+	return w.pressure(), true
+}
+
+// Sync implements the zapcore.WriteSyncer interface. It sends every
+// buffered entry before returning.
+func (w *batchWriter) Sync() error {
+	return w.flush()
+}
+
+// Close sends the remaining entries and stops the flush loop.
+func (w *batchWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	close(w.done)
+	untrackPressure(w)
+	err := w.flush()
+	w.reportDrops()
+	return err
+}
+
+// reportDrops prints the entries that overflowed the buffer since the last
+// report, if any. It is called once per flush interval, so that a full
+// buffer does not print a line per write.
+func (w *batchWriter) reportDrops() {
+	w.mu.Lock()
+	dropped, spillFailed, spillErr := w.dropped, w.spillFailed, w.spillErr
+	w.dropped, w.spillFailed, w.spillErr = 0, 0, nil
+	w.mu.Unlock()
+
+	if dropped > 0 {
+		fmt.Printf("%s buffer full, dropped %d log entries\n", w.cfg.name, dropped)
+	}
+	if spillFailed > 0 {
+		fmt.Printf("%s buffer full, failed to spill %d log entries: %v\n", w.cfg.name, spillFailed, spillErr)
+	}
+}
+
+// outage accounts for the entries of a remote sink outage, reported once
+// the sink recovered and its queue drained.
+type outage struct {
+	// since is when the first failed batch was sent, and recovered when the
+	// first batch succeeded again, or zero while the sink is down.
+	since, recovered time.Time
+
+	// buffered counts the entries queued while the sink was down, and
+	// dropped and spilled those that overflowed the queue.
+	buffered, dropped, spilled int
+
+	// replayedBytes is the JSON size of the entries sent since the
+	// recovery.
+	replayedBytes int64
+}
+
+// report logs the summary of the outage of sink to every sink, at warn
+// level if entries were lost.
+func (o *outage) report(sink string) {
+	if Log == nil {
+		return
+	}
+	fields := []zap.Field{
+		zap.String("sink", sink),
+		zap.Duration("downtime", o.recovered.Sub(o.since)),
+		zap.Int("bufferedEntries", o.buffered),
+		zap.Int("droppedEntries", o.dropped),
+		zap.Int64("replayedBytes", o.replayedBytes),
+	}
+	if o.spilled > 0 {
+		fields = append(fields, zap.Int("spilledEntries", o.spilled))
+	}
+	if o.dropped > 0 {
+		Log.Warn("Remote sink recovered from an outage", fields...)
+	} else {
+		Log.Info("Remote sink recovered from an outage", fields...)
+	}
+}
+
+// batchSize returns the JSON size of batch.
+func batchSize(batch []map[string]interface{}) int64 {
+	var n int64
+	for _, entry := range batch {
+		if b, err := json.Marshal(entry); err == nil {
+			n += int64(len(b)) + 1
+		}
+	}
+	return n
+}
+
+// logRow is an entry split into the columns of the database sinks.
+type logRow struct {
+	Time    time.Time
+	Level   string
+	Service string
+	Host    string
+	Message string
+
+	// Fields holds the other keys of the entry.
+	Fields map[string]interface{}
+}
+
+// newLogRow splits entry. Entries without a parsable timestamp are stamped
+// with the current time.
+func newLogRow(entry map[string]interface{}) logRow {
+	row := logRow{Fields: map[string]interface{}{}}
+	t, ok := logfile.Record(entry).Time()
+	if !ok {
+		t = time.Now()
+	}
+	row.Time = t.UTC()
+	for key, value := range entry {
+		switch key {
+		case logfile.TimeKey:
+		case logfile.LevelKey:
+			row.Level = logfile.FormatValue(value)
+		case logfile.MessageKey:
+			row.Message = logfile.FormatValue(value)
+		case "serviceName":
+			row.Service = logfile.FormatValue(value)
+		case "hostname":
+			row.Host = logfile.FormatValue(value)
+		default:
+			row.Fields[key] = value
+		}
+	}
+	return row
+}
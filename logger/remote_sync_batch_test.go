@@ -0,0 +1,210 @@
+// sad-go-logger/logger/remote_sync_batch_test.go
+
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeDestination records the batches sent to it, failing the attempts for
+// which fail returns an error.
+type fakeDestination struct {
+	mu       sync.Mutex
+	attempts int
+	prepared int
+	batches  [][]map[string]interface{}
+	fail     func(attempt int) error
+}
+
+func (d *fakeDestination) send(batch []map[string]interface{}) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.attempts++
+	if d.fail != nil {
+		if err := d.fail(d.attempts); err != nil {
+			return err
+		}
+	}
+	d.batches = append(d.batches, batch)
+	return nil
+}
+
+func (d *fakeDestination) prepare() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.prepared++
+	return nil
+}
+
+func (d *fakeDestination) sent() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var messages []string
+	for _, batch := range d.batches {
+		for _, entry := range batch {
+			messages = append(messages, fmt.Sprint(entry["message"]))
+		}
+	}
+	return messages
+}
+
+// newTestBatchWriter returns a batchWriter sending to d that only flushes
+// when the test asks.
+func newTestBatchWriter(t *testing.T, d *fakeDestination, cfg batchConfig) *batchWriter {
+	t.Helper()
+	cfg.name = "test"
+	cfg.send = d.send
+	cfg.prepare = d.prepare
+	cfg.flushInterval = time.Hour
+	if cfg.batchSize == 0 {
+		cfg.batchSize = 100
+	}
+	cfg.retry.InitialBackoff = time.Millisecond
+	cfg.retry.MaxBackoff = time.Millisecond
+	w := newBatchWriter(cfg)
+	t.Cleanup(func() { w.Close() })
+	return w
+}
+
+func writeEntries(w *batchWriter, messages ...string) {
+	for _, msg := range messages {
+		fmt.Fprintf(w, "{\"message\":%q}\n", msg)
+	}
+}
+
+func TestBatchWriterRetriesFailedBatches(t *testing.T) {
+	d := &fakeDestination{fail: func(attempt int) error {
+		if attempt < 3 {
+			return errors.New("connection refused")
+		}
+		return nil
+	}}
+	w := newTestBatchWriter(t, d, batchConfig{retry: RetryPolicy{MaxAttempts: 4}})
+
+	writeEntries(w, "a", "b")
+	if err := w.Sync(); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if d.attempts != 3 {
+		t.Errorf("got %d attempts, want 3", d.attempts)
+	}
+	if got := d.sent(); fmt.Sprint(got) != "[a b]" {
+		t.Errorf("sent %v, want [a b]", got)
+	}
+	if d.prepared != 3 {
+		t.Errorf("prepare called %d times, want once per attempt after a failure: 3", d.prepared)
+	}
+}
+
+func TestBatchWriterKeepsBatchesUntilRecovery(t *testing.T) {
+	down := true
+	d := &fakeDestination{fail: func(int) error {
+		if down {
+			return errors.New("connection refused")
+		}
+		return nil
+	}}
+	w := newTestBatchWriter(t, d, batchConfig{retry: RetryPolicy{MaxAttempts: 2}})
+
+	writeEntries(w, "a", "b")
+	if err := w.Sync(); err == nil {
+		t.Fatal("Sync succeeded while the destination is down")
+	}
+	if d.attempts != 2 {
+		t.Errorf("got %d attempts, want MaxAttempts: 2", d.attempts)
+	}
+	if p := w.Pressure(); !p.Down || p.Queued != 2 || p.Err == nil {
+		t.Errorf("Pressure() = %+v, want down with 2 queued entries", p)
+	}
+
+	d.mu.Lock()
+	down = false
+	d.mu.Unlock()
+	writeEntries(w, "c")
+	if err := w.Sync(); err != nil {
+		t.Fatalf("Sync after recovery: %v", err)
+	}
+	if got := d.sent(); fmt.Sprint(got) != "[a b c]" {
+		t.Errorf("sent %v, want the kept batch first: [a b c]", got)
+	}
+	if p := w.Pressure(); p.Down || p.Queued != 0 {
+		t.Errorf("Pressure() = %+v, want up and empty", p)
+	}
+}
+
+func TestBatchWriterDropsPermanentFailures(t *testing.T) {
+	d := &fakeDestination{fail: func(attempt int) error {
+		if attempt == 1 {
+			return &statusError{code: 400, err: errors.New("bad request")}
+		}
+		return nil
+	}}
+	w := newTestBatchWriter(t, d, batchConfig{batchSize: 2, retry: RetryPolicy{MaxAttempts: 4}})
+
+	writeEntries(w, "a", "b", "c")
+	if err := w.Sync(); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if got := d.sent(); fmt.Sprint(got) != "[c]" {
+		t.Errorf("sent %v, want the rejected batch dropped: [c]", got)
+	}
+	if d.attempts != 2 {
+		t.Errorf("got %d attempts, want the rejected batch tried once: 2", d.attempts)
+	}
+}
+
+func TestBatchWriterRetryDeadline(t *testing.T) {
+	d := &fakeDestination{fail: func(int) error { return errors.New("timeout") }}
+	w := newTestBatchWriter(t, d, batchConfig{retry: RetryPolicy{MaxAttempts: -1, Deadline: 20 * time.Millisecond}})
+
+	writeEntries(w, "a")
+	start := time.Now()
+	if err := w.Sync(); err == nil {
+		t.Fatal("Sync succeeded while every attempt fails")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Sync took %v, want it bounded by the deadline", elapsed)
+	}
+	if d.attempts < 2 {
+		t.Errorf("got %d attempts, want retries until the deadline", d.attempts)
+	}
+}
+
+func TestBatchWriterMaxBuffer(t *testing.T) {
+	d := &fakeDestination{}
+	w := newTestBatchWriter(t, d, batchConfig{maxBuffer: 3})
+
+	writeEntries(w, "a", "b", "c", "d", "e")
+	w.mu.Lock()
+	queued, dropped := len(w.buffer), w.dropped
+	w.mu.Unlock()
+	if queued != 3 || dropped != 2 {
+		t.Errorf("got %d queued and %d dropped entries, want 3 and 2", queued, dropped)
+	}
+
+	w.reportDrops()
+	if w.dropped != 0 {
+		t.Errorf("reportDrops left %d dropped entries, want 0", w.dropped)
+	}
+
+	if err := w.Sync(); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if got := d.sent(); fmt.Sprint(got) != "[c d e]" {
+		t.Errorf("sent %v, want the oldest dropped first: [c d e]", got)
+	}
+}
+
+func TestBatchWriterSetBatchSizeKeepsRoom(t *testing.T) {
+	d := &fakeDestination{}
+	w := newTestBatchWriter(t, d, batchConfig{batchSize: 10})
+
+	w.setBatchSize(50)
+	if w.cfg.maxBuffer != 5000 {
+		t.Errorf("maxBuffer = %d, want room for 100 batches: 5000", w.cfg.maxBuffer)
+	}
+}
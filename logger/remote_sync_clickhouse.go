@@ -0,0 +1,154 @@
+// sad-go-logger/logger/remote_sync_clickhouse.go
+
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sadco-io/sad-go-logger/logger/logfile"
+)
+
+// clickHouseIdentifier matches the database and table names accepted by
+// the ClickHouse sink.
+var clickHouseIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// clickHouseWriter sends entries to the ClickHouse HTTP interface.
+type clickHouseWriter struct {
+	*batchWriter
+
+	base     string
+	database string
+	table    string
+	user     string
+	password string
+	client   *http.Client
+}
+
+// NewClickHouseRemoteSyncWriter creates and returns a writer inserting log
+// entries into ClickHouse through its HTTP interface, with asynchronous
+// inserts. The logs table is created if it does not exist, with the
+// timestamp, level, service, host and message columns and the other
+// fields in a Map(String, String) column. It reads configuration from
+// environment variables:
+//   - CLICKHOUSE_URL: The HTTP interface URL (default: "http://localhost:8123")
+//   - CLICKHOUSE_USER and CLICKHOUSE_PASSWORD: Credentials (optional)
+//   - CLICKHOUSE_DATABASE: The database (default: "default")
+//   - CLICKHOUSE_TABLE: The table (default: "logs")
+//
+// It returns nil if the database or table name is invalid.
+func NewClickHouseRemoteSyncWriter() RemoteSyncWriter {
+	base := os.Getenv("CLICKHOUSE_URL")
+	if base == "" {
+		base = "http://localhost:8123"
+	}
+	database := os.Getenv("CLICKHOUSE_DATABASE")
+	if database == "" {
+		database = "default"
+	}
+	table := os.Getenv("CLICKHOUSE_TABLE")
+	if table == "" {
+		table = "logs"
+	}
+	if !clickHouseIdentifier.MatchString(database) || !clickHouseIdentifier.MatchString(table) {
+		fmt.Println("Invalid CLICKHOUSE_DATABASE or CLICKHOUSE_TABLE. ClickHouse logging disabled.")
+		return nil
+	}
+
+	w := &clickHouseWriter{
+		base:     strings.TrimRight(base, "/"),
+		database: database,
+		table:    table,
+		user:     os.Getenv("CLICKHOUSE_USER"),
+		password: os.Getenv("CLICKHOUSE_PASSWORD"),
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+
+	insert := fmt.Sprintf("INSERT INTO %s.%s FORMAT JSONEachRow", database, table)
+	headers := map[string]string{}
+	if w.user != "" {
+		headers["X-ClickHouse-User"] = w.user
+		headers["X-ClickHouse-Key"] = w.password
+	}
+	w.batchWriter = newHTTPBatchWriter(httpBatchConfig{
+		batchConfig: batchConfig{name: "ClickHouse", prepare: w.createTable},
+		endpoint:    w.url(insert, "async_insert=1", "wait_for_async_insert=0", "date_time_input_format=best_effort"),
+		headers:     headers,
+		encode:      encodeClickHouseRows,
+		gzip:        true,
+	})
+	return w
+}
+
+// url returns the HTTP interface URL running query with the given settings.
+func (w *clickHouseWriter) url(query string, settings ...string) string {
+	u := w.base + "/?query=" + url.QueryEscape(query)
+	for _, s := range settings {
+		u += "&" + s
+	}
+	return u
+}
+
+// createTable creates the logs table if it does not exist.
+func (w *clickHouseWriter) createTable() error {
+	ddl := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s.%s (
+	timestamp DateTime64(3, 'UTC'),
+	level LowCardinality(String),
+	service LowCardinality(String),
+	host LowCardinality(String),
+	message String,
+	fields Map(String, String)
+) ENGINE = MergeTree
+PARTITION BY toDate(timestamp)
+ORDER BY (service, timestamp)`, w.database, w.table)
+
+	req, err := http.NewRequest("POST", w.base+"/", strings.NewReader(ddl))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	if w.user != "" {
+		req.Header.Set("X-ClickHouse-User", w.user)
+		req.Header.Set("X-ClickHouse-Key", w.password)
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to create ClickHouse table: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to create ClickHouse table: status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// encodeClickHouseRows renders a batch as JSONEachRow.
+func encodeClickHouseRows(entries []map[string]interface{}) ([]byte, string, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, entry := range entries {
+		row := newLogRow(entry)
+		fields := make(map[string]string, len(row.Fields))
+		for key, value := range row.Fields {
+			fields[key] = logfile.FormatValue(value)
+		}
+		err := enc.Encode(map[string]interface{}{
+			"timestamp": row.Time.Format("2006-01-02 15:04:05.000"),
+			"level":     row.Level,
+			"service":   row.Service,
+			"host":      row.Host,
+			"message":   row.Message,
+			"fields":    fields,
+		})
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	return buf.Bytes(), "application/x-ndjson", nil
+}
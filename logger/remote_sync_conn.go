@@ -0,0 +1,96 @@
+// sad-go-logger/logger/remote_sync_conn.go
+
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// connConfig describes a stream destination, such as a syslog server, for
+// newConnWriter.
+type connConfig struct {
+	batchConfig
+
+	// dial opens the connection.
+	dial func() (net.Conn, error)
+
+	// frame renders one entry as it is written to the connection.
+	frame func(entry map[string]interface{}) ([]byte, error)
+}
+
+// connWriter writes batches of entries to a connection, opened before the
+// first batch and again after a failed one, so that a lost connection is
+// reestablished with the backoff of batchWriter while entries are buffered.
+type connWriter struct {
+	*batchWriter
+
+	cfg connConfig
+
+	// mu guards conn, which is only used by the flushing goroutine
+	// otherwise.
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// newConnWriter creates the writer; cfg.send and cfg.prepare are ignored.
+func newConnWriter(cfg connConfig) *connWriter {
+	w := &connWriter{cfg: cfg}
+	cfg.batchConfig.prepare = w.connect
+	cfg.batchConfig.send = w.send
+	w.batchWriter = newBatchWriter(cfg.batchConfig)
+	return w
+}
+
+// connect replaces the connection.
+func (w *connWriter) connect() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn != nil {
+		w.conn.Close()
+		w.conn = nil
+	}
+	conn, err := w.cfg.dial()
+	if err != nil {
+		return fmt.Errorf("failed to connect: %v", err)
+	}
+	w.conn = conn
+	return nil
+}
+
+func (w *connWriter) send(entries []map[string]interface{}) error {
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		frame, err := w.cfg.frame(entry)
+		if err != nil {
+			return &permanentError{fmt.Errorf("failed to encode log entry: %v", err)}
+		}
+		buf.Write(frame)
+	}
+
+	w.mu.Lock()
+	conn := w.conn
+	w.mu.Unlock()
+	if conn == nil {
+		return fmt.Errorf("not connected")
+	}
+	conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	_, err := conn.Write(buf.Bytes())
+	return err
+}
+
+// Close sends the remaining entries and closes the connection.
+func (w *connWriter) Close() error {
+	err := w.batchWriter.Close()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn != nil {
+		w.conn.Close()
+		w.conn = nil
+	}
+	return err
+}
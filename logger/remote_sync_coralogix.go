@@ -0,0 +1,111 @@
+// sad-go-logger/logger/remote_sync_coralogix.go
+
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sadco-io/sad-go-logger/logger/logfile"
+)
+
+// coralogixSeverities maps the entry levels to Coralogix severities.
+var coralogixSeverities = map[string]int{
+	"DEBUG":  1,
+	"INFO":   3,
+	"WARN":   4,
+	"ERROR":  5,
+	"DPANIC": 6,
+	"PANIC":  6,
+	"FATAL":  6,
+}
+
+// coralogixLog is one log of the Coralogix singles API.
+type coralogixLog struct {
+	ApplicationName string  `json:"applicationName"`
+	SubsystemName   string  `json:"subsystemName"`
+	ComputerName    string  `json:"computerName,omitempty"`
+	Timestamp       float64 `json:"timestamp"`
+	Severity        int     `json:"severity"`
+	Text            string  `json:"text"`
+}
+
+// NewCoralogixRemoteSyncWriter creates and returns a writer sending batches
+// of log entries to the Coralogix ingestion API. The application name of
+// each is its serviceName and the subsystem name its component field; the
+// text is the entry as JSON. It reads configuration from environment
+// variables:
+//   - CORALOGIX_PRIVATE_KEY: The Send-Your-Data API key
+//   - CORALOGIX_DOMAIN: The domain of the account (default: "coralogix.com"),
+//     e.g. "eu2.coralogix.com"
+//   - CORALOGIX_APPLICATION_NAME: Overrides the application name
+//   - CORALOGIX_SUBSYSTEM_NAME: The subsystem name of entries without a
+//     component field (default: "default")
+//
+// If CORALOGIX_PRIVATE_KEY is not set, it returns nil.
+func NewCoralogixRemoteSyncWriter() RemoteSyncWriter {
+	key := os.Getenv("CORALOGIX_PRIVATE_KEY")
+	if key == "" {
+		fmt.Println("CORALOGIX_PRIVATE_KEY not set. Coralogix logging disabled.")
+		return nil
+	}
+	domain := os.Getenv("CORALOGIX_DOMAIN")
+	if domain == "" {
+		domain = "coralogix.com"
+	}
+	application := os.Getenv("CORALOGIX_APPLICATION_NAME")
+	subsystem := os.Getenv("CORALOGIX_SUBSYSTEM_NAME")
+	if subsystem == "" {
+		subsystem = "default"
+	}
+
+	return newHTTPBatchWriter(httpBatchConfig{
+		batchConfig: batchConfig{name: "Coralogix"},
+		endpoint:    "https://ingress." + domain + "/logs/v1/singles",
+		headers:     map[string]string{"Authorization": "Bearer " + key},
+		encode: func(entries []map[string]interface{}) ([]byte, string, error) {
+			return encodeCoralogixLogs(entries, application, subsystem)
+		},
+		gzip: true,
+	})
+}
+
+// encodeCoralogixLogs renders a batch as the JSON array of the singles API.
+func encodeCoralogixLogs(entries []map[string]interface{}, application, subsystem string) ([]byte, string, error) {
+	logs := make([]coralogixLog, len(entries))
+	for i, entry := range entries {
+		rec := logfile.Record(entry)
+		text, err := json.Marshal(entry)
+		if err != nil {
+			return nil, "", err
+		}
+		log := coralogixLog{
+			ApplicationName: application,
+			SubsystemName:   subsystem,
+			Text:            string(text),
+		}
+		if log.ApplicationName == "" {
+			log.ApplicationName, _ = rec.Field("serviceName")
+		}
+		if log.ApplicationName == "" {
+			log.ApplicationName = "default"
+		}
+		if component, ok := rec.Field("component"); ok && component != "" {
+			log.SubsystemName = component
+		}
+		log.ComputerName, _ = rec.Field("hostname")
+		t, ok := rec.Time()
+		if !ok {
+			t = time.Now()
+		}
+		log.Timestamp = float64(t.UnixMicro()) / 1000
+		if log.Severity = coralogixSeverities[logfile.FormatValue(entry[logfile.LevelKey])]; log.Severity == 0 {
+			log.Severity = 3
+		}
+		logs[i] = log
+	}
+	body, err := json.Marshal(logs)
+	return body, "application/json", err
+}
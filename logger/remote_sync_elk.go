@@ -1,206 +1,285 @@
-// sad-go-logger/logger/remotesync_elk.go
-
-// Package logger provides functionality for logging to various outputs,
-// including remote ELK (Elasticsearch, Logstash, Kibana) stacks.
-package logger
-
-import (
-	"bytes"
-	"crypto/tls"
-	"encoding/json"
-	"fmt"
-	"net"
-	"os"
-	"sync"
-	"time"
-)
-
-// ELKRemoteSyncWriter implements a writer that sends log entries to a remote
-// Logstash instance. It supports batching, buffering, automatic reconnection,
-// and optional TLS encryption.
-type ELKRemoteSyncWriter struct {
-	// host is the hostname or IP address of the Logstash server.
-	host string
-
-	// port is the port number on which the Logstash server is listening.
-	port string
-
-	// useTLS indicates whether to use TLS encryption for the connection.
-	// If true, the connection will be established using TLS.
-	useTLS bool
-
-	// conn is the network connection to the Logstash server.
-	// It may be nil if the connection is not currently established.
-	conn net.Conn
-
-	// encoder is used to JSON-encode log entries before sending them to Logstash.
-	// It is initialized when a connection is established.
-	encoder *json.Encoder
-
-	// mu is used to ensure thread-safety when accessing shared resources.
-	mu sync.Mutex
-
-	// buffer holds log entries that haven't been sent to Logstash yet.
-	// This allows for batching of log entries and helps handle temporary connection issues.
-	buffer []map[string]interface{}
-
-	// batchSize is the number of log entries to accumulate before sending them to Logstash.
-	// When the buffer reaches this size, it will be flushed to Logstash.
-	batchSize int
-
-	// reconnectInterval is the duration to wait between connection attempts
-	// when the connection to Logstash is lost.
-	reconnectInterval time.Duration
-}
-
-// NewRemoteSyncWriter creates and returns a new ELKRemoteSyncWriter.
-// It reads configuration from environment variables:
-//   - LOGSTASH_HOST: The hostname of the Logstash server
-//   - LOGSTASH_PORT: The port number of the Logstash server
-//   - LOGSTASH_USE_TLS: Set to "true" to enable TLS encryption
-//
-// If LOGSTASH_HOST or LOGSTASH_PORT are not set, it returns nil.
-func NewRemoteSyncWriter() RemoteSyncWriter {
-	host := os.Getenv("LOGSTASH_HOST")
-	port := os.Getenv("LOGSTASH_PORT")
-	useTLS := os.Getenv("LOGSTASH_USE_TLS") == "true"
-	batchSize := 100                     // Default batch size, can be made configurable
-	reconnectInterval := 5 * time.Second // Default reconnect interval, can be made configurable
-
-	if host == "" || port == "" {
-		fmt.Println("LOGSTASH_HOST or LOGSTASH_PORT not set. Remote sync disabled.")
-		return nil
-	}
-
-	writer := &ELKRemoteSyncWriter{
-		host:              host,
-		port:              port,
-		useTLS:            useTLS,
-		buffer:            make([]map[string]interface{}, 0, batchSize),
-		batchSize:         batchSize,
-		reconnectInterval: reconnectInterval,
-	}
-
-	if err := writer.connect(); err != nil {
-		fmt.Printf("Failed to connect to Logstash: %v. Will retry later.\n", err)
-	}
-
-	go writer.reconnectionLoop()
-
-	return writer
-}
-
-// connect establishes a connection to the Logstash server.
-// It uses TLS if configured to do so.
-func (w *ELKRemoteSyncWriter) connect() error {
-	w.mu.Lock()
-	defer w.mu.Unlock()
-
-	if w.conn != nil {
-		w.conn.Close()
-	}
-
-	var conn net.Conn
-	var err error
-
-	address := fmt.Sprintf("%s:%s", w.host, w.port)
-
-	if w.useTLS {
-		config := &tls.Config{
-			InsecureSkipVerify: true, // Note: This should be configurable in production
-		}
-		conn, err = tls.Dial("tcp", address, config)
-	} else {
-		conn, err = net.Dial("tcp", address)
-	}
-
-	if err != nil {
-		return err
-	}
-
-	w.conn = conn
-	w.encoder = json.NewEncoder(conn)
-	return nil
-}
-
-// reconnectionLoop continuously attempts to reconnect to Logstash
-// if the connection is lost.
-func (w *ELKRemoteSyncWriter) reconnectionLoop() {
-	for {
-		time.Sleep(w.reconnectInterval)
-		if w.conn == nil {
-			if err := w.connect(); err != nil {
-				fmt.Printf("Failed to reconnect to Logstash: %v. Will retry later.\n", err)
-			} else {
-				fmt.Println("Successfully reconnected to Logstash.")
-				w.flushBuffer()
-			}
-		}
-	}
-}
-
-// Write implements the io.Writer interface.
-// It adds the log entry to the buffer and flushes if the batch size is reached.
-func (w *ELKRemoteSyncWriter) Write(p []byte) (n int, err error) {
-	w.mu.Lock()
-	defer w.mu.Unlock()
-
-	var logEntry map[string]interface{}
-	decoder := json.NewDecoder(bytes.NewReader(p))
-	if err := decoder.Decode(&logEntry); err != nil {
-		return 0, fmt.Errorf("failed to decode log entry: %v", err)
-	}
-
-	// Add additional fields for ELK
-	logEntry["@timestamp"] = time.Now().UTC().Format(time.RFC3339Nano)
-	logEntry["@version"] = "1"
-
-	w.buffer = append(w.buffer, logEntry)
-
-	if len(w.buffer) >= w.batchSize {
-		w.flushBuffer()
-	}
-
-	return len(p), nil
-}
-
-// flushBuffer sends all buffered log entries to Logstash.
-// If the connection is not available, it keeps the entries in the buffer.
-func (w *ELKRemoteSyncWriter) flushBuffer() {
-	if w.conn == nil {
-		return // Connection is not available, keep buffering
-	}
-
-	for _, entry := range w.buffer {
-		if err := w.encoder.Encode(entry); err != nil {
-			fmt.Printf("Failed to encode log entry for ELK: %v\n", err)
-			w.conn = nil // Mark connection as failed
-			return
-		}
-	}
-
-	w.buffer = w.buffer[:0] // Clear the buffer
-}
-
-// Sync implements the zapcore.WriteSyncer interface.
-// It flushes the buffer to ensure all logs are sent.
-func (w *ELKRemoteSyncWriter) Sync() error {
-	w.mu.Lock()
-	defer w.mu.Unlock()
-
-	w.flushBuffer()
-	return nil
-}
-
-// Close flushes any remaining logs and closes the connection to Logstash.
-func (w *ELKRemoteSyncWriter) Close() error {
-	w.mu.Lock()
-	defer w.mu.Unlock()
-
-	w.flushBuffer() // Attempt to flush any remaining logs
-
-	if w.conn != nil {
-		return w.conn.Close()
-	}
-	return nil
-}
+// sad-go-logger/logger/remotesync_elk.go
+
+// Package logger provides functionality for logging to various outputs,
+// including remote ELK (Elasticsearch, Logstash, Kibana) stacks.
+package logger
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// ELKRemoteSyncWriter implements a writer that sends log entries to a remote
+// Logstash instance. It supports batching, buffering, automatic reconnection,
+// and optional TLS encryption.
+type ELKRemoteSyncWriter struct {
+	// host is the hostname or IP address of the Logstash server.
+	host string
+
+	// port is the port number on which the Logstash server is listening.
+	port string
+
+	// useTLS indicates whether to use TLS encryption for the connection.
+	// If true, the connection will be established using TLS.
+	useTLS bool
+
+	// conn is the network connection to the Logstash server.
+	// It may be nil if the connection is not currently established.
+	conn net.Conn
+
+	// encoder is used to JSON-encode log entries before sending them to Logstash.
+	// It is initialized when a connection is established.
+	encoder *json.Encoder
+
+	// mu is used to ensure thread-safety when accessing shared resources.
+	mu sync.Mutex
+
+	// buffer holds log entries that haven't been sent to Logstash yet.
+	// This allows for batching of log entries and helps handle temporary connection issues.
+	buffer []map[string]interface{}
+
+	// batchSize is the number of log entries to accumulate before sending them to Logstash.
+	// When the buffer reaches this size, it will be flushed to Logstash.
+	batchSize int
+
+	// retry paces the connection attempts when the connection to Logstash is
+	// lost, and bounds how long the buffered entries wait for it. attempts
+	// counts the failures since failedAt.
+	retry    RetryPolicy
+	attempts int
+	failedAt time.Time
+
+	// done is closed by Close to stop the reconnection loop.
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// elkRetryPolicy is the default policy of the ELK sink: reconnect every 5
+// seconds, keeping the buffered entries until Logstash is back.
+var elkRetryPolicy = RetryPolicy{MaxAttempts: -1, InitialBackoff: 5 * time.Second, MaxBackoff: 5 * time.Second}
+
+// NewRemoteSyncWriter creates and returns a new ELKRemoteSyncWriter.
+// It reads configuration from environment variables:
+//   - LOGSTASH_HOST: The hostname of the Logstash server
+//   - LOGSTASH_PORT: The port number of the Logstash server
+//   - LOGSTASH_USE_TLS: Set to "true" to enable TLS encryption
+//
+// If LOGSTASH_HOST or LOGSTASH_PORT are not set, it returns nil.
+func NewRemoteSyncWriter() RemoteSyncWriter {
+	host := os.Getenv("LOGSTASH_HOST")
+	port := os.Getenv("LOGSTASH_PORT")
+	useTLS := os.Getenv("LOGSTASH_USE_TLS") == "true"
+
+	if host == "" || port == "" {
+		fmt.Println("LOGSTASH_HOST or LOGSTASH_PORT not set. Remote sync disabled.")
+		return nil
+	}
+
+	return newELKRemoteSyncWriter(host, port, useTLS)
+}
+
+// newELKRemoteSyncWriter creates a writer for the Logstash server at host
+// and port and starts its reconnection loop.
+func newELKRemoteSyncWriter(host, port string, useTLS bool) *ELKRemoteSyncWriter {
+	batchSize := 100 // Default batch size, can be made configurable
+
+	writer := &ELKRemoteSyncWriter{
+		host:      host,
+		port:      port,
+		useTLS:    useTLS,
+		buffer:    make([]map[string]interface{}, 0, batchSize),
+		batchSize: batchSize,
+		retry:     elkRetryPolicy,
+		done:      make(chan struct{}),
+	}
+
+	if err := writer.connect(); err != nil {
+		fmt.Printf("Failed to connect to Logstash: %v. Will retry later.\n", err)
+	}
+
+	go writer.reconnectionLoop()
+
+	return writer
+}
+
+// connect establishes a connection to the Logstash server.
+// It uses TLS if configured to do so.
+func (w *ELKRemoteSyncWriter) connect() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	select {
+	case <-w.done:
+		return errors.New("writer closed")
+	default:
+	}
+
+	if w.conn != nil {
+		w.conn.Close()
+	}
+
+	var conn net.Conn
+	var err error
+
+	address := net.JoinHostPort(w.host, w.port)
+
+	if w.useTLS {
+		config := &tls.Config{
+			InsecureSkipVerify: true, // Note: This should be configurable in production
+		}
+		conn, err = tls.Dial("tcp", address, config)
+	} else {
+		conn, err = net.Dial("tcp", address)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	w.conn = conn
+	w.encoder = json.NewEncoder(conn)
+	return nil
+}
+
+// reconnectionLoop continuously attempts to reconnect to Logstash
+// if the connection is lost, backing off as the retry policy says, until
+// the writer is closed.
+func (w *ELKRemoteSyncWriter) reconnectionLoop() {
+	for {
+		w.mu.Lock()
+		wait := w.retry.backoff(max(w.attempts, 1))
+		w.mu.Unlock()
+		timer := time.NewTimer(wait)
+		select {
+		case <-w.done:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		w.mu.Lock()
+		connected := w.conn != nil
+		w.mu.Unlock()
+		if !connected {
+			if err := w.connect(); err != nil {
+				fmt.Printf("Failed to reconnect to Logstash: %v. Will retry later.\n", err)
+				w.mu.Lock()
+				w.failed(err)
+				w.mu.Unlock()
+			} else {
+				fmt.Println("Successfully reconnected to Logstash.")
+				w.mu.Lock()
+				w.flushBuffer()
+				w.mu.Unlock()
+			}
+		}
+	}
+}
+
+// failed records a failed attempt to deliver the buffer. The buffer is
+// dropped once the retry policy gives up on it. w.mu must be held.
+func (w *ELKRemoteSyncWriter) failed(err error) {
+	if w.attempts == 0 {
+		w.failedAt = time.Now()
+	}
+	w.attempts++
+	if w.retry.retryable(err) && !w.retry.exhausted(w.attempts, w.failedAt, w.retry.backoff(w.attempts)) {
+		return
+	}
+	if len(w.buffer) > 0 {
+		fmt.Printf("ELK dropped %d log entries after %d attempts: %v\n", len(w.buffer), w.attempts, err)
+	}
+	w.buffer = w.buffer[:0]
+	w.attempts = 0
+}
+
+// setBatchSize implements batchedWriter.
+func (w *ELKRemoteSyncWriter) setBatchSize(n int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.batchSize = n
+}
+
+// setRetryPolicy implements retryingWriter.
+func (w *ELKRemoteSyncWriter) setRetryPolicy(p RetryPolicy) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.retry = p.withDefaults()
+}
+
+// Write implements the io.Writer interface.
+// It adds the log entries to the buffer and flushes if the batch size is reached.
+// Payloads that are not JSON objects are wrapped, see decodeLogEntries.
+func (w *ELKRemoteSyncWriter) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, logEntry := range decodeLogEntries(p) {
+		// Add additional fields for ELK, keeping the original timestamp of
+		// replayed entries
+		if _, ok := logEntry["@timestamp"]; !ok {
+			logEntry["@timestamp"] = time.Now().UTC().Format(time.RFC3339Nano)
+		}
+		logEntry["@version"] = "1"
+
+		w.buffer = append(w.buffer, logEntry)
+	}
+
+	if len(w.buffer) >= w.batchSize {
+		w.flushBuffer()
+	}
+
+	return len(p), nil
+}
+
+// flushBuffer sends all buffered log entries to Logstash.
+// If the connection is not available, it keeps the entries in the buffer.
+func (w *ELKRemoteSyncWriter) flushBuffer() {
+	if w.conn == nil {
+		return // Connection is not available, keep buffering
+	}
+
+	for _, entry := range w.buffer {
+		if err := w.encoder.Encode(entry); err != nil {
+			fmt.Printf("Failed to encode log entry for ELK: %v\n", err)
+			w.conn = nil // Mark connection as failed
+			w.failed(err)
+			return
+		}
+	}
+
+	w.buffer = w.buffer[:0] // Clear the buffer
+	w.attempts = 0
+}
+
+// Sync implements the zapcore.WriteSyncer interface.
+// It flushes the buffer to ensure all logs are sent.
+func (w *ELKRemoteSyncWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.flushBuffer()
+	return nil
+}
+
+// Close flushes any remaining logs and closes the connection to Logstash.
+func (w *ELKRemoteSyncWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.flushBuffer() // Attempt to flush any remaining logs
+	w.closeOnce.Do(func() { close(w.done) })
+
+	if w.conn != nil {
+		err := w.conn.Close()
+		w.conn = nil
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+// sad-go-logger/logger/remote_sync_failover.go
+
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// FailoverPolicy configures a FailoverWriter.
+type FailoverPolicy struct {
+	// CheckInterval is how often the health of the primary is checked
+	// (default: 5s).
+	CheckInterval time.Duration
+
+	// FailureThreshold is the number of consecutive failed checks or
+	// writes after which the primary is unhealthy (default: 1).
+	FailureThreshold int
+
+	// HealthCheck probes the primary, e.g. by calling the health endpoint
+	// of its destination. It defaults to the backpressure state of the
+	// built-in batching sinks, failing while Pressure().Down is set, and is
+	// required for other primaries.
+	HealthCheck func() error
+
+	// Replay, when set, writes the entries shipped to the secondary during
+	// an outage to the primary once it recovers, so that the primary
+	// destination ends up complete.
+	Replay bool
+
+	// MaxReplay caps the writes kept for Replay; the oldest are dropped
+	// first (default: 10000).
+	MaxReplay int
+}
+
+// FailoverWriter ships entries to a primary writer and, only while the
+// primary is unhealthy, to a secondary one, e.g. for dual-region ingestion.
+// Create it with NewFailoverWriter.
+type FailoverWriter struct {
+	primary, secondary RemoteSyncWriter
+	policy             FailoverPolicy
+
+	mu       sync.Mutex
+	healthy  bool
+	failures int
+	replay   [][]byte
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewFailoverWriter returns a writer shipping to primary while it is
+// healthy and to secondary while it is not. The health of the primary is
+// checked periodically, see FailoverPolicy; on recovery, entries go to the
+// primary again, after the replay of the outage when enabled. It panics if
+// policy has no HealthCheck and primary does not report its backpressure.
+func NewFailoverWriter(primary, secondary RemoteSyncWriter, policy FailoverPolicy) *FailoverWriter {
+	if policy.CheckInterval <= 0 {
+		policy.CheckInterval = 5 * time.Second
+	}
+	if policy.FailureThreshold <= 0 {
+		policy.FailureThreshold = 1
+	}
+	if policy.HealthCheck == nil {
+		reporter, ok := primary.(pressureReporter)
+		if !ok {
+			panic(fmt.Sprintf("logger: FailoverPolicy.HealthCheck is required for a primary of type %T", primary))
+		}
+		policy.HealthCheck = pressureHealthCheck(reporter)
+	}
+	if policy.MaxReplay <= 0 {
+		policy.MaxReplay = 10000
+	}
+
+	w := &FailoverWriter{
+		primary:   primary,
+		secondary: secondary,
+		policy:    policy,
+		healthy:   true,
+		done:      make(chan struct{}),
+	}
+	go w.checkLoop()
+	return w
+}
+
+// PrimaryHealthy reports whether entries currently go to the primary.
+func (w *FailoverWriter) PrimaryHealthy() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.healthy
+}
+
+// Write implements the io.Writer interface.
+func (w *FailoverWriter) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	healthy := w.healthy
+	if !healthy && w.policy.Replay {
+		w.replay = append(w.replay, append([]byte(nil), p...))
+		if over := len(w.replay) - w.policy.MaxReplay; over > 0 {
+			w.replay = append(w.replay[:0], w.replay[over:]...)
+		}
+	}
+	w.mu.Unlock()
+
+	if healthy {
+		_, err := w.primary.Write(p)
+		if err == nil {
+			return len(p), nil
+		}
+		w.recordFailure(err)
+	}
+	return w.secondary.Write(p)
+}
+
+// Sync implements the zapcore.WriteSyncer interface. It syncs the
+// destination currently in use.
+func (w *FailoverWriter) Sync() error {
+	if w.PrimaryHealthy() {
+		if err := w.primary.Sync(); err != nil {
+			w.recordFailure(err)
+			return err
+		}
+		return nil
+	}
+	return w.secondary.Sync()
+}
+
+// Close stops the health checks and closes both writers, when they
+// implement io.Closer.
+func (w *FailoverWriter) Close() error {
+	w.closeOnce.Do(func() { close(w.done) })
+	var errs []error
+	for _, writer := range []RemoteSyncWriter{w.primary, w.secondary} {
+		if closer, ok := writer.(io.Closer); ok {
+			errs = append(errs, closer.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// pressureHealthCheck returns a health check failing while the last batch
+// of r could not be delivered.
+func pressureHealthCheck(r pressureReporter) func() error {
+	return func() error {
+		p := r.Pressure()
+		if !p.Down {
+			return nil
+		}
+		if p.Err != nil {
+			return p.Err
+		}
+		return fmt.Errorf("%s is down", p.Sink)
+	}
+}
+
+func (w *FailoverWriter) checkLoop() {
+	ticker := time.NewTicker(w.policy.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+		}
+		if err := w.policy.HealthCheck(); err != nil {
+			w.recordFailure(err)
+		} else {
+			w.recover()
+		}
+	}
+}
+
+// recordFailure counts a failure of the primary, failing over at the
+// threshold.
+func (w *FailoverWriter) recordFailure(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.failures++
+	if w.healthy && w.failures >= w.policy.FailureThreshold {
+		w.healthy = false
+		fmt.Printf("Primary remote sink unhealthy: %v. Failing over to the secondary.\n", err)
+	}
+}
+
+// recover switches back to the primary after a successful check,
+// replaying the outage first when enabled.
+func (w *FailoverWriter) recover() {
+	w.mu.Lock()
+	w.failures = 0
+	if w.healthy {
+		w.mu.Unlock()
+		return
+	}
+
+	// Entries keep going to the secondary, and to the replay, until the
+	// replay is drained.
+	replayed := 0
+	for len(w.replay) > 0 {
+		replay := w.replay
+		w.replay = nil
+		w.mu.Unlock()
+		for i, p := range replay {
+			if _, err := w.primary.Write(p); err != nil {
+				// Stay failed over and retry the rest at the next check.
+				w.mu.Lock()
+				w.replay = append(replay[i:], w.replay...)
+				w.mu.Unlock()
+				fmt.Printf("Failed to replay log entries to the primary remote sink: %v\n", err)
+				return
+			}
+			replayed++
+		}
+		w.mu.Lock()
+	}
+	w.healthy = true
+	w.mu.Unlock()
+
+	if w.policy.Replay {
+		fmt.Printf("Primary remote sink recovered; replayed %d writes from the outage.\n", replayed)
+	} else {
+		fmt.Println("Primary remote sink recovered.")
+	}
+}
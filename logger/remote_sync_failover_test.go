@@ -0,0 +1,121 @@
+// sad-go-logger/logger/remote_sync_failover_test.go
+
+package logger
+
+import (
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// switchWriter is a recordingWriter whose writes fail while down is set.
+type switchWriter struct {
+	recordingWriter
+	down atomic.Bool
+}
+
+func (w *switchWriter) Write(p []byte) (int, error) {
+	if w.down.Load() {
+		return 0, errors.New("connection refused")
+	}
+	return w.recordingWriter.Write(p)
+}
+
+// waitFor fails t if cond does not hold within a second.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestFailoverOnWriteError(t *testing.T) {
+	primary, secondary := &switchWriter{}, &recordingWriter{}
+	w := NewFailoverWriter(primary, secondary, FailoverPolicy{
+		CheckInterval: time.Hour,
+		HealthCheck:   func() error { return nil },
+	})
+	defer w.Close()
+
+	w.Write([]byte("first\n"))
+	primary.down.Store(true)
+	w.Write([]byte("second\n"))
+	w.Write([]byte("third\n"))
+
+	if w.PrimaryHealthy() {
+		t.Error("primary still healthy after a failed write")
+	}
+	if got := primary.String(); got != "first\n" {
+		t.Errorf("primary got %q, want the first write only", got)
+	}
+	if got := secondary.String(); got != "second\nthird\n" {
+		t.Errorf("secondary got %q, want the writes after the failure", got)
+	}
+}
+
+func TestFailoverRecoversWithReplay(t *testing.T) {
+	var unhealthy atomic.Bool
+	unhealthy.Store(true)
+	primary, secondary := &switchWriter{}, &recordingWriter{}
+	w := NewFailoverWriter(primary, secondary, FailoverPolicy{
+		CheckInterval: time.Millisecond,
+		HealthCheck: func() error {
+			if unhealthy.Load() {
+				return errors.New("health endpoint down")
+			}
+			return nil
+		},
+		Replay: true,
+	})
+	defer w.Close()
+
+	waitFor(t, "the failover", func() bool { return !w.PrimaryHealthy() })
+	w.Write([]byte("during the outage\n"))
+	if !strings.Contains(secondary.String(), "during the outage") {
+		t.Errorf("secondary got %q during the outage", secondary.String())
+	}
+
+	unhealthy.Store(false)
+	waitFor(t, "the recovery", w.PrimaryHealthy)
+	w.Write([]byte("after the outage\n"))
+	if got := primary.String(); got != "during the outage\nafter the outage\n" {
+		t.Errorf("primary got %q, want the outage replayed first", got)
+	}
+}
+
+func TestFailoverRequiresHealthCheck(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("NewFailoverWriter accepted a policy without HealthCheck")
+		}
+	}()
+	NewFailoverWriter(&recordingWriter{}, &recordingWriter{}, FailoverPolicy{})
+}
+
+// pressureWriter is a recordingWriter reporting itself down while down is
+// set.
+type pressureWriter struct {
+	recordingWriter
+	down atomic.Bool
+}
+
+func (w *pressureWriter) Pressure() Pressure {
+	return Pressure{Sink: "eu", Down: w.down.Load()}
+}
+
+func TestFailoverDefaultsToBackpressure(t *testing.T) {
+	primary := &pressureWriter{}
+	w := NewFailoverWriter(primary, &recordingWriter{}, FailoverPolicy{CheckInterval: time.Millisecond})
+	defer w.Close()
+
+	primary.down.Store(true)
+	waitFor(t, "the failover", func() bool { return !w.PrimaryHealthy() })
+	primary.down.Store(false)
+	waitFor(t, "the recovery", w.PrimaryHealthy)
+}
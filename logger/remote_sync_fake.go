@@ -0,0 +1,195 @@
+// sad-go-logger/logger/remote_sync_fake.go
+
+package logger
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrFakeDisconnected is recorded for batches attempted while a
+// FakeRemoteSyncWriter is disconnected.
+var ErrFakeDisconnected = errors.New("fake remote sink disconnected")
+
+// FakeBatch is one shipping attempt recorded by a FakeRemoteSyncWriter.
+type FakeBatch struct {
+	// Entries are the decoded log entries in the batch.
+	Entries []map[string]interface{}
+
+	// Err is the simulated error of the attempt, nil if it was delivered.
+	Err error
+
+	// Latency is the simulated latency of the attempt.
+	Latency time.Duration
+
+	// At is the time the attempt started.
+	At time.Time
+}
+
+// FakeRemoteSyncWriter is an in-memory RemoteSyncWriter for integration
+// tests. It batches entries like the real writers and records every batch
+// it "ships" in a transcript, including attempts that fail because of
+// simulated errors or disconnections. Failed batches stay buffered and are
+// retried on the next flush, like the ELK writer.
+type FakeRemoteSyncWriter struct {
+	mu sync.Mutex
+
+	buffer    []map[string]interface{}
+	batchSize int
+
+	latency   time.Duration
+	failures  []error
+	connected bool
+	closed    bool
+
+	transcript []FakeBatch
+	reconnects int
+}
+
+// NewFakeRemoteSyncWriter returns a connected FakeRemoteSyncWriter that
+// ships a batch every batchSize entries, and on Sync and Close.
+func NewFakeRemoteSyncWriter(batchSize int) *FakeRemoteSyncWriter {
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+	return &FakeRemoteSyncWriter{
+		buffer:    make([]map[string]interface{}, 0, batchSize),
+		batchSize: batchSize,
+		connected: true,
+	}
+}
+
+// SetLatency makes every subsequent shipping attempt take d.
+func (w *FakeRemoteSyncWriter) SetLatency(d time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.latency = d
+}
+
+// FailNext makes the next len(errs) shipping attempts fail with errs, in
+// order.
+func (w *FakeRemoteSyncWriter) FailNext(errs ...error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.failures = append(w.failures, errs...)
+}
+
+// Disconnect simulates losing the connection: attempts fail with
+// ErrFakeDisconnected until Reconnect is called.
+func (w *FakeRemoteSyncWriter) Disconnect() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.connected = false
+}
+
+// Reconnect restores the connection and flushes the buffered entries, like
+// the ELK writer's reconnection loop.
+func (w *FakeRemoteSyncWriter) Reconnect() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.connected = true
+	w.reconnects++
+	w.flush()
+}
+
+// Write implements the io.Writer interface.
+func (w *FakeRemoteSyncWriter) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buffer = append(w.buffer, decodeLogEntries(p)...)
+	if len(w.buffer) >= w.batchSize {
+		w.flush()
+	}
+	return len(p), nil
+}
+
+// Sync implements the zapcore.WriteSyncer interface.
+func (w *FakeRemoteSyncWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.flush()
+}
+
+// Close flushes the remaining entries and marks the writer closed.
+func (w *FakeRemoteSyncWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	err := w.flush()
+	w.closed = true
+	return err
+}
+
+// flush records a shipping attempt of the buffered entries.
+func (w *FakeRemoteSyncWriter) flush() error {
+	if len(w.buffer) == 0 {
+		return nil
+	}
+
+	batch := FakeBatch{
+		Entries: append([]map[string]interface{}(nil), w.buffer...),
+		Latency: w.latency,
+		At:      time.Now(),
+	}
+	if w.latency > 0 {
+		time.Sleep(w.latency)
+	}
+
+	switch {
+	case !w.connected:
+		batch.Err = ErrFakeDisconnected
+	case len(w.failures) > 0:
+		batch.Err = w.failures[0]
+		w.failures = w.failures[1:]
+	}
+	w.transcript = append(w.transcript, batch)
+
+	if batch.Err != nil {
+		return batch.Err
+	}
+	w.buffer = w.buffer[:0]
+	return nil
+}
+
+// Batches returns every shipping attempt so far, failed ones included.
+func (w *FakeRemoteSyncWriter) Batches() []FakeBatch {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]FakeBatch(nil), w.transcript...)
+}
+
+// Delivered returns the entries of all successful attempts, in order.
+func (w *FakeRemoteSyncWriter) Delivered() []map[string]interface{} {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var entries []map[string]interface{}
+	for _, batch := range w.transcript {
+		if batch.Err == nil {
+			entries = append(entries, batch.Entries...)
+		}
+	}
+	return entries
+}
+
+// Buffered returns the number of entries not delivered yet.
+func (w *FakeRemoteSyncWriter) Buffered() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.buffer)
+}
+
+// Reconnects returns how many times Reconnect was called.
+func (w *FakeRemoteSyncWriter) Reconnects() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.reconnects
+}
+
+// Closed reports whether Close was called.
+func (w *FakeRemoteSyncWriter) Closed() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.closed
+}
@@ -0,0 +1,181 @@
+// sad-go-logger/logger/remote_sync_fifo.go
+
+//go:build !windows && !plan9 && !js && !wasip1
+
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// fifoRetryInterval is how often a FIFO without reader is opened again.
+const fifoRetryInterval = time.Second
+
+// fifoWriteTimeout bounds the time spent finishing an entry that was
+// partially written to a full pipe.
+const fifoWriteTimeout = 100 * time.Millisecond
+
+// fifoWriter writes entries to a named pipe without ever blocking the
+// logger: entries are dropped while the pipe has no reader or is full.
+type fifoWriter struct {
+	path string
+
+	mu        sync.Mutex
+	fd        int // -1 when not open
+	retryAt   time.Time
+	dropped   int
+	truncated int // entries cut short by a full pipe
+	lastDrop  error
+}
+
+// NewFIFORemoteSyncWriter creates and returns a writer sending log entries
+// as NDJSON to a named pipe, for collection agents reading from one. The
+// pipe is created if it does not exist. It is opened without blocking, and
+// opened again every second while it has no reader; entries written while
+// no reader is connected, or while the pipe is full, are dropped and
+// counted. An entry cut short by a full pipe is ended with a newline, or
+// the pipe is closed and opened again, and counted as truncated. It reads
+// configuration from environment variables:
+//   - FIFO_PATH: The path of the named pipe
+//
+// If FIFO_PATH is not set, or the path exists and is not a named pipe, it
+// returns nil.
+func NewFIFORemoteSyncWriter() RemoteSyncWriter {
+	path := os.Getenv("FIFO_PATH")
+	if path == "" {
+		fmt.Println("FIFO_PATH not set. FIFO logging disabled.")
+		return nil
+	}
+
+	info, err := os.Stat(path)
+	switch {
+	case os.IsNotExist(err):
+		if err := syscall.Mkfifo(path, 0640); err != nil {
+			fmt.Printf("Failed to create FIFO %s: %v. FIFO logging disabled.\n", path, err)
+			return nil
+		}
+	case err != nil:
+		fmt.Printf("Failed to stat FIFO %s: %v. FIFO logging disabled.\n", path, err)
+		return nil
+	case info.Mode()&os.ModeNamedPipe == 0:
+		fmt.Printf("%s is not a named pipe. FIFO logging disabled.\n", path)
+		return nil
+	}
+
+	return &fifoWriter{path: path, fd: -1}
+}
+
+// open opens the pipe if it is not open and a retry is due. Opening a
+// pipe for writing without blocking fails until a reader opens it.
+func (w *fifoWriter) open() bool {
+	if w.fd >= 0 {
+		return true
+	}
+	now := time.Now()
+	if now.Before(w.retryAt) {
+		return false
+	}
+	fd, err := syscall.Open(w.path, syscall.O_WRONLY|syscall.O_NONBLOCK|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		w.retryAt = now.Add(fifoRetryInterval)
+		return false
+	}
+	w.fd = fd
+	w.report("has a reader again")
+	return true
+}
+
+// report prints the entries dropped and truncated since the last report,
+// if any.
+func (w *fifoWriter) report(event string) {
+	if w.dropped == 0 && w.truncated == 0 {
+		return
+	}
+	fmt.Printf("FIFO %s %s; dropped %d and truncated %d log entries meanwhile (%v)\n", w.path, event, w.dropped, w.truncated, w.lastDrop)
+	w.dropped, w.truncated = 0, 0
+}
+
+// close closes the pipe after its reader went away.
+func (w *fifoWriter) close() {
+	if w.fd >= 0 {
+		syscall.Close(w.fd)
+		w.fd = -1
+	}
+}
+
+// Write implements the io.Writer interface. It never blocks for long and
+// never fails: entries that cannot be written are dropped.
+func (w *fifoWriter) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.open() {
+		w.drop(errors.New("no reader"))
+		return len(p), nil
+	}
+
+	written := 0
+	deadline := time.Now().Add(fifoWriteTimeout)
+	for written < len(p) {
+		n, err := syscall.Write(w.fd, p[written:])
+		if n > 0 {
+			written += n
+		}
+		switch {
+		case err == nil:
+		case errors.Is(err, syscall.EAGAIN) && written > 0 && time.Now().Before(deadline):
+			// Finish the entry rather than leave a partial line.
+			time.Sleep(time.Millisecond)
+		case errors.Is(err, syscall.EAGAIN) && written > 0:
+			w.truncate()
+			return len(p), nil
+		case errors.Is(err, syscall.EAGAIN):
+			w.drop(errors.New("pipe full"))
+			return len(p), nil
+		default:
+			// EPIPE: the reader went away.
+			w.close()
+			w.drop(err)
+			return len(p), nil
+		}
+	}
+	w.report("accepts entries again")
+	return len(p), nil
+}
+
+// truncate ends the line of an entry cut short by a full pipe, so that the
+// next entry does not continue it, or closes the pipe if even the newline
+// does not fit, the reader then seeing the end of the partial line.
+func (w *fifoWriter) truncate() {
+	w.truncated++
+	w.lastDrop = errors.New("pipe full, entry truncated")
+	if n, err := syscall.Write(w.fd, []byte{'\n'}); n == 1 && err == nil {
+		return
+	}
+	w.close()
+	w.retryAt = time.Now().Add(fifoRetryInterval)
+}
+
+func (w *fifoWriter) drop(err error) {
+	w.dropped++
+	w.lastDrop = err
+}
+
+// Sync implements the zapcore.WriteSyncer interface. Pipes have nothing to
+// flush.
+func (w *fifoWriter) Sync() error {
+	return nil
+}
+
+// Close closes the pipe.
+func (w *fifoWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.close()
+	return nil
+}
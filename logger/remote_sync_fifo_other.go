@@ -0,0 +1,14 @@
+// sad-go-logger/logger/remote_sync_fifo_other.go
+
+//go:build windows || plan9 || js || wasip1
+
+package logger
+
+import "fmt"
+
+// NewFIFORemoteSyncWriter returns nil: named pipes are not supported on
+// this platform.
+func NewFIFORemoteSyncWriter() RemoteSyncWriter {
+	fmt.Println("Named pipes are not supported on this platform. FIFO logging disabled.")
+	return nil
+}
@@ -0,0 +1,185 @@
+// sad-go-logger/logger/remote_sync_grpc.go
+
+package logger
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/sadco-io/sad-go-logger/logger/collectorpb"
+	"github.com/sadco-io/sad-go-logger/logger/logfile"
+	"github.com/sadco-io/sad-go-logger/logger/logpb"
+)
+
+// grpcAckTimeout bounds the wait for the acknowledgement of a batch.
+const grpcAckTimeout = 10 * time.Second
+
+// grpcWriter streams batches to a collector implementing
+// collectorpb.LogCollector.
+type grpcWriter struct {
+	*batchWriter
+
+	address string
+	creds   credentials.TransportCredentials
+
+	// The stream is only used by the flushing goroutine; mu guards its
+	// replacement against Close.
+	mu       sync.Mutex
+	conn     *grpc.ClientConn
+	stream   collectorpb.LogCollector_StreamLogsClient
+	cancel   context.CancelFunc
+	sequence uint64
+}
+
+// NewGRPCRemoteSyncWriter creates and returns a writer streaming batches of
+// log entries over gRPC to an in-house collector, see the collectorpb
+// package. Every batch waits for its acknowledgement; batches that are not
+// acknowledged are sent again on a new stream, with backoff. It reads
+// configuration from environment variables:
+//   - GRPC_COLLECTOR_ADDRESS: The collector, as host:port
+//   - GRPC_COLLECTOR_TLS: Set to "true" to enable TLS
+//   - GRPC_COLLECTOR_CA_FILE: The PEM CA bundle verifying the collector
+//     (default: the system roots)
+//   - GRPC_COLLECTOR_CERT_FILE and GRPC_COLLECTOR_KEY_FILE: The PEM client
+//     certificate and key, for mutual TLS; setting them enables TLS
+//   - GRPC_COLLECTOR_SERVER_NAME: Overrides the name verified in the
+//     collector certificate
+//
+// If GRPC_COLLECTOR_ADDRESS is not set, or the TLS files cannot be loaded,
+// it returns nil.
+func NewGRPCRemoteSyncWriter() RemoteSyncWriter {
+	address := os.Getenv("GRPC_COLLECTOR_ADDRESS")
+	if address == "" {
+		fmt.Println("GRPC_COLLECTOR_ADDRESS not set. gRPC logging disabled.")
+		return nil
+	}
+
+	creds := insecure.NewCredentials()
+	certFile, keyFile := os.Getenv("GRPC_COLLECTOR_CERT_FILE"), os.Getenv("GRPC_COLLECTOR_KEY_FILE")
+	if os.Getenv("GRPC_COLLECTOR_TLS") == "true" || certFile != "" {
+		config, err := grpcTLSConfig(os.Getenv("GRPC_COLLECTOR_CA_FILE"), certFile, keyFile)
+		if err != nil {
+			fmt.Printf("Invalid gRPC collector TLS configuration: %v. gRPC logging disabled.\n", err)
+			return nil
+		}
+		config.ServerName = os.Getenv("GRPC_COLLECTOR_SERVER_NAME")
+		creds = credentials.NewTLS(config)
+	}
+
+	w := &grpcWriter{address: address, creds: creds}
+	w.batchWriter = newBatchWriter(batchConfig{
+		name:    "gRPC collector",
+		send:    w.send,
+		prepare: w.connect,
+	})
+	return w
+}
+
+// grpcTLSConfig loads the CA bundle and client certificate, when set.
+func grpcTLSConfig(caFile, certFile, keyFile string) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", caFile)
+		}
+	}
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// connect opens a new stream, on a new connection.
+func (w *grpcWriter) connect() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.closeStream()
+	conn, err := grpc.NewClient(w.address, grpc.WithTransportCredentials(w.creds))
+	if err != nil {
+		return fmt.Errorf("failed to connect: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := collectorpb.NewLogCollectorClient(conn).StreamLogs(ctx)
+	if err != nil {
+		cancel()
+		conn.Close()
+		return fmt.Errorf("failed to open stream: %v", err)
+	}
+	w.conn, w.stream, w.cancel = conn, stream, cancel
+	return nil
+}
+
+// closeStream closes the stream and its connection. w.mu must be held.
+func (w *grpcWriter) closeStream() {
+	if w.stream != nil {
+		w.stream.CloseSend()
+		w.cancel()
+		w.conn.Close()
+		w.conn, w.stream, w.cancel = nil, nil, nil
+	}
+}
+
+// send sends a batch and waits for its acknowledgement.
+func (w *grpcWriter) send(entries []map[string]interface{}) error {
+	batch := &collectorpb.LogBatch{Entries: make([]*logpb.LogEntry, len(entries))}
+	for i, entry := range entries {
+		e, err := logfile.Record(entry).Proto()
+		if err != nil {
+			return &permanentError{err}
+		}
+		batch.Entries[i] = e
+	}
+
+	w.mu.Lock()
+	stream, cancel := w.stream, w.cancel
+	w.sequence++
+	batch.Sequence = w.sequence
+	w.mu.Unlock()
+	if stream == nil {
+		return fmt.Errorf("not connected")
+	}
+
+	if err := stream.Send(batch); err != nil {
+		return err
+	}
+	timer := time.AfterFunc(grpcAckTimeout, cancel)
+	ack, err := stream.Recv()
+	timer.Stop()
+	switch {
+	case err != nil:
+		return fmt.Errorf("no acknowledgement: %v", err)
+	case ack.Sequence != batch.Sequence:
+		return fmt.Errorf("acknowledgement of batch %d instead of %d", ack.Sequence, batch.Sequence)
+	case ack.Error != "":
+		return &permanentError{fmt.Errorf("batch rejected: %s", ack.Error)}
+	}
+	return nil
+}
+
+// Close sends the remaining entries and closes the stream.
+func (w *grpcWriter) Close() error {
+	err := w.batchWriter.Close()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closeStream()
+	return err
+}
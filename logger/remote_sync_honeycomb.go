@@ -0,0 +1,81 @@
+// sad-go-logger/logger/remote_sync_honeycomb.go
+
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sadco-io/sad-go-logger/logger/logfile"
+)
+
+// honeycombEvent is one event of the Honeycomb batch API.
+type honeycombEvent struct {
+	Time       string                 `json:"time,omitempty"`
+	SampleRate int                    `json:"samplerate,omitempty"`
+	Data       map[string]interface{} `json:"data"`
+}
+
+// NewHoneycombRemoteSyncWriter creates and returns a writer sending log
+// entries as events to the Honeycomb batch API. Entries carrying the
+// SampleRateKey field, such as those kept by the sampler, are sent with
+// that sample rate so that Honeycomb weights them accordingly. It reads
+// configuration from environment variables:
+//   - HONEYCOMB_API_KEY: The API key
+//   - HONEYCOMB_DATASET: The dataset (default: the SERVICE_NAME, or "logs")
+//   - HONEYCOMB_API_HOST: The API host (default: "https://api.honeycomb.io")
+//
+// If HONEYCOMB_API_KEY is not set, it returns nil.
+func NewHoneycombRemoteSyncWriter() RemoteSyncWriter {
+	apiKey := os.Getenv("HONEYCOMB_API_KEY")
+	if apiKey == "" {
+		fmt.Println("HONEYCOMB_API_KEY not set. Honeycomb logging disabled.")
+		return nil
+	}
+	dataset := os.Getenv("HONEYCOMB_DATASET")
+	if dataset == "" {
+		dataset = os.Getenv("SERVICE_NAME")
+	}
+	if dataset == "" {
+		dataset = "logs"
+	}
+	host := os.Getenv("HONEYCOMB_API_HOST")
+	if host == "" {
+		host = "https://api.honeycomb.io"
+	}
+
+	return newHTTPBatchWriter(httpBatchConfig{
+		batchConfig: batchConfig{name: "Honeycomb"},
+		endpoint:    strings.TrimRight(host, "/") + "/1/batch/" + url.PathEscape(dataset),
+		headers:     map[string]string{"X-Honeycomb-Team": apiKey},
+		encode:      encodeHoneycombEvents,
+		gzip:        true,
+	})
+}
+
+// encodeHoneycombEvents renders a batch as the JSON array of the batch API,
+// the timestamp and sample rate of the entries moving to the event.
+func encodeHoneycombEvents(entries []map[string]interface{}) ([]byte, string, error) {
+	events := make([]honeycombEvent, len(entries))
+	for i, entry := range entries {
+		data := make(map[string]interface{}, len(entry))
+		for key, value := range entry {
+			data[key] = value
+		}
+		if t, ok := logfile.Record(entry).Time(); ok {
+			events[i].Time = t.Format(time.RFC3339Nano)
+			delete(data, logfile.TimeKey)
+		}
+		if rate, ok := data[SampleRateKey].(float64); ok && rate >= 1 {
+			events[i].SampleRate = int(rate)
+			delete(data, SampleRateKey)
+		}
+		events[i].Data = data
+	}
+	body, err := json.Marshal(events)
+	return body, "application/json", err
+}
@@ -0,0 +1,91 @@
+// sad-go-logger/logger/remote_sync_http.go
+
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// httpBatchConfig describes an HTTP ingestion API for newHTTPBatchWriter.
+type httpBatchConfig struct {
+	batchConfig
+
+	// endpoint is the URL the batches are POSTed to.
+	endpoint string
+
+	// headers are set on every request, e.g. for authentication.
+	headers map[string]string
+
+	// encode renders a batch as a request body and its content type.
+	encode func(entries []map[string]interface{}) ([]byte, string, error)
+
+	// gzip compresses the request bodies.
+	gzip bool
+}
+
+// httpSender posts batches to an HTTP ingestion API.
+type httpSender struct {
+	cfg    httpBatchConfig
+	client *http.Client
+	writer *batchWriter
+}
+
+// newHTTPBatchWriter returns a batchWriter posting to the API described by
+// cfg; cfg.send is ignored.
+func newHTTPBatchWriter(cfg httpBatchConfig) *batchWriter {
+	s := &httpSender{cfg: cfg, client: &http.Client{Timeout: 10 * time.Second}}
+	cfg.batchConfig.send = s.send
+	cfg.batchConfig.rateLimited = true
+	s.writer = newBatchWriter(cfg.batchConfig)
+	return s.writer
+}
+
+func (s *httpSender) send(batch []map[string]interface{}) error {
+	body, contentType, err := s.cfg.encode(batch)
+	if err != nil {
+		return fmt.Errorf("failed to encode log entries: %v", err)
+	}
+	s.writer.waitRateLimit(len(body))
+
+	var reader io.Reader = bytes.NewReader(body)
+	if s.cfg.gzip {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		if _, err := gz.Write(body); err != nil {
+			return fmt.Errorf("failed to compress log entries: %v", err)
+		}
+		if err := gz.Close(); err != nil {
+			return fmt.Errorf("failed to compress log entries: %v", err)
+		}
+		reader = &buf
+	}
+
+	req, err := http.NewRequest("POST", s.cfg.endpoint, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	if s.cfg.gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	for key, value := range s.cfg.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		// The retry policy decides which codes are worth retrying
+		return &statusError{resp.StatusCode, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, bytes.TrimSpace(msg))}
+	}
+	return nil
+}
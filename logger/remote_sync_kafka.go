@@ -0,0 +1,154 @@
+// sad-go-logger/logger/remote_sync_kafka.go
+
+package logger
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl/plain"
+)
+
+// kafkaWriter produces entries to a Kafka topic.
+type kafkaWriter struct {
+	*batchWriter
+
+	writer *kafka.Writer
+
+	// registry, when set, holds the Avro schema of the values, registered
+	// under subject; schemaID is set by prepare.
+	registry *schemaRegistry
+	subject  string
+	register bool
+	schemaID int32
+}
+
+// NewKafkaRemoteSyncWriter creates and returns a writer producing log
+// entries to a Kafka topic, one message per entry keyed by the service
+// name, so that the entries of a service stay in order on one partition.
+// Values are the entries as JSON or, with a schema registry, Avro records
+// in the Confluent wire format, see avroLogEntrySchema. It reads
+// configuration from environment variables:
+//   - KAFKA_BROKERS: Comma-separated bootstrap brokers, as host:port
+//   - KAFKA_TOPIC: The topic (default: "logs")
+//   - KAFKA_USE_TLS: Set to "true" to enable TLS
+//   - KAFKA_SASL_USERNAME and KAFKA_SASL_PASSWORD: SASL/PLAIN credentials
+//     (optional)
+//   - KAFKA_SCHEMA_REGISTRY_URL: The Confluent Schema Registry; setting it
+//     enables Avro
+//   - KAFKA_SCHEMA_REGISTRY_USERNAME and KAFKA_SCHEMA_REGISTRY_PASSWORD:
+//     Basic auth credentials of the registry (optional)
+//   - KAFKA_SCHEMA_SUBJECT_STRATEGY: "topic" (default), "record" or
+//     "topic_record", the subject naming strategy of the schema
+//   - KAFKA_SCHEMA_AUTO_REGISTER: Set to "false" to require the schema to
+//     be registered already (default: "true")
+//
+// If KAFKA_BROKERS is not set, or the subject strategy is invalid, it
+// returns nil.
+func NewKafkaRemoteSyncWriter() RemoteSyncWriter {
+	brokers := os.Getenv("KAFKA_BROKERS")
+	if brokers == "" {
+		fmt.Println("KAFKA_BROKERS not set. Kafka logging disabled.")
+		return nil
+	}
+	topic := os.Getenv("KAFKA_TOPIC")
+	if topic == "" {
+		topic = "logs"
+	}
+
+	transport := &kafka.Transport{}
+	if os.Getenv("KAFKA_USE_TLS") == "true" {
+		transport.TLS = &tls.Config{}
+	}
+	if username := os.Getenv("KAFKA_SASL_USERNAME"); username != "" {
+		transport.SASL = plain.Mechanism{Username: username, Password: os.Getenv("KAFKA_SASL_PASSWORD")}
+	}
+
+	w := &kafkaWriter{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(strings.Split(brokers, ",")...),
+			Topic:        topic,
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+			BatchTimeout: 10 * time.Millisecond,
+			WriteTimeout: 10 * time.Second,
+			Transport:    transport,
+		},
+	}
+	if registry := os.Getenv("KAFKA_SCHEMA_REGISTRY_URL"); registry != "" {
+		subject, err := schemaSubject(os.Getenv("KAFKA_SCHEMA_SUBJECT_STRATEGY"), topic)
+		if err != nil {
+			fmt.Printf("Invalid KAFKA_SCHEMA_SUBJECT_STRATEGY: %v. Kafka logging disabled.\n", err)
+			return nil
+		}
+		w.registry = newSchemaRegistry(registry, os.Getenv("KAFKA_SCHEMA_REGISTRY_USERNAME"), os.Getenv("KAFKA_SCHEMA_REGISTRY_PASSWORD"))
+		w.subject = subject
+		w.register = os.Getenv("KAFKA_SCHEMA_AUTO_REGISTER") != "false"
+	}
+
+	cfg := batchConfig{name: "Kafka", send: w.produce}
+	if w.registry != nil {
+		cfg.prepare = w.fetchSchemaID
+	}
+	w.batchWriter = newBatchWriter(cfg)
+	return w
+}
+
+// fetchSchemaID registers or looks up the Avro schema.
+func (w *kafkaWriter) fetchSchemaID() error {
+	id, err := w.registry.schemaID(w.subject, avroLogEntrySchema, w.register)
+	if err != nil {
+		return err
+	}
+	w.schemaID = id
+	return nil
+}
+
+// produce writes a batch and waits for its acknowledgement by the in-sync
+// replicas.
+func (w *kafkaWriter) produce(entries []map[string]interface{}) error {
+	messages, err := w.encode(entries)
+	if err != nil {
+		return &permanentError{err}
+	}
+	if err := w.writer.WriteMessages(context.Background(), messages...); err != nil {
+		return fmt.Errorf("failed to produce log entries: %v", err)
+	}
+	return nil
+}
+
+// encode renders a batch as messages.
+func (w *kafkaWriter) encode(entries []map[string]interface{}) ([]kafka.Message, error) {
+	messages := make([]kafka.Message, len(entries))
+	for i, entry := range entries {
+		row := newLogRow(entry)
+		messages[i].Key = []byte(row.Service)
+		messages[i].Time = row.Time
+		if w.registry != nil {
+			value := appendSchemaRegistryHeader(nil, w.schemaID)
+			messages[i].Value = encodeAvroLogEntry(value, row)
+			continue
+		}
+		value, err := json.Marshal(entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode log entry: %v", err)
+		}
+		messages[i].Value = value
+	}
+	return messages, nil
+}
+
+// Close sends the remaining entries and closes the producer.
+func (w *kafkaWriter) Close() error {
+	err := w.batchWriter.Close()
+	if closeErr := w.writer.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
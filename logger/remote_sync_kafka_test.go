@@ -0,0 +1,157 @@
+// sad-go-logger/logger/remote_sync_kafka_test.go
+
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// avroReader decodes the Avro binary encoding written by encodeAvroLogEntry.
+type avroReader struct {
+	t *testing.T
+	r *bytes.Reader
+}
+
+func (a avroReader) long() int64 {
+	a.t.Helper()
+	v, err := binary.ReadVarint(a.r)
+	if err != nil {
+		a.t.Fatalf("failed to read a long: %v", err)
+	}
+	return v
+}
+
+func (a avroReader) string() string {
+	a.t.Helper()
+	b := make([]byte, a.long())
+	if _, err := a.r.Read(b); err != nil && len(b) > 0 {
+		a.t.Fatalf("failed to read a string: %v", err)
+	}
+	return string(b)
+}
+
+func TestEncodeAvroLogEntry(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	row := logRow{
+		Time:    at,
+		Level:   "info",
+		Service: "cart",
+		Host:    "web-1",
+		Message: "checkout",
+		Fields:  map[string]interface{}{"user": "u-1", "items": 3.0},
+	}
+
+	a := avroReader{t, bytes.NewReader(encodeAvroLogEntry(nil, row))}
+	if got := a.long(); got != at.UnixMilli() {
+		t.Errorf("timestamp = %d, want %d", got, at.UnixMilli())
+	}
+	for _, want := range []string{"info", "cart", "web-1", "checkout"} {
+		if got := a.string(); got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+	if n := a.long(); n != 2 {
+		t.Fatalf("map block of %d fields, want 2", n)
+	}
+	for _, want := range [][2]string{{"items", "3"}, {"user", "u-1"}} {
+		if k, v := a.string(), a.string(); k != want[0] || v != want[1] {
+			t.Errorf("field %s=%s, want %s=%s", k, v, want[0], want[1])
+		}
+	}
+	if a.long() != 0 || a.r.Len() != 0 {
+		t.Error("map not terminated at the end of the record")
+	}
+}
+
+func TestSchemaSubject(t *testing.T) {
+	for strategy, want := range map[string]string{
+		"":             "logs-value",
+		"topic":        "logs-value",
+		"record":       "io.sadco.logger.LogEntry",
+		"topic_record": "logs-io.sadco.logger.LogEntry",
+	} {
+		if got, err := schemaSubject(strategy, "logs"); err != nil || got != want {
+			t.Errorf("schemaSubject(%q) = %q, %v, want %q", strategy, got, err, want)
+		}
+	}
+	if _, err := schemaSubject("topic_name", "logs"); err == nil {
+		t.Error("schemaSubject accepted an unknown strategy")
+	}
+}
+
+func TestSchemaRegistry(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.EscapedPath())
+		var body struct{ Schema string }
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.Schema != avroLogEntrySchema {
+			t.Errorf("registry got schema %q", body.Schema)
+		}
+		if user, _, _ := r.BasicAuth(); user != "ingest" {
+			t.Errorf("registry got user %q", user)
+		}
+		if r.URL.Path == "/subjects/unknown" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error_code":40403,"message":"Schema not found"}`))
+			return
+		}
+		w.Write([]byte(`{"id":42}`))
+	}))
+	defer server.Close()
+	registry := newSchemaRegistry(server.URL+"/", "ingest", "secret")
+
+	if id, err := registry.schemaID("logs-value", avroLogEntrySchema, true); err != nil || id != 42 {
+		t.Errorf("schemaID registering = %d, %v, want 42", id, err)
+	}
+	if id, err := registry.schemaID("io.sadco.logger.LogEntry", avroLogEntrySchema, false); err != nil || id != 42 {
+		t.Errorf("schemaID looking up = %d, %v, want 42", id, err)
+	}
+	if _, err := registry.schemaID("unknown", avroLogEntrySchema, false); err == nil {
+		t.Error("schemaID found a schema that is not registered")
+	}
+
+	want := []string{"/subjects/logs-value/versions", "/subjects/io.sadco.logger.LogEntry", "/subjects/unknown"}
+	if len(paths) != len(want) {
+		t.Fatalf("registry requests %v, want %v", paths, want)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Errorf("registry request %d to %s, want %s", i, paths[i], want[i])
+		}
+	}
+}
+
+func TestKafkaWriterEncode(t *testing.T) {
+	entry := map[string]interface{}{
+		"timestamp":   "2024-05-01T12:00:00Z",
+		"level":       "info",
+		"message":     "checkout",
+		"serviceName": "cart",
+	}
+
+	w := &kafkaWriter{}
+	messages, err := w.encode([]map[string]interface{}{entry})
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	if string(messages[0].Key) != "cart" || !json.Valid(messages[0].Value) {
+		t.Errorf("JSON message with key %q and value %q", messages[0].Key, messages[0].Value)
+	}
+
+	w = &kafkaWriter{registry: &schemaRegistry{}, schemaID: 7}
+	messages, err = w.encode([]map[string]interface{}{entry})
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	header := []byte{0, 0, 0, 0, 7}
+	if value := messages[0].Value; !bytes.HasPrefix(value, header) || !bytes.Equal(value[5:], encodeAvroLogEntry(nil, newLogRow(entry))) {
+		t.Errorf("Avro message value %v, want the wire format header and the record", value)
+	}
+}
@@ -0,0 +1,71 @@
+// sad-go-logger/logger/remote_sync_logtail.go
+
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sadco-io/sad-go-logger/logger/logfile"
+)
+
+// NewLogtailRemoteSyncWriter creates and returns a writer sending log
+// entries to a Better Stack (formerly Logtail) HTTP source. Following
+// Better Stack's recommendations, entries are sent in gzipped batches of
+// up to 1000 at least every second, and failed batches are retried with
+// exponential backoff unless they were rejected. It reads configuration
+// from environment variables:
+//   - LOGTAIL_SOURCE_TOKEN: The source token; setting it enables the sink
+//   - LOGTAIL_HOST: The ingesting host of the source
+//     (default: "https://in.logs.betterstack.com")
+//
+// If LOGTAIL_SOURCE_TOKEN is not set, it returns nil.
+func NewLogtailRemoteSyncWriter() RemoteSyncWriter {
+	token := os.Getenv("LOGTAIL_SOURCE_TOKEN")
+	if token == "" {
+		fmt.Println("LOGTAIL_SOURCE_TOKEN not set. Better Stack logging disabled.")
+		return nil
+	}
+	host := os.Getenv("LOGTAIL_HOST")
+	if host == "" {
+		host = "https://in.logs.betterstack.com"
+	}
+	if !strings.Contains(host, "://") {
+		host = "https://" + host
+	}
+
+	return newHTTPBatchWriter(httpBatchConfig{
+		batchConfig: batchConfig{
+			name:          "Better Stack",
+			batchSize:     1000,
+			flushInterval: time.Second,
+			retry:         RetryPolicy{MaxAttempts: 6},
+		},
+		endpoint: host,
+		headers:  map[string]string{"Authorization": "Bearer " + token},
+		encode:   encodeLogtailEvents,
+		gzip:     true,
+	})
+}
+
+// encodeLogtailEvents renders a batch as a JSON array, the timestamp of the
+// entries moving to Better Stack's dt field.
+func encodeLogtailEvents(entries []map[string]interface{}) ([]byte, string, error) {
+	events := make([]map[string]interface{}, len(entries))
+	for i, entry := range entries {
+		event := make(map[string]interface{}, len(entry))
+		for key, value := range entry {
+			event[key] = value
+		}
+		if t, ok := logfile.Record(entry).Time(); ok {
+			event["dt"] = t.UTC().Format(time.RFC3339Nano)
+			delete(event, logfile.TimeKey)
+		}
+		events[i] = event
+	}
+	body, err := json.Marshal(events)
+	return body, "application/json", err
+}
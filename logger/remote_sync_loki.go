@@ -0,0 +1,217 @@
+// sad-go-logger/logger/remote_sync_loki.go
+
+package logger
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sadco-io/sad-go-logger/logger/logfile"
+)
+
+// defaultLokiLabels maps the Loki labels of the entries to their fields.
+// Labels index the streams, so they are kept to low-cardinality fields.
+const defaultLokiLabels = "service_name=serviceName,host=hostname,level"
+
+// lokiLabelName matches valid Loki label names.
+var lokiLabelName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// lokiConfig describes a Loki push endpoint.
+type lokiConfig struct {
+	name     string
+	url      string
+	user     string
+	password string
+	tenant   string
+
+	// labels maps label names to the entry fields they are read from.
+	labels map[string]string
+
+	// static are labels added to every stream.
+	static map[string]string
+}
+
+// lokiStream is one stream of the Loki push API.
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// NewLokiRemoteSyncWriter creates and returns a writer pushing batches of
+// log entries to Loki, grouped into streams by their labels. It reads
+// configuration from environment variables:
+//   - LOKI_URL: The server URL (default: "http://localhost:3100")
+//   - LOKI_USERNAME and LOKI_PASSWORD: Basic auth credentials (optional)
+//   - LOKI_TENANT_ID: The tenant, sent as X-Scope-OrgID (optional)
+//   - LOKI_LABELS: Comma-separated labels, as label=field or field
+//     (default: "service_name=serviceName,host=hostname,level")
+//
+// It returns nil if LOKI_LABELS is invalid.
+func NewLokiRemoteSyncWriter() RemoteSyncWriter {
+	labels, err := parseLokiLabels(os.Getenv("LOKI_LABELS"))
+	if err != nil {
+		fmt.Printf("Invalid LOKI_LABELS: %v. Loki logging disabled.\n", err)
+		return nil
+	}
+	base := os.Getenv("LOKI_URL")
+	if base == "" {
+		base = "http://localhost:3100"
+	}
+	return newLokiWriter(lokiConfig{
+		name:     "Loki",
+		url:      base,
+		user:     os.Getenv("LOKI_USERNAME"),
+		password: os.Getenv("LOKI_PASSWORD"),
+		tenant:   os.Getenv("LOKI_TENANT_ID"),
+		labels:   labels,
+	})
+}
+
+// NewGrafanaCloudRemoteSyncWriter creates and returns a Loki writer for the
+// logs service of a Grafana Cloud stack, with the default labels. It reads
+// configuration from environment variables, shown on the Loki details page
+// of the stack:
+//   - GRAFANA_CLOUD_LOGS_URL: The Loki URL, e.g. "https://logs-prod-012.grafana.net"
+//   - GRAFANA_CLOUD_LOGS_USER: The Loki user, a number
+//   - GRAFANA_CLOUD_API_KEY: An access policy token with the logs:write scope
+//   - GRAFANA_CLOUD_ENVIRONMENT: Added as the env label when set
+//
+// If any of the first three is not set, it returns nil.
+func NewGrafanaCloudRemoteSyncWriter() RemoteSyncWriter {
+	base := os.Getenv("GRAFANA_CLOUD_LOGS_URL")
+	user := os.Getenv("GRAFANA_CLOUD_LOGS_USER")
+	key := os.Getenv("GRAFANA_CLOUD_API_KEY")
+	if base == "" || user == "" || key == "" {
+		fmt.Println("GRAFANA_CLOUD_LOGS_URL, GRAFANA_CLOUD_LOGS_USER or GRAFANA_CLOUD_API_KEY not set. Grafana Cloud logging disabled.")
+		return nil
+	}
+
+	labels, _ := parseLokiLabels(defaultLokiLabels)
+	cfg := lokiConfig{
+		name:     "Grafana Cloud",
+		url:      base,
+		user:     user,
+		password: key,
+		labels:   labels,
+	}
+	if env := os.Getenv("GRAFANA_CLOUD_ENVIRONMENT"); env != "" {
+		cfg.static = map[string]string{"env": env}
+	}
+	return newLokiWriter(cfg)
+}
+
+// parseLokiLabels parses a LOKI_LABELS value, empty for the defaults.
+func parseLokiLabels(s string) (map[string]string, error) {
+	if s == "" {
+		s = defaultLokiLabels
+	}
+	labels := map[string]string{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, field, ok := strings.Cut(part, "=")
+		if !ok {
+			field = name
+		}
+		if !lokiLabelName.MatchString(name) || field == "" {
+			return nil, fmt.Errorf("invalid label %q", part)
+		}
+		labels[name] = field
+	}
+	return labels, nil
+}
+
+func newLokiWriter(cfg lokiConfig) *batchWriter {
+	headers := map[string]string{}
+	if cfg.user != "" {
+		headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(cfg.user+":"+cfg.password))
+	}
+	if cfg.tenant != "" {
+		headers["X-Scope-OrgID"] = cfg.tenant
+	}
+	return newHTTPBatchWriter(httpBatchConfig{
+		batchConfig: batchConfig{name: cfg.name},
+		endpoint:    strings.TrimRight(cfg.url, "/") + "/loki/api/v1/push",
+		headers:     headers,
+		encode:      cfg.encode,
+		gzip:        true,
+	})
+}
+
+// encode renders a batch as a push request. Entries are grouped into
+// streams by their labels; the lines are the entries as JSON, without the
+// timestamp that Loki stores alongside.
+func (cfg lokiConfig) encode(entries []map[string]interface{}) ([]byte, string, error) {
+	streams := map[string]*lokiStream{}
+	var order []string
+	for _, entry := range entries {
+		stream := make(map[string]string, len(cfg.labels)+len(cfg.static))
+		for name, value := range cfg.static {
+			stream[name] = value
+		}
+		for name, field := range cfg.labels {
+			if value, ok := logfile.Record(entry).Field(field); ok {
+				stream[name] = value
+			}
+		}
+
+		t, ok := logfile.Record(entry).Time()
+		if !ok {
+			t = time.Now()
+		}
+		line := make(map[string]interface{}, len(entry))
+		for key, value := range entry {
+			if key != logfile.TimeKey {
+				line[key] = value
+			}
+		}
+		data, err := json.Marshal(line)
+		if err != nil {
+			return nil, "", err
+		}
+
+		key := lokiStreamKey(stream)
+		s, ok := streams[key]
+		if !ok {
+			s = &lokiStream{Stream: stream}
+			streams[key] = s
+			order = append(order, key)
+		}
+		s.Values = append(s.Values, [2]string{strconv.FormatInt(t.UnixNano(), 10), string(data)})
+	}
+
+	req := struct {
+		Streams []*lokiStream `json:"streams"`
+	}{}
+	for _, key := range order {
+		req.Streams = append(req.Streams, streams[key])
+	}
+	body, err := json.Marshal(req)
+	return body, "application/json", err
+}
+
+// lokiStreamKey identifies a label set.
+func lokiStreamKey(stream map[string]string) string {
+	names := make([]string, 0, len(stream))
+	for name := range stream {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name)
+		b.WriteByte(0)
+		b.WriteString(stream[name])
+		b.WriteByte(0)
+	}
+	return b.String()
+}
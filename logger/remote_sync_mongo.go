@@ -0,0 +1,156 @@
+// sad-go-logger/logger/remote_sync_mongo.go
+
+package logger
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// mongoWriter inserts entries into a MongoDB collection.
+type mongoWriter struct {
+	*batchWriter
+
+	client     *mongo.Client
+	database   string
+	collection string
+	cappedSize int64
+	ttl        time.Duration
+}
+
+// NewMongoRemoteSyncWriter creates and returns a writer inserting batches
+// of log entries into a MongoDB collection with unordered bulk inserts, one
+// document per entry with the timestamp, level, service, host and message
+// keys and the other fields in a fields subdocument. It reads configuration
+// from environment variables:
+//   - MONGO_LOGS_URI: The connection string, e.g. "mongodb://host:27017"
+//   - MONGO_LOGS_DATABASE: The database (default: "logs")
+//   - MONGO_LOGS_COLLECTION: The collection (default: "logs")
+//   - MONGO_LOGS_CAPPED_SIZE_MB: Creates the collection capped to this size,
+//     the oldest entries being overwritten first
+//   - MONGO_LOGS_TTL: A duration, e.g. "168h", after which entries are
+//     removed by a TTL index
+//
+// MongoDB does not support TTL indexes on capped collections, so
+// MONGO_LOGS_TTL is ignored when MONGO_LOGS_CAPPED_SIZE_MB is set. Neither
+// applies to an existing collection. If MONGO_LOGS_URI is not set, it
+// returns nil.
+func NewMongoRemoteSyncWriter() RemoteSyncWriter {
+	uri := os.Getenv("MONGO_LOGS_URI")
+	if uri == "" {
+		fmt.Println("MONGO_LOGS_URI not set. MongoDB logging disabled.")
+		return nil
+	}
+
+	w := &mongoWriter{
+		database:   os.Getenv("MONGO_LOGS_DATABASE"),
+		collection: os.Getenv("MONGO_LOGS_COLLECTION"),
+	}
+	if w.database == "" {
+		w.database = "logs"
+	}
+	if w.collection == "" {
+		w.collection = "logs"
+	}
+	if s := os.Getenv("MONGO_LOGS_CAPPED_SIZE_MB"); s != "" {
+		size, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || size <= 0 {
+			fmt.Printf("Invalid MONGO_LOGS_CAPPED_SIZE_MB: %s. MongoDB logging disabled.\n", s)
+			return nil
+		}
+		w.cappedSize = size << 20
+	}
+	if s := os.Getenv("MONGO_LOGS_TTL"); s != "" {
+		ttl, err := time.ParseDuration(s)
+		if err != nil || ttl < time.Second {
+			fmt.Printf("Invalid MONGO_LOGS_TTL: %s. MongoDB logging disabled.\n", s)
+			return nil
+		}
+		if w.cappedSize > 0 {
+			fmt.Println("MONGO_LOGS_TTL is ignored for capped collections.")
+		} else {
+			w.ttl = ttl
+		}
+	}
+
+	client, err := mongo.Connect(options.Client().ApplyURI(uri).SetTimeout(10 * time.Second))
+	if err != nil {
+		fmt.Printf("Invalid MONGO_LOGS_URI: %v. MongoDB logging disabled.\n", err)
+		return nil
+	}
+	w.client = client
+
+	w.batchWriter = newBatchWriter(batchConfig{
+		name:    "MongoDB",
+		send:    w.insert,
+		prepare: w.createCollection,
+	})
+	return w
+}
+
+// createCollection creates the collection, capped or with its TTL index, if
+// it does not exist, and the timestamp index.
+func (w *mongoWriter) createCollection() error {
+	ctx := context.Background()
+	db := w.client.Database(w.database)
+
+	names, err := db.ListCollectionNames(ctx, bson.D{{Key: "name", Value: w.collection}})
+	if err != nil {
+		return fmt.Errorf("failed to list MongoDB collections: %v", err)
+	}
+	if len(names) == 0 {
+		opts := options.CreateCollection()
+		if w.cappedSize > 0 {
+			opts.SetCapped(true).SetSizeInBytes(w.cappedSize)
+		}
+		if err := db.CreateCollection(ctx, w.collection, opts); err != nil {
+			return fmt.Errorf("failed to create MongoDB collection: %v", err)
+		}
+	}
+
+	index := mongo.IndexModel{Keys: bson.D{{Key: "timestamp", Value: 1}}}
+	if w.ttl > 0 {
+		index.Options = options.Index().SetExpireAfterSeconds(int32(w.ttl / time.Second))
+	}
+	if _, err := db.Collection(w.collection).Indexes().CreateOne(ctx, index); err != nil {
+		return fmt.Errorf("failed to create MongoDB index: %v", err)
+	}
+	return nil
+}
+
+// insert inserts a batch with a single unordered bulk insert.
+func (w *mongoWriter) insert(entries []map[string]interface{}) error {
+	docs := make([]interface{}, len(entries))
+	for i, entry := range entries {
+		row := newLogRow(entry)
+		docs[i] = bson.D{
+			{Key: "timestamp", Value: row.Time},
+			{Key: "level", Value: row.Level},
+			{Key: "service", Value: row.Service},
+			{Key: "host", Value: row.Host},
+			{Key: "message", Value: row.Message},
+			{Key: "fields", Value: row.Fields},
+		}
+	}
+
+	_, err := w.client.Database(w.database).Collection(w.collection).
+		InsertMany(context.Background(), docs, options.InsertMany().SetOrdered(false))
+	if err != nil {
+		return fmt.Errorf("failed to insert log entries: %v", err)
+	}
+	return nil
+}
+
+// Close sends the remaining entries and disconnects from MongoDB.
+func (w *mongoWriter) Close() error {
+	err := w.batchWriter.Close()
+	w.client.Disconnect(context.Background())
+	return err
+}
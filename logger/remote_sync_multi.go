@@ -0,0 +1,76 @@
+// sad-go-logger/logger/remote_sync_multi.go
+
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// multiRemoteSyncWriter fans entries out to several writers.
+type multiRemoteSyncWriter struct {
+	writers []RemoteSyncWriter
+}
+
+// NewMultiRemoteSyncWriter returns a writer duplicating every entry to each
+// of writers, such as several remote destinations fed by one logger or one
+// Replay. The writers are isolated from each other: a failing or panicking
+// writer loses its entries without affecting the others, and Write only
+// fails when every writer failed. Sync and Close, when the writers
+// implement io.Closer, reach every writer and join their errors. Nil
+// writers are skipped.
+func NewMultiRemoteSyncWriter(writers ...RemoteSyncWriter) RemoteSyncWriter {
+	w := &multiRemoteSyncWriter{}
+	for _, writer := range writers {
+		if writer != nil {
+			w.writers = append(w.writers, writer)
+		}
+	}
+	return w
+}
+
+func (w *multiRemoteSyncWriter) Write(p []byte) (n int, err error) {
+	var errs []error
+	for _, writer := range w.writers {
+		if err := isolate(func() error {
+			_, err := writer.Write(p)
+			return err
+		}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 && len(errs) == len(w.writers) {
+		return 0, errors.Join(errs...)
+	}
+	return len(p), nil
+}
+
+func (w *multiRemoteSyncWriter) Sync() error {
+	var errs []error
+	for _, writer := range w.writers {
+		errs = append(errs, isolate(writer.Sync))
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes the writers that implement io.Closer.
+func (w *multiRemoteSyncWriter) Close() error {
+	var errs []error
+	for _, writer := range w.writers {
+		if closer, ok := writer.(io.Closer); ok {
+			errs = append(errs, isolate(closer.Close))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// isolate calls fn, turning a panic into an error.
+func isolate(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("remote sync writer panicked: %v", r)
+		}
+	}()
+	return fn()
+}
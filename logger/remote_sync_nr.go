@@ -1,108 +1,169 @@
-// sad-go-logger/logger/remotesync_newrelic.go
-
-package logger
-
-import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"net/http"
-	"os"
-	"sync"
-	"time"
-)
-
-// NewRelicRemoteSyncWriter implements a writer that sends log entries to New Relic Logs API.
-type NewRelicRemoteSyncWriter struct {
-	apiKey    string
-	endpoint  string
-	client    *http.Client
-	buffer    []map[string]interface{}
-	batchSize int
-	mu        sync.Mutex
-}
-
-// NewNewRelicRemoteSyncWriter creates and returns a new NewRelicRemoteSyncWriter.
-func NewNewRelicRemoteSyncWriter() RemoteSyncWriter {
-	apiKey := os.Getenv("NEW_RELIC_API_KEY")
-	if apiKey == "" {
-		fmt.Println("NEW_RELIC_API_KEY not set. New Relic logging disabled.")
-		return nil
-	}
-
-	endpoint := os.Getenv("NEW_RELIC_LOGS_ENDPOINT")
-	if endpoint == "" {
-		endpoint = "https://log-api.newrelic.com/log/v1" // Default endpoint
-	}
-
-	return &NewRelicRemoteSyncWriter{
-		apiKey:    apiKey,
-		endpoint:  endpoint,
-		client:    &http.Client{Timeout: 10 * time.Second},
-		buffer:    make([]map[string]interface{}, 0, 100),
-		batchSize: 100, // Can be made configurable
-	}
-}
-
-func (w *NewRelicRemoteSyncWriter) Write(p []byte) (n int, err error) {
-	w.mu.Lock()
-	defer w.mu.Unlock()
-
-	var logEntry map[string]interface{}
-	decoder := json.NewDecoder(bytes.NewReader(p))
-	if err := decoder.Decode(&logEntry); err != nil {
-		return 0, fmt.Errorf("failed to decode log entry: %v", err)
-	}
-
-	w.buffer = append(w.buffer, logEntry)
-
-	if len(w.buffer) >= w.batchSize {
-		if err := w.flush(); err != nil {
-			return 0, err
-		}
-	}
-
-	return len(p), nil
-}
-
-func (w *NewRelicRemoteSyncWriter) flush() error {
-	if len(w.buffer) == 0 {
-		return nil
-	}
-
-	payload := map[string]interface{}{
-		"logs": w.buffer,
-	}
-
-	jsonPayload, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("failed to marshal log entries: %v", err)
-	}
-
-	req, err := http.NewRequest("POST", w.endpoint, bytes.NewBuffer(jsonPayload))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %v", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Api-Key", w.apiKey)
-
-	resp, err := w.client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to send logs to New Relic: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusAccepted {
-		return fmt.Errorf("new relic API returned unexpected status code: %d", resp.StatusCode)
-	}
-
-	w.buffer = w.buffer[:0] // Clear the buffer after successful send
-	return nil
-}
-
-func (w *NewRelicRemoteSyncWriter) Sync() error {
-	w.mu.Lock()
-	defer w.mu.Unlock()
-	return w.flush()
-}
+// sad-go-logger/logger/remotesync_newrelic.go
+
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// NewRelicRemoteSyncWriter implements a writer that sends log entries to New Relic Logs API.
+type NewRelicRemoteSyncWriter struct {
+	apiKey    string
+	endpoint  string
+	client    *http.Client
+	buffer    []map[string]interface{}
+	batchSize int
+	limiter   *rateLimiter
+	mu        sync.Mutex
+
+	// retry is the policy of a failed batch. attempts counts the failures of
+	// the buffered batch since failedAt; the next attempt waits for retryAt.
+	retry    RetryPolicy
+	attempts int
+	failedAt time.Time
+	retryAt  time.Time
+}
+
+// NewNewRelicRemoteSyncWriter creates and returns a new NewRelicRemoteSyncWriter.
+func NewNewRelicRemoteSyncWriter() RemoteSyncWriter {
+	apiKey := os.Getenv("NEW_RELIC_API_KEY")
+	if apiKey == "" {
+		fmt.Println("NEW_RELIC_API_KEY not set. New Relic logging disabled.")
+		return nil
+	}
+
+	return newNewRelicRemoteSyncWriter(apiKey, os.Getenv("NEW_RELIC_LOGS_ENDPOINT"))
+}
+
+// newNewRelicRemoteSyncWriter creates a writer for the New Relic Logs API at
+// endpoint, or at the default endpoint if it is empty.
+func newNewRelicRemoteSyncWriter(apiKey, endpoint string) *NewRelicRemoteSyncWriter {
+	if endpoint == "" {
+		endpoint = "https://log-api.newrelic.com/log/v1" // Default endpoint
+	}
+
+	return &NewRelicRemoteSyncWriter{
+		apiKey:    apiKey,
+		endpoint:  endpoint,
+		client:    &http.Client{Timeout: 10 * time.Second},
+		buffer:    make([]map[string]interface{}, 0, 100),
+		batchSize: 100, // Can be made configurable
+		retry:     DefaultRetryPolicy,
+	}
+}
+
+func (w *NewRelicRemoteSyncWriter) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buffer = append(w.buffer, decodeLogEntries(p)...)
+
+	if len(w.buffer) >= w.batchSize {
+		if err := w.flush(); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(p), nil
+}
+
+func (w *NewRelicRemoteSyncWriter) flush() error {
+	if len(w.buffer) == 0 || (w.limiter != nil && w.limiter.blocked()) || time.Now().Before(w.retryAt) {
+		return nil
+	}
+
+	payload := map[string]interface{}{
+		"logs": w.buffer,
+	}
+
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal log entries: %v", err)
+	}
+
+	// Over the rate limit, keep buffering until the next flush
+	if w.limiter != nil && !w.limiter.allow(len(jsonPayload)) {
+		return nil
+	}
+
+	req, err := http.NewRequest("POST", w.endpoint, bytes.NewBuffer(jsonPayload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Api-Key", w.apiKey)
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return w.failed(fmt.Errorf("failed to send logs to New Relic: %v", err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		return w.failed(&statusError{resp.StatusCode, fmt.Errorf("new relic API returned unexpected status code: %d", resp.StatusCode)})
+	}
+
+	w.buffer = w.buffer[:0] // Clear the buffer after successful send
+	w.attempts, w.retryAt = 0, time.Time{}
+	return nil
+}
+
+// failed records a failed attempt to send the buffer and schedules the
+// next one. The buffer is dropped once the retry policy gives up on it.
+func (w *NewRelicRemoteSyncWriter) failed(err error) error {
+	if w.attempts == 0 {
+		w.failedAt = time.Now()
+	}
+	w.attempts++
+	wait := w.retry.backoff(w.attempts)
+	if w.retry.retryable(err) && !w.retry.exhausted(w.attempts, w.failedAt, wait) {
+		w.retryAt = time.Now().Add(wait)
+		return err
+	}
+	fmt.Printf("New Relic dropped %d log entries after %d attempts: %v\n", len(w.buffer), w.attempts, err)
+	w.buffer = w.buffer[:0]
+	w.attempts, w.retryAt = 0, time.Time{}
+	return err
+}
+
+// setBatchSize implements batchedWriter.
+func (w *NewRelicRemoteSyncWriter) setBatchSize(n int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.batchSize = n
+}
+
+// setRetryPolicy implements retryingWriter.
+func (w *NewRelicRemoteSyncWriter) setRetryPolicy(p RetryPolicy) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.retry = p.withDefaults()
+}
+
+// setRateLimit implements rateLimitedWriter. Entries over the limit stay
+// buffered; the buffer is not capped, so spilling is not supported.
+func (w *NewRelicRemoteSyncWriter) setRateLimit(l *rateLimiter, spillPath string) error {
+	if spillPath != "" {
+		return fmt.Errorf("the newrelic sink does not support spilling, set LOG_RATE_LIMIT_NEWRELIC_OVERFLOW=queue")
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.limiter = l
+	return nil
+}
+
+// Sync sends the buffered entries without waiting for the backoff of a
+// failed batch.
+func (w *NewRelicRemoteSyncWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.retryAt = time.Time{}
+	return w.flush()
+}
@@ -0,0 +1,72 @@
+// sad-go-logger/logger/remote_sync_openobserve.go
+
+package logger
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/sadco-io/sad-go-logger/logger/logfile"
+)
+
+// NewOpenObserveRemoteSyncWriter creates and returns a writer sending
+// batches of log entries to the JSON ingestion API of an OpenObserve stream.
+// It reads configuration from environment variables:
+//   - OPENOBSERVE_URL: The server URL (default: "http://localhost:5080")
+//   - OPENOBSERVE_ORG: The organization (default: "default")
+//   - OPENOBSERVE_STREAM: The stream (default: "default")
+//   - OPENOBSERVE_USER and OPENOBSERVE_PASSWORD: Basic auth credentials
+//
+// If OPENOBSERVE_USER or OPENOBSERVE_PASSWORD is not set, it returns nil.
+func NewOpenObserveRemoteSyncWriter() RemoteSyncWriter {
+	user, password := os.Getenv("OPENOBSERVE_USER"), os.Getenv("OPENOBSERVE_PASSWORD")
+	if user == "" || password == "" {
+		fmt.Println("OPENOBSERVE_USER or OPENOBSERVE_PASSWORD not set. OpenObserve logging disabled.")
+		return nil
+	}
+	base := os.Getenv("OPENOBSERVE_URL")
+	if base == "" {
+		base = "http://localhost:5080"
+	}
+	org := os.Getenv("OPENOBSERVE_ORG")
+	if org == "" {
+		org = "default"
+	}
+	stream := os.Getenv("OPENOBSERVE_STREAM")
+	if stream == "" {
+		stream = "default"
+	}
+
+	return newHTTPBatchWriter(httpBatchConfig{
+		batchConfig: batchConfig{name: "OpenObserve"},
+		endpoint:    strings.TrimRight(base, "/") + "/api/" + url.PathEscape(org) + "/" + url.PathEscape(stream) + "/_json",
+		headers: map[string]string{
+			"Authorization": "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+password)),
+		},
+		encode: encodeOpenObserveRecords,
+		gzip:   true,
+	})
+}
+
+// encodeOpenObserveRecords renders a batch as a JSON array, the timestamp
+// of the entries moving to OpenObserve's _timestamp field, in microseconds.
+func encodeOpenObserveRecords(entries []map[string]interface{}) ([]byte, string, error) {
+	records := make([]map[string]interface{}, len(entries))
+	for i, entry := range entries {
+		record := make(map[string]interface{}, len(entry))
+		for key, value := range entry {
+			record[key] = value
+		}
+		if t, ok := logfile.Record(entry).Time(); ok {
+			record["_timestamp"] = t.UnixMicro()
+			delete(record, logfile.TimeKey)
+		}
+		records[i] = record
+	}
+	body, err := json.Marshal(records)
+	return body, "application/json", err
+}
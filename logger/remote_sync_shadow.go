@@ -0,0 +1,219 @@
+// sad-go-logger/logger/remote_sync_shadow.go
+
+package logger
+
+import (
+	"io"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// shadowQueueSize is the number of writes waiting for the shadow writer
+// before new ones are dropped.
+const shadowQueueSize = 1000
+
+// ShadowStats compares the primary and shadow writers of a ShadowWriter.
+// Only the writes duplicated to the shadow writer are compared.
+type ShadowStats struct {
+	// Writes is the number of writes to the primary writer.
+	Writes uint64 `json:"writes"`
+
+	// Shadowed is the number of writes duplicated to the shadow writer.
+	Shadowed uint64 `json:"shadowed"`
+
+	// Dropped is the number of writes selected for the shadow writer but
+	// dropped because it was falling behind.
+	Dropped uint64 `json:"dropped"`
+
+	// PrimaryErrors and ShadowErrors are the failed shadowed writes of each
+	// writer.
+	PrimaryErrors uint64 `json:"primaryErrors"`
+	ShadowErrors  uint64 `json:"shadowErrors"`
+
+	// PrimarySyncErrors and ShadowSyncErrors are the failed syncs of each
+	// writer. Batching writers report delivery failures there.
+	PrimarySyncErrors uint64 `json:"primarySyncErrors"`
+	ShadowSyncErrors  uint64 `json:"shadowSyncErrors"`
+
+	// Mismatches is the number of shadowed writes that succeeded on one
+	// writer only.
+	Mismatches uint64 `json:"mismatches"`
+
+	// PrimaryLatency and ShadowLatency are the average durations of the
+	// shadowed writes of each writer.
+	PrimaryLatency time.Duration `json:"primaryLatency"`
+	ShadowLatency  time.Duration `json:"shadowLatency"`
+}
+
+// shadowWrite is a write waiting for the shadow writer, with the outcome of
+// the primary write. A nil p requests a Sync.
+type shadowWrite struct {
+	p          []byte
+	primaryErr error
+}
+
+// ShadowWriter writes entries to a primary writer and duplicates a
+// percentage of them to a shadow writer, e.g. to try a new pipeline before
+// migrating to it. Create it with NewShadowWriter.
+type ShadowWriter struct {
+	primary, shadow RemoteSyncWriter
+	percent         float64
+
+	// mu guards the closing of queue against writes.
+	mu     sync.RWMutex
+	closed bool
+	queue  chan shadowWrite
+	done   chan struct{}
+
+	writes, shadowed, dropped           atomic.Uint64
+	primaryErrors, shadowErrors         atomic.Uint64
+	primarySyncErrors, shadowSyncErrors atomic.Uint64
+	mismatches                          atomic.Uint64
+	primaryLatency, shadowLatency       atomic.Int64
+	primaryLatencyN, shadowLatencyN     atomic.Uint64
+}
+
+// NewShadowWriter returns a writer writing every entry to primary and
+// duplicating percent (0 to 100) of the writes to shadow. The outcomes are
+// compared in Stats. The shadow writer never affects the primary path: it
+// is written from its own goroutine, its writes are dropped when it falls
+// behind, and its errors and panics are only counted.
+func NewShadowWriter(primary, shadow RemoteSyncWriter, percent float64) *ShadowWriter {
+	w := &ShadowWriter{
+		primary: primary,
+		shadow:  shadow,
+		percent: percent,
+		queue:   make(chan shadowWrite, shadowQueueSize),
+		done:    make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Write implements the io.Writer interface. It returns the outcome of the
+// primary writer.
+func (w *ShadowWriter) Write(p []byte) (n int, err error) {
+	w.writes.Add(1)
+	if w.percent <= 0 || (w.percent < 100 && rand.Float64()*100 >= w.percent) {
+		return w.primary.Write(p)
+	}
+
+	start := time.Now()
+	n, err = w.primary.Write(p)
+	w.primaryLatency.Add(int64(time.Since(start)))
+	w.primaryLatencyN.Add(1)
+	if err != nil {
+		w.primaryErrors.Add(1)
+	}
+	w.enqueue(shadowWrite{p: append([]byte(nil), p...), primaryErr: err})
+	return n, err
+}
+
+func (w *ShadowWriter) enqueue(sw shadowWrite) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return
+	}
+	select {
+	case w.queue <- sw:
+		if sw.p != nil {
+			w.shadowed.Add(1)
+		}
+	default:
+		if sw.p != nil {
+			w.dropped.Add(1)
+		}
+	}
+}
+
+// run writes the queued writes to the shadow writer.
+func (w *ShadowWriter) run() {
+	defer close(w.done)
+	for sw := range w.queue {
+		if sw.p == nil {
+			if err := isolate(w.shadow.Sync); err != nil {
+				w.shadowSyncErrors.Add(1)
+			}
+			continue
+		}
+
+		start := time.Now()
+		err := isolate(func() error {
+			_, err := w.shadow.Write(sw.p)
+			return err
+		})
+		w.shadowLatency.Add(int64(time.Since(start)))
+		w.shadowLatencyN.Add(1)
+		if err != nil {
+			w.shadowErrors.Add(1)
+		}
+		if (err == nil) != (sw.primaryErr == nil) {
+			w.mismatches.Add(1)
+		}
+	}
+}
+
+// Stats returns a snapshot of the comparison of the two writers.
+func (w *ShadowWriter) Stats() ShadowStats {
+	s := ShadowStats{
+		Writes:        w.writes.Load(),
+		Shadowed:      w.shadowed.Load(),
+		Dropped:       w.dropped.Load(),
+		PrimaryErrors: w.primaryErrors.Load(),
+		ShadowErrors:  w.shadowErrors.Load(),
+		Mismatches:    w.mismatches.Load(),
+
+		PrimarySyncErrors: w.primarySyncErrors.Load(),
+		ShadowSyncErrors:  w.shadowSyncErrors.Load(),
+	}
+	if n := w.primaryLatencyN.Load(); n > 0 {
+		s.PrimaryLatency = time.Duration(w.primaryLatency.Load() / int64(n))
+	}
+	if n := w.shadowLatencyN.Load(); n > 0 {
+		s.ShadowLatency = time.Duration(w.shadowLatency.Load() / int64(n))
+	}
+	return s
+}
+
+// Sync implements the zapcore.WriteSyncer interface. It syncs the primary
+// writer, and requests a sync of the shadow writer without waiting for it.
+func (w *ShadowWriter) Sync() error {
+	err := w.primary.Sync()
+	if err != nil {
+		w.primarySyncErrors.Add(1)
+	}
+	if w.percent > 0 {
+		w.enqueue(shadowWrite{})
+	}
+	return err
+}
+
+// Close closes the primary writer, then writes the queued writes to the
+// shadow writer and closes it, when they implement io.Closer. Only the
+// error of the primary writer is returned.
+func (w *ShadowWriter) Close() error {
+	var err error
+	if closer, ok := w.primary.(io.Closer); ok {
+		err = closer.Close()
+	}
+
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return err
+	}
+	w.closed = true
+	close(w.queue)
+	w.mu.Unlock()
+
+	<-w.done
+	if closer, ok := w.shadow.(io.Closer); ok {
+		if isolate(closer.Close) != nil {
+			w.shadowSyncErrors.Add(1)
+		}
+	}
+	return err
+}
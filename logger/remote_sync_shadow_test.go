@@ -0,0 +1,85 @@
+// sad-go-logger/logger/remote_sync_shadow_test.go
+
+package logger
+
+import (
+	"testing"
+)
+
+// panicWriter is a RemoteSyncWriter panicking on every call.
+type panicWriter struct{}
+
+func (panicWriter) Write([]byte) (int, error) { panic("shadow pipeline bug") }
+
+func (panicWriter) Sync() error { panic("shadow pipeline bug") }
+
+func TestShadowWriterDuplicatesWrites(t *testing.T) {
+	primary, shadow := &recordingWriter{}, &recordingWriter{}
+	w := NewShadowWriter(primary, shadow, 100)
+
+	for _, p := range []string{"a\n", "b\n", "c\n"} {
+		if _, err := w.Write([]byte(p)); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	w.Close()
+
+	if primary.String() != "a\nb\nc\n" || shadow.String() != "a\nb\nc\n" {
+		t.Errorf("primary got %q and shadow %q, want every write in both", primary.String(), shadow.String())
+	}
+	if s := w.Stats(); s.Writes != 3 || s.Shadowed != 3 || s.Mismatches != 0 {
+		t.Errorf("Stats() = %+v, want 3 writes, all shadowed and matching", s)
+	}
+}
+
+func TestShadowWriterDisabled(t *testing.T) {
+	primary, shadow := &recordingWriter{}, &recordingWriter{}
+	w := NewShadowWriter(primary, shadow, 0)
+
+	w.Write([]byte("a\n"))
+	w.Sync()
+	w.Close()
+
+	if shadow.String() != "" {
+		t.Errorf("shadow got %q at 0%%", shadow.String())
+	}
+	if s := w.Stats(); s.Writes != 1 || s.Shadowed != 0 {
+		t.Errorf("Stats() = %+v, want 1 write, none shadowed", s)
+	}
+}
+
+func TestShadowWriterIsolatesTheShadow(t *testing.T) {
+	primary := &recordingWriter{}
+	w := NewShadowWriter(primary, panicWriter{}, 100)
+
+	if _, err := w.Write([]byte("a\n")); err != nil {
+		t.Errorf("Write returned %v, want the outcome of the primary", err)
+	}
+	if err := w.Sync(); err != nil {
+		t.Errorf("Sync returned %v, want the outcome of the primary", err)
+	}
+	w.Close()
+
+	if primary.String() != "a\n" {
+		t.Errorf("primary got %q", primary.String())
+	}
+	if s := w.Stats(); s.ShadowErrors != 1 || s.ShadowSyncErrors != 1 || s.Mismatches != 1 {
+		t.Errorf("Stats() = %+v, want the shadow panics counted as errors", s)
+	}
+}
+
+func TestShadowWriterReportsPrimaryErrors(t *testing.T) {
+	primary := &switchWriter{}
+	primary.down.Store(true)
+	shadow := &recordingWriter{}
+	w := NewShadowWriter(primary, shadow, 100)
+
+	if _, err := w.Write([]byte("a\n")); err == nil {
+		t.Error("Write hid the error of the primary")
+	}
+	w.Close()
+
+	if s := w.Stats(); s.PrimaryErrors != 1 || s.Mismatches != 1 {
+		t.Errorf("Stats() = %+v, want the primary error and the mismatch", s)
+	}
+}
@@ -0,0 +1,57 @@
+// sad-go-logger/logger/remote_sync_sumo.go
+
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// NewSumoLogicRemoteSyncWriter creates and returns a writer sending gzipped
+// batches of log entries, one JSON object per line, to a Sumo Logic hosted
+// collector HTTP source. It reads configuration from environment variables:
+//   - SUMO_LOGIC_ENDPOINT: The unique URL of the HTTP source
+//   - SUMO_LOGIC_CATEGORY: Overrides the source category (optional)
+//   - SUMO_LOGIC_HOST: Overrides the source host (optional)
+//   - SUMO_LOGIC_NAME: Overrides the source name (optional)
+//
+// If SUMO_LOGIC_ENDPOINT is not set, it returns nil.
+func NewSumoLogicRemoteSyncWriter() RemoteSyncWriter {
+	endpoint := os.Getenv("SUMO_LOGIC_ENDPOINT")
+	if endpoint == "" {
+		fmt.Println("SUMO_LOGIC_ENDPOINT not set. Sumo Logic logging disabled.")
+		return nil
+	}
+
+	headers := map[string]string{}
+	for header, env := range map[string]string{
+		"X-Sumo-Category": "SUMO_LOGIC_CATEGORY",
+		"X-Sumo-Host":     "SUMO_LOGIC_HOST",
+		"X-Sumo-Name":     "SUMO_LOGIC_NAME",
+	} {
+		if value := os.Getenv(env); value != "" {
+			headers[header] = value
+		}
+	}
+	return newHTTPBatchWriter(httpBatchConfig{
+		batchConfig: batchConfig{name: "Sumo Logic"},
+		endpoint:    endpoint,
+		headers:     headers,
+		encode:      encodeJSONLines,
+		gzip:        true,
+	})
+}
+
+// encodeJSONLines renders a batch as one JSON object per line.
+func encodeJSONLines(entries []map[string]interface{}) ([]byte, string, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, entry := range entries {
+		if err := enc.Encode(entry); err != nil {
+			return nil, "", err
+		}
+	}
+	return buf.Bytes(), "application/x-ndjson", nil
+}
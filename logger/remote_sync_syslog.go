@@ -0,0 +1,186 @@
+// sad-go-logger/logger/remote_sync_syslog.go
+
+package logger
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sadco-io/sad-go-logger/logger/logfile"
+)
+
+// syslogFacilities are the facility names accepted by SYSLOG_FACILITY.
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5,
+	"lpr": 6, "news": 7, "uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// syslogSeverities maps the entry levels to syslog severities.
+var syslogSeverities = map[string]int{
+	"DEBUG":  7,
+	"INFO":   6,
+	"WARN":   4,
+	"ERROR":  3,
+	"DPANIC": 2,
+	"PANIC":  2,
+	"FATAL":  2,
+}
+
+// syslogConfig describes a syslog destination.
+type syslogConfig struct {
+	name     string
+	address  string
+	useTLS   bool
+	facility int
+
+	// hostname and appName identify the system and program of the
+	// messages.
+	hostname string
+	appName  string
+
+	// token, when set, is sent as a structured data element,
+	// [token@41058], the way hosted services identify their customers.
+	token string
+}
+
+// NewSyslogRemoteSyncWriter creates and returns a writer sending log
+// entries to a remote syslog server over TCP, optionally with TLS, as
+// RFC 5424 messages with octet-counting framing (RFC 6587). The message of
+// each is the entry as JSON. It reads configuration from environment
+// variables:
+//   - SYSLOG_ADDRESS: The server, as host:port
+//   - SYSLOG_USE_TLS: Set to "true" to enable TLS
+//   - SYSLOG_FACILITY: The facility name (default: "user")
+//   - SYSLOG_HOSTNAME: The system name (default: the host name)
+//   - SYSLOG_APP_NAME: The program name (default: the SERVICE_NAME)
+//   - SYSLOG_TOKEN: A customer token, for hosted services requiring one
+//
+// If SYSLOG_ADDRESS is not set, it returns nil.
+func NewSyslogRemoteSyncWriter() RemoteSyncWriter {
+	address := os.Getenv("SYSLOG_ADDRESS")
+	if address == "" {
+		fmt.Println("SYSLOG_ADDRESS not set. Syslog logging disabled.")
+		return nil
+	}
+	facility := 1
+	if name := os.Getenv("SYSLOG_FACILITY"); name != "" {
+		f, ok := syslogFacilities[name]
+		if !ok {
+			fmt.Printf("Invalid SYSLOG_FACILITY: %s. Syslog logging disabled.\n", name)
+			return nil
+		}
+		facility = f
+	}
+	return newSyslogWriter(syslogConfig{
+		name:     "syslog",
+		address:  address,
+		useTLS:   os.Getenv("SYSLOG_USE_TLS") == "true",
+		facility: facility,
+		hostname: os.Getenv("SYSLOG_HOSTNAME"),
+		appName:  os.Getenv("SYSLOG_APP_NAME"),
+		token:    os.Getenv("SYSLOG_TOKEN"),
+	})
+}
+
+// NewPapertrailRemoteSyncWriter creates and returns a syslog writer for a
+// Papertrail log destination, or any hosted service accepting syslog over
+// TCP with TLS. It reads configuration from environment variables:
+//   - PAPERTRAIL_HOST and PAPERTRAIL_PORT: The log destination, e.g.
+//     "logs5.papertrailapp.com" and "12345"
+//   - PAPERTRAIL_SYSTEM: The system name (default: the host name)
+//   - PAPERTRAIL_TOKEN: A customer token, for services requiring one
+//
+// The program name is the SERVICE_NAME. If PAPERTRAIL_HOST or
+// PAPERTRAIL_PORT is not set, it returns nil.
+func NewPapertrailRemoteSyncWriter() RemoteSyncWriter {
+	host, port := os.Getenv("PAPERTRAIL_HOST"), os.Getenv("PAPERTRAIL_PORT")
+	if host == "" || port == "" {
+		fmt.Println("PAPERTRAIL_HOST or PAPERTRAIL_PORT not set. Papertrail logging disabled.")
+		return nil
+	}
+	return newSyslogWriter(syslogConfig{
+		name:     "Papertrail",
+		address:  net.JoinHostPort(host, port),
+		useTLS:   true,
+		facility: 1,
+		hostname: os.Getenv("PAPERTRAIL_SYSTEM"),
+		token:    os.Getenv("PAPERTRAIL_TOKEN"),
+	})
+}
+
+func newSyslogWriter(cfg syslogConfig) *connWriter {
+	if cfg.hostname == "" {
+		cfg.hostname = hostname
+	}
+	if cfg.appName == "" {
+		cfg.appName = os.Getenv("SERVICE_NAME")
+	}
+	serverName, _, _ := net.SplitHostPort(cfg.address)
+
+	return newConnWriter(connConfig{
+		batchConfig: batchConfig{name: cfg.name, flushInterval: time.Second},
+		dial: func() (net.Conn, error) {
+			dialer := &net.Dialer{Timeout: 10 * time.Second}
+			if cfg.useTLS {
+				return tls.DialWithDialer(dialer, "tcp", cfg.address, &tls.Config{ServerName: serverName})
+			}
+			return dialer.Dial("tcp", cfg.address)
+		},
+		frame: cfg.frame,
+	})
+}
+
+// frame renders an entry as an octet-counted RFC 5424 message.
+func (cfg syslogConfig) frame(entry map[string]interface{}) ([]byte, error) {
+	msg, err := json.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	severity, ok := syslogSeverities[logfile.FormatValue(entry[logfile.LevelKey])]
+	if !ok {
+		severity = 6
+	}
+	t, ok := logfile.Record(entry).Time()
+	if !ok {
+		t = time.Now()
+	}
+	sd := "-"
+	if cfg.token != "" {
+		sd = "[" + cfg.token + "@41058]"
+	}
+
+	line := fmt.Sprintf("<%d>1 %s %s %s - - %s %s",
+		cfg.facility*8+severity,
+		t.UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		syslogHeaderField(cfg.hostname, 255),
+		syslogHeaderField(cfg.appName, 48),
+		sd, msg)
+	return []byte(strconv.Itoa(len(line)) + " " + line), nil
+}
+
+// syslogHeaderField makes s a valid header field of at most max characters:
+// printable ASCII without spaces, or "-" when empty.
+func syslogHeaderField(s string, max int) string {
+	s = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' {
+			return '_'
+		}
+		return r
+	}, s)
+	if s == "" {
+		return "-"
+	}
+	if len(s) > max {
+		s = s[:max]
+	}
+	return s
+}
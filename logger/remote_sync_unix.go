@@ -0,0 +1,38 @@
+// sad-go-logger/logger/remote_sync_unix.go
+
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"time"
+)
+
+// NewUnixSocketRemoteSyncWriter creates and returns a writer sending log
+// entries as NDJSON to a Unix domain stream socket, typically that of a
+// local agent or sidecar. Entries are buffered while the socket is
+// unavailable, and the connection is retried with backoff. It reads
+// configuration from environment variables:
+//   - UNIX_SOCKET_PATH: The path of the socket
+//
+// If UNIX_SOCKET_PATH is not set, it returns nil.
+func NewUnixSocketRemoteSyncWriter() RemoteSyncWriter {
+	path := os.Getenv("UNIX_SOCKET_PATH")
+	if path == "" {
+		fmt.Println("UNIX_SOCKET_PATH not set. Unix socket logging disabled.")
+		return nil
+	}
+
+	return newConnWriter(connConfig{
+		batchConfig: batchConfig{name: "Unix socket", flushInterval: time.Second},
+		dial: func() (net.Conn, error) {
+			return net.DialTimeout("unix", path, 10*time.Second)
+		},
+		frame: func(entry map[string]interface{}) ([]byte, error) {
+			line, err := json.Marshal(entry)
+			return append(line, '\n'), err
+		},
+	})
+}
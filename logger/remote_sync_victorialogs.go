@@ -0,0 +1,76 @@
+// sad-go-logger/logger/remote_sync_victorialogs.go
+
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sadco-io/sad-go-logger/logger/logfile"
+)
+
+// NewVictoriaLogsRemoteSyncWriter creates and returns a writer sending
+// batches of log entries to the JSON lines ingestion endpoint of
+// VictoriaLogs. Entries are grouped into log streams by the service name
+// and host, so that queries can select a stream cheaply. It reads
+// configuration from environment variables:
+//   - VICTORIALOGS_URL: The server URL (default: "http://localhost:9428")
+//   - VICTORIALOGS_STREAM_FIELDS: Comma-separated fields identifying the
+//     stream of an entry (default: "serviceName,hostname")
+//   - VICTORIALOGS_ACCOUNT_ID and VICTORIALOGS_PROJECT_ID: The tenant
+//     (optional)
+func NewVictoriaLogsRemoteSyncWriter() RemoteSyncWriter {
+	base := os.Getenv("VICTORIALOGS_URL")
+	if base == "" {
+		base = "http://localhost:9428"
+	}
+	streamFields := os.Getenv("VICTORIALOGS_STREAM_FIELDS")
+	if streamFields == "" {
+		streamFields = "serviceName,hostname"
+	}
+
+	params := url.Values{}
+	params.Set("_stream_fields", streamFields)
+	params.Set("_msg_field", logfile.MessageKey)
+	params.Set("_time_field", "_time")
+
+	headers := map[string]string{}
+	if id := os.Getenv("VICTORIALOGS_ACCOUNT_ID"); id != "" {
+		headers["AccountID"] = id
+	}
+	if id := os.Getenv("VICTORIALOGS_PROJECT_ID"); id != "" {
+		headers["ProjectID"] = id
+	}
+	return newHTTPBatchWriter(httpBatchConfig{
+		batchConfig: batchConfig{name: "VictoriaLogs"},
+		endpoint:    strings.TrimRight(base, "/") + "/insert/jsonline?" + params.Encode(),
+		headers:     headers,
+		encode:      encodeVictoriaLogsLines,
+		gzip:        true,
+	})
+}
+
+// encodeVictoriaLogsLines renders a batch as JSON lines, the timestamp of
+// the entries moving to the _time field in RFC 3339.
+func encodeVictoriaLogsLines(entries []map[string]interface{}) ([]byte, string, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, entry := range entries {
+		line := make(map[string]interface{}, len(entry))
+		for key, value := range entry {
+			line[key] = value
+		}
+		if t, ok := logfile.Record(entry).Time(); ok {
+			line["_time"] = t.UTC().Format(time.RFC3339Nano)
+			delete(line, logfile.TimeKey)
+		}
+		if err := enc.Encode(line); err != nil {
+			return nil, "", err
+		}
+	}
+	return buf.Bytes(), "application/stream+json", nil
+}
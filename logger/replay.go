@@ -0,0 +1,56 @@
+// sad-go-logger/logger/replay.go
+
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/sadco-io/sad-go-logger/logger/logfile"
+)
+
+// Filter selects log records, see logfile.Filter.
+type Filter = logfile.Filter
+
+// Replay re-reads the JSON log file at path and ships the records matching
+// filter through sink, for backfilling a remote destination after an outage
+// where only the local files survived. The original timestamp of each
+// record is kept as @timestamp. The sink is synced, and closed if it
+// implements io.Closer, once the file is read. Replay returns the number of
+// records shipped.
+func Replay(path string, sink RemoteSyncWriter, filter Filter) (int, error) {
+	count := 0
+	err := logfile.ScanFile(path, func(r logfile.Record) error {
+		if !filter.Match(r) {
+			return nil
+		}
+		if t, ok := r.Time(); ok {
+			if _, exists := r["@timestamp"]; !exists {
+				r["@timestamp"] = t.UTC().Format(time.RFC3339Nano)
+			}
+		}
+
+		line, err := json.Marshal(r)
+		if err != nil {
+			return fmt.Errorf("failed to marshal log entry: %v", err)
+		}
+		if _, err := sink.Write(append(line, '\n')); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		return count, err
+	}
+
+	if err := sink.Sync(); err != nil {
+		return count, err
+	}
+	if closer, ok := sink.(io.Closer); ok {
+		return count, closer.Close()
+	}
+	return count, nil
+}
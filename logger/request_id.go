@@ -0,0 +1,86 @@
+// sad-go-logger/logger/request_id.go
+
+package logger
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// RequestIDHeader is the header carrying the id of a request, read and
+// written by RequestIDMiddleware.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDKey is the key of the request id field.
+const RequestIDKey = "request_id"
+
+// maxRequestIDLength bounds the ids accepted from clients.
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// RequestIDMiddleware gives every request an id: the one of its
+// X-Request-ID header, set by a proxy or the calling service, or else a
+// new UUID. The id is returned in the X-Request-ID response header, stored
+// in the request context, see RequestIDFromContext, and added as the
+// request_id field to the request logger, so that every entry logged
+// through FromContext while serving the request carries it. Ids longer
+// than 128 bytes or with characters other than printable ASCII are
+// replaced. AccessLogMiddleware writes the id to the access log, whichever
+// of the two wraps the other.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := RequestID(r.Header.Get(RequestIDHeader))
+		w.Header().Set(RequestIDHeader, id)
+
+		ctx := ContextWithRequestID(requestContext(r), id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// NewRequestID returns a new random request id, a UUID.
+func NewRequestID() string {
+	return uuid.NewString()
+}
+
+// RequestID returns the request id of the X-Request-ID header value v: v
+// itself, unless it is empty or not a valid id, as RequestIDMiddleware
+// checks, and then a new one, for the middlewares of other frameworks.
+func RequestID(v string) string {
+	if !validRequestID(v) {
+		return NewRequestID()
+	}
+	return v
+}
+
+// ContextWithRequestID returns a copy of ctx carrying the request id id,
+// and the logger of FromContext(ctx) with the request_id field, e.g. for
+// the consumers of a queue propagating the id of the producer. Outgoing
+// requests should forward it in the X-Request-ID header.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	ctx = context.WithValue(ctx, requestIDKey{}, id)
+	return ToContext(ctx, zap.String(RequestIDKey, id))
+}
+
+// RequestIDFromContext returns the request id carried by ctx, or "" if
+// there is none.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// validRequestID reports whether id is worth keeping as a request id.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,54 @@
+// sad-go-logger/logger/request_id_test.go
+
+package logger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	remote := &recordingWriter{}
+	useRemoteTestLogger(t, remote)
+
+	var seen string
+	handler := RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestIDFromContext(r.Context())
+		FromContext(withSpan(t, r.Context(), testTraceID, testChildID)).Warn("in span")
+	}))
+
+	for _, tt := range []struct {
+		header string
+		keep   bool
+	}{
+		{"req-42", true},
+		{"", false},
+		{"with space", false},
+		{strings.Repeat("x", maxRequestIDLength+1), false},
+	} {
+		remote.mu.Lock()
+		remote.buf.Reset()
+		remote.mu.Unlock()
+
+		req := httptest.NewRequest("GET", "/", nil)
+		if tt.header != "" {
+			req.Header.Set(RequestIDHeader, tt.header)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		id := rec.Header().Get(RequestIDHeader)
+		if (id == tt.header) != tt.keep || !validRequestID(id) {
+			t.Errorf("header %q: got the id %q, want it kept: %v", tt.header, id, tt.keep)
+		}
+		if seen != id {
+			t.Errorf("header %q: RequestIDFromContext() = %q, want %q", tt.header, seen, id)
+		}
+		out := remote.String()
+		if !strings.Contains(out, `"request_id":"`+id+`"`) || !strings.Contains(out, `"span_id":"`+testChildID+`"`) {
+			t.Errorf("header %q: logged %s, want the request_id and the span of the handler", tt.header, out)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+// sad-go-logger/logger/retention.go
+
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// startMill starts the goroutine compressing and expiring the rotated files,
+// if the rotation asks for it, and runs it once for the files left by
+// previous runs.
+func (r *rotatingFile) startMill() {
+	if !r.rotation.Compress && r.rotation.MaxAge <= 0 && r.rotation.MaxTotalSize <= 0 {
+		return
+	}
+	r.mill = make(chan struct{}, 1)
+	go func(wake <-chan struct{}) {
+		r.runMill()
+		for range wake {
+			r.runMill()
+		}
+	}(r.mill)
+}
+
+// wakeMill runs the mill again once it is done, if it is running. It is
+// called with r.mu held.
+func (r *rotatingFile) wakeMill() {
+	if r.mill == nil {
+		return
+	}
+	select {
+	case r.mill <- struct{}{}:
+	default:
+	}
+}
+
+// rotatedFile is a file rotated out of a rotatingFile.
+type rotatedFile struct {
+	path string
+	info os.FileInfo
+}
+
+// runMill compresses the rotated files, then deletes those past MaxAge or
+// MaxTotalSize.
+func (r *rotatingFile) runMill() {
+	r.mu.Lock()
+	current := r.path
+	r.mu.Unlock()
+
+	r.millMu.Lock()
+	defer r.millMu.Unlock()
+
+	rotated := r.rotatedFiles(current)
+	if r.rotation.Compress {
+		for i, f := range rotated {
+			if strings.HasSuffix(f.path, ".gz") {
+				continue
+			}
+			if err := compressFile(f.path, f.info); err != nil {
+				fmt.Printf("Failed to compress %s: %v\n", f.path, err)
+				continue
+			}
+			if info, err := os.Stat(f.path + ".gz"); err == nil {
+				rotated[i] = rotatedFile{path: f.path + ".gz", info: info}
+			}
+		}
+	}
+
+	// Delete the oldest files first.
+	sort.Slice(rotated, func(i, j int) bool {
+		return rotated[i].info.ModTime().After(rotated[j].info.ModTime())
+	})
+	var total int64
+	for _, f := range rotated {
+		total += f.info.Size()
+		expired := r.rotation.MaxAge > 0 && time.Since(f.info.ModTime()) > r.rotation.MaxAge
+		overBudget := r.rotation.MaxTotalSize > 0 && total > r.rotation.MaxTotalSize
+		if expired || overBudget {
+			if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+				fmt.Printf("Failed to delete %s: %v\n", f.path, err)
+			}
+		}
+	}
+}
+
+// rotatedFiles returns the files rotated out of r, current being the path
+// written: the size backups, <file>.1 and so on, and with a schedule the
+// files of the previous periods and their backups, compressed or not.
+func (r *rotatingFile) rotatedFiles(current string) []rotatedFile {
+	dir, name := filepath.Split(r.base)
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	backup := regexp.MustCompile(`^` + regexp.QuoteMeta(name) + `\.\d+(\.gz)?$`)
+	dated := regexp.MustCompile(`^` + regexp.QuoteMeta(stem) + `-(\d{4}-\d{2}-\d{2}(?:-\d{2})?)` + regexp.QuoteMeta(ext) + `(\.\d+)?(\.gz)?$`)
+	currentDate := ""
+	if m := dated.FindStringSubmatch(filepath.Base(current)); m != nil {
+		currentDate = m[1]
+	}
+
+	entries, err := os.ReadDir(filepath.Clean(dir))
+	if err != nil {
+		return nil
+	}
+	var files []rotatedFile
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		if m := dated.FindStringSubmatch(e.Name()); m != nil {
+			// The files of the current period, or of a later one after a
+			// roll-over, are still written.
+			if m[2] == "" && m[3] == "" && m[1] >= currentDate {
+				continue
+			}
+		} else if !backup.MatchString(e.Name()) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, rotatedFile{path: filepath.Join(dir, e.Name()), info: info})
+	}
+	return files
+}
+
+// compressFile gzips the file at path, described by info, to path.gz, with
+// the same permission and modification time, then deletes it.
+func compressFile(path string, info os.FileInfo) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp := path + ".gz.tmp"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(out)
+	gz.Name = filepath.Base(path)
+	gz.ModTime = info.ModTime()
+	_, err = io.Copy(gz, in)
+	if closeErr := gz.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chtimes(tmp, info.ModTime(), info.ModTime())
+	}
+	if err == nil {
+		err = os.Rename(tmp, path+".gz")
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	in.Close()
+	return os.Remove(path)
+}
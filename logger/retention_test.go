@@ -0,0 +1,100 @@
+// sad-go-logger/logger/retention_test.go
+
+package logger
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"testing"
+	"time"
+)
+
+// writeBackup creates the rotated file path with content, last modified age
+// ago.
+func writeBackup(t *testing.T, path, content string, age time.Duration) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	at := time.Now().Add(-age)
+	if err := os.Chtimes(path, at, at); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestRetentionCompressesBackups(t *testing.T) {
+	r := newTestRotatingFile(t, Rotation{MaxSize: 10, MaxBackups: 2, Compress: true})
+	writeBackup(t, r.path+".1", "rotated\n", time.Minute)
+
+	r.runMill()
+
+	if exists(r.path + ".1") {
+		t.Error("logs.txt.1 kept next to its compressed copy")
+	}
+	f, err := os.Open(r.path + ".1.gz")
+	if err != nil {
+		t.Fatalf("logs.txt.1 not compressed: %v", err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+	if b, _ := io.ReadAll(gz); string(b) != "rotated\n" {
+		t.Errorf("logs.txt.1.gz holds %q, want the rotated entries", b)
+	}
+	if !exists(r.path) {
+		t.Error("the file being written was touched")
+	}
+}
+
+func TestRetentionDeletesExpiredBackups(t *testing.T) {
+	r := newTestRotatingFile(t, Rotation{MaxBackups: 3, MaxAge: time.Hour})
+	writeBackup(t, r.path+".1", "recent\n", time.Minute)
+	writeBackup(t, r.path+".2", "expired\n", 2*time.Hour)
+	os.Chtimes(r.path, time.Now().Add(-3*time.Hour), time.Now().Add(-3*time.Hour))
+
+	r.runMill()
+
+	if !exists(r.path+".1") || exists(r.path+".2") {
+		t.Error("want logs.txt.1 kept and logs.txt.2 deleted")
+	}
+	if !exists(r.path) {
+		t.Error("the file being written was deleted")
+	}
+}
+
+func TestRetentionBoundsTotalSize(t *testing.T) {
+	r := newTestRotatingFile(t, Rotation{MaxBackups: 3, MaxTotalSize: 10})
+	writeBackup(t, r.path+".1", "newest\n", time.Minute)
+	writeBackup(t, r.path+".2", "older\n", 2*time.Minute)
+	writeBackup(t, r.path+".3", "oldest\n", 3*time.Minute)
+
+	r.runMill()
+
+	if !exists(r.path+".1") || exists(r.path+".2") || exists(r.path+".3") {
+		t.Error("want the oldest backups deleted past 10 bytes, logs.txt.1 kept")
+	}
+}
+
+func TestRetentionSkipsTheCurrentPeriod(t *testing.T) {
+	r := newTestRotatingFile(t, Rotation{Schedule: "daily", MaxAge: time.Hour})
+	yesterday, _ := r.rotation.period(r.base, time.Now().AddDate(0, 0, -1))
+	writeBackup(t, yesterday, "yesterday\n", 25*time.Hour)
+	os.Chtimes(r.path, time.Now().Add(-2*time.Hour), time.Now().Add(-2*time.Hour))
+
+	r.runMill()
+
+	if exists(yesterday) {
+		t.Error("the file of the previous period was not expired")
+	}
+	if !exists(r.path) {
+		t.Error("the file of the current period was deleted")
+	}
+}
@@ -0,0 +1,188 @@
+// sad-go-logger/logger/retry.go
+
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// RetryPolicy describes how a remote sink retries a failed delivery. Zero
+// fields take the values of DefaultRetryPolicy.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts of a delivery, the first one
+	// included. A negative value retries until Deadline, if any.
+	MaxAttempts int
+
+	// InitialBackoff is the wait before the first retry. It doubles on every
+	// retry, up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	// Jitter randomizes every wait by up to this fraction of it, between 0
+	// and 1, so that instances do not retry in lockstep.
+	Jitter float64
+
+	// RetryableStatusCodes are the HTTP status codes worth retrying; the
+	// other failed requests are dropped. Nil means 408, 429 and the 5xx
+	// codes.
+	RetryableStatusCodes []int
+
+	// Retryable, when set, decides which errors are retried instead of the
+	// status codes. Network errors are retried by default.
+	Retryable func(err error) bool
+
+	// Deadline, when positive, bounds the time spent on a delivery, from
+	// its first attempt.
+	Deadline time.Duration
+}
+
+// DefaultRetryPolicy is the policy of the remote sinks without one in
+// Config.RetryPolicies, except ELK, which reconnects every 5 seconds for as
+// long as Logstash is down.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    4,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     30 * time.Second,
+}
+
+// withDefaults returns p, its zero fields set from DefaultRetryPolicy.
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts == 0 {
+		p.MaxAttempts = DefaultRetryPolicy.MaxAttempts
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = DefaultRetryPolicy.InitialBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = max(DefaultRetryPolicy.MaxBackoff, p.InitialBackoff)
+	}
+	if p.Jitter < 0 || p.Jitter > 1 {
+		p.Jitter = DefaultRetryPolicy.Jitter
+	}
+	return p
+}
+
+// backoff returns the wait before the retry following attempt, counted
+// from 1.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.InitialBackoff
+	for i := 1; i < attempt && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	d = min(d, p.MaxBackoff)
+	if p.Jitter > 0 {
+		d += time.Duration(float64(d) * p.Jitter * (2*rand.Float64() - 1))
+	}
+	return d
+}
+
+// retryable reports whether a delivery that failed with err is worth
+// retrying. Permanent errors never are.
+func (p RetryPolicy) retryable(err error) bool {
+	var permanent *permanentError
+	if errors.As(err, &permanent) {
+		return false
+	}
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	var status *statusError
+	if !errors.As(err, &status) {
+		return true
+	}
+	if p.RetryableStatusCodes == nil {
+		return status.code == http.StatusRequestTimeout || status.code == http.StatusTooManyRequests || status.code >= 500
+	}
+	for _, code := range p.RetryableStatusCodes {
+		if status.code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// exhausted reports whether a delivery first attempted at start is given
+// up after attempt failed attempts, the next one being due after wait.
+func (p RetryPolicy) exhausted(attempt int, start time.Time, wait time.Duration) bool {
+	if p.MaxAttempts > 0 && attempt >= p.MaxAttempts {
+		return true
+	}
+	return p.Deadline > 0 && time.Since(start)+wait > p.Deadline
+}
+
+// UnmarshalJSON reads a policy written with the field names in camel case
+// and the durations as strings such as "1.5s", see LOG_RETRY_POLICY_<SINK>.
+func (p *RetryPolicy) UnmarshalJSON(data []byte) error {
+	var v struct {
+		MaxAttempts          int     `json:"maxAttempts"`
+		InitialBackoff       string  `json:"initialBackoff"`
+		MaxBackoff           string  `json:"maxBackoff"`
+		Jitter               float64 `json:"jitter"`
+		RetryableStatusCodes []int   `json:"retryableStatusCodes"`
+		Deadline             string  `json:"deadline"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	policy := RetryPolicy{MaxAttempts: v.MaxAttempts, Jitter: v.Jitter, RetryableStatusCodes: v.RetryableStatusCodes}
+	for _, d := range []struct {
+		name  string
+		value string
+		to    *time.Duration
+	}{
+		{"initialBackoff", v.InitialBackoff, &policy.InitialBackoff},
+		{"maxBackoff", v.MaxBackoff, &policy.MaxBackoff},
+		{"deadline", v.Deadline, &policy.Deadline},
+	} {
+		if d.value == "" {
+			continue
+		}
+		var err error
+		if *d.to, err = time.ParseDuration(d.value); err != nil {
+			return fmt.Errorf("invalid %s: %v", d.name, err)
+		}
+	}
+	*p = policy
+	return nil
+}
+
+// retryPolicyFromEnv returns the policy of sink set by
+// LOG_RETRY_POLICY_<SINK>, as JSON, or nil if it is not set.
+func retryPolicyFromEnv(sink string) (*RetryPolicy, error) {
+	name := "LOG_RETRY_POLICY_" + strings.ToUpper(sink)
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return nil, nil
+	}
+	var p RetryPolicy
+	if err := json.Unmarshal([]byte(value), &p); err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", name, err)
+	}
+	return &p, nil
+}
+
+// retryingWriter is implemented by the remote sinks supporting retry
+// policies.
+type retryingWriter interface {
+	// setRetryPolicy replaces the default policy of the sink. It is called
+	// before the first write.
+	setRetryPolicy(p RetryPolicy)
+}
+
+// statusError is the failure of a request answered with an unexpected HTTP
+// status code.
+type statusError struct {
+	code int
+	err  error
+}
+
+func (e *statusError) Error() string {
+	return e.err.Error()
+}
@@ -0,0 +1,211 @@
+// sad-go-logger/logger/ring_buffer.go
+
+package logger
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// ringRecordHeader is the size of a record header: the payload length and
+// the index of the destination writer.
+const ringRecordHeader = 8
+
+// ringBuffer decouples logging calls from slow sinks. Writes copy the
+// encoded entry into a preallocated ring, mapped outside the Go heap where
+// the platform allows, and a background goroutine drains it to the real
+// writers. When the ring is full, entries are dropped rather than blocking
+// the caller, and the drops are reported once the ring drained.
+type ringBuffer struct {
+	buf  []byte
+	size int
+
+	mu      sync.Mutex
+	drained *sync.Cond // broadcast whenever head moves
+	head    uint64     // next byte to drain
+	tail    uint64     // next byte to write
+	dropped int
+	closed  bool
+
+	writers []zapcore.WriteSyncer
+	notify  chan struct{}
+	done    chan struct{} // closed by close to stop the drainer
+	stopped chan struct{} // closed by the drainer once it returned
+}
+
+// newRingBuffer allocates a ring of size bytes and starts its drainer.
+func newRingBuffer(size int) (*ringBuffer, error) {
+	buf, err := allocRing(size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to allocate the ring buffer: %v", err)
+	}
+	r := &ringBuffer{
+		buf:     buf,
+		size:    len(buf),
+		notify:  make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	r.drained = sync.NewCond(&r.mu)
+	go r.drain()
+	return r, nil
+}
+
+// writer returns a WriteSyncer writing to w through the ring. It must be
+// called before the first write.
+func (r *ringBuffer) writer(w zapcore.WriteSyncer) zapcore.WriteSyncer {
+	r.writers = append(r.writers, w)
+	return &ringWriter{ring: r, index: uint32(len(r.writers) - 1)}
+}
+
+// put copies p into the ring at offset off, wrapping around its end.
+func (r *ringBuffer) put(off uint64, p []byte) {
+	i := off % uint64(len(r.buf))
+	n := copy(r.buf[i:], p)
+	copy(r.buf, p[n:])
+}
+
+// get copies the ring bytes at offset off into p.
+func (r *ringBuffer) get(off uint64, p []byte) {
+	i := off % uint64(len(r.buf))
+	n := copy(p, r.buf[i:])
+	copy(p[n:], r.buf)
+}
+
+func (r *ringBuffer) write(index uint32, p []byte) {
+	var header [ringRecordHeader]byte
+	binary.LittleEndian.PutUint32(header[:4], uint32(len(p)))
+	binary.LittleEndian.PutUint32(header[4:], index)
+
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return
+	}
+	if r.tail+ringRecordHeader+uint64(len(p))-r.head > uint64(len(r.buf)) {
+		r.dropped++
+		r.mu.Unlock()
+		return
+	}
+	r.put(r.tail, header[:])
+	r.put(r.tail+ringRecordHeader, p)
+	r.tail += ringRecordHeader + uint64(len(p))
+	r.mu.Unlock()
+
+	select {
+	case r.notify <- struct{}{}:
+	default:
+	}
+}
+
+// drain writes the records of the ring to their writers until close
+// stops it, after a last pass.
+func (r *ringBuffer) drain() {
+	defer close(r.stopped)
+	var payload []byte
+	for {
+		select {
+		case <-r.notify:
+			payload = r.drainRecords(payload)
+		case <-r.done:
+			r.drainRecords(payload)
+			return
+		}
+	}
+}
+
+// drainRecords writes the records written so far to their writers, with
+// payload as their buffer, which it returns. The bytes between head and
+// tail belong to the drainer: writers only fill the space past tail.
+func (r *ringBuffer) drainRecords(payload []byte) []byte {
+	var header [ringRecordHeader]byte
+	for {
+		r.mu.Lock()
+		head, tail, dropped := r.head, r.tail, r.dropped
+		r.dropped = 0
+		r.mu.Unlock()
+		if dropped > 0 {
+			fmt.Printf("Ring buffer full, dropped %d log entries\n", dropped)
+		}
+		if head == tail {
+			return payload
+		}
+
+		for head < tail {
+			r.get(head, header[:])
+			n := binary.LittleEndian.Uint32(header[:4])
+			index := binary.LittleEndian.Uint32(header[4:])
+			if cap(payload) < int(n) {
+				payload = make([]byte, n)
+			}
+			payload = payload[:n]
+			r.get(head+ringRecordHeader, payload)
+			r.writers[index].Write(payload)
+			head += ringRecordHeader + uint64(n)
+		}
+
+		r.mu.Lock()
+		r.head = head
+		r.drained.Broadcast()
+		r.mu.Unlock()
+	}
+}
+
+// close stops the ring once the records written so far are drained, and
+// releases its memory. The entries written afterwards are discarded.
+func (r *ringBuffer) close() {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return
+	}
+	r.closed = true
+	r.mu.Unlock()
+
+	close(r.done)
+	<-r.stopped
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := freeRing(r.buf); err != nil {
+		fmt.Printf("Failed to release the ring buffer: %v\n", err)
+	}
+	r.buf = nil
+}
+
+// wait blocks until every record written so far is drained.
+func (r *ringBuffer) wait() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	target := r.tail
+	for r.head < target {
+		r.drained.Wait()
+	}
+}
+
+// ringWriter is a writer of a ringBuffer.
+type ringWriter struct {
+	ring  *ringBuffer
+	index uint32
+}
+
+// Write copies p into the ring. It never blocks on the writer; p is
+// dropped if the ring is full.
+func (w *ringWriter) Write(p []byte) (int, error) {
+	if len(p)+ringRecordHeader > w.ring.size {
+		return 0, errors.New("log entry larger than the ring buffer")
+	}
+	w.ring.write(w.index, p)
+	return len(p), nil
+}
+
+// Sync waits for the ring to drain the entries written so far, then syncs
+// the writer.
+func (w *ringWriter) Sync() error {
+	w.ring.wait()
+	return w.ring.writers[w.index].Sync()
+}
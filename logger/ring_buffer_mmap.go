@@ -0,0 +1,28 @@
+// sad-go-logger/logger/ring_buffer_mmap.go
+
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package logger
+
+import (
+	"os"
+	"syscall"
+)
+
+// allocRing maps size bytes of anonymous memory, outside the Go heap, and
+// touches every page so that writes never fault.
+func allocRing(size int) ([]byte, error) {
+	buf, err := syscall.Mmap(-1, 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_ANON|syscall.MAP_PRIVATE)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(buf); i += os.Getpagesize() {
+		buf[i] = 0
+	}
+	return buf, nil
+}
+
+// freeRing unmaps a ring of allocRing.
+func freeRing(buf []byte) error {
+	return syscall.Munmap(buf)
+}
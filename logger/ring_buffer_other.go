@@ -0,0 +1,16 @@
+// sad-go-logger/logger/ring_buffer_other.go
+
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd && !dragonfly
+
+package logger
+
+// allocRing allocates the ring on the heap, where memory mapping is not
+// available.
+func allocRing(size int) ([]byte, error) {
+	return make([]byte, size), nil
+}
+
+// freeRing releases a ring of allocRing to the garbage collector.
+func freeRing(buf []byte) error {
+	return nil
+}
@@ -0,0 +1,94 @@
+// sad-go-logger/logger/ring_buffer_test.go
+
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// gateWriter is a recordingWriter whose writes wait until the gate is
+// closed.
+type gateWriter struct {
+	recordingWriter
+	gate chan struct{}
+}
+
+func (w *gateWriter) Write(p []byte) (int, error) {
+	<-w.gate
+	return w.recordingWriter.Write(p)
+}
+
+func newTestRing(t *testing.T, size int) *ringBuffer {
+	t.Helper()
+	r, err := newRingBuffer(size)
+	if err != nil {
+		t.Fatalf("newRingBuffer: %v", err)
+	}
+	return r
+}
+
+func TestRingBufferDeliversInOrder(t *testing.T) {
+	r := newTestRing(t, 4096)
+	first, second := &recordingWriter{}, &recordingWriter{}
+	w1, w2 := r.writer(first), r.writer(second)
+
+	// Enough entries to wrap around the ring several times.
+	var want1, want2 strings.Builder
+	for i := 0; i < 200; i++ {
+		e1, e2 := fmt.Sprintf("first %d\n", i), fmt.Sprintf("second %d\n", i)
+		w1.Write([]byte(e1))
+		w2.Write([]byte(e2))
+		want1.WriteString(e1)
+		want2.WriteString(e2)
+		if i%50 == 49 {
+			w1.Sync()
+		}
+	}
+	if err := w2.Sync(); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+
+	if got := first.String(); got != want1.String() {
+		t.Errorf("first writer got %q, want %q", got, want1.String())
+	}
+	if got := second.String(); got != want2.String() {
+		t.Errorf("second writer got %q, want %q", got, want2.String())
+	}
+}
+
+func TestRingBufferDropsWhenFull(t *testing.T) {
+	r := newTestRing(t, 64)
+	slow := &gateWriter{gate: make(chan struct{})}
+	w := r.writer(slow)
+
+	// The first entry blocks the drainer; the next ones fill the ring.
+	entry := []byte("0123456789abcdef\n")
+	for i := 0; i < 10; i++ {
+		if _, err := w.Write(entry); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	r.mu.Lock()
+	dropped := r.dropped
+	r.mu.Unlock()
+	if dropped == 0 {
+		t.Error("no entry dropped from a full ring")
+	}
+
+	close(slow.gate)
+	w.Sync()
+	if n := strings.Count(slow.String(), "\n"); n == 0 || n+dropped != 10 {
+		t.Errorf("delivered %d and dropped %d entries, want 10 in total", n, dropped)
+	}
+}
+
+func TestRingBufferRejectsLargeEntries(t *testing.T) {
+	r := newTestRing(t, 64)
+	w := r.writer(&recordingWriter{})
+
+	if _, err := w.Write(make([]byte, 64)); err == nil {
+		t.Error("Write accepted an entry larger than the ring")
+	}
+}
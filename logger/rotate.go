@@ -0,0 +1,306 @@
+// sad-go-logger/logger/rotate.go
+
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sadco-io/sad-go-logger/logger/logfile"
+)
+
+// Rotation configures the rotation of a log file. The zero value never
+// rotates.
+type Rotation struct {
+	// MaxSize is the size in bytes past which the file is rotated: <file>
+	// is renamed to <file>.1, <file>.1 to <file>.2 and so on. Zero disables
+	// the size limit.
+	MaxSize int64
+
+	// MaxBackups is the number of rotated files kept; the older ones are
+	// deleted. With none, the file is truncated instead.
+	MaxBackups int
+
+	// Schedule, "daily" or "hourly", stamps the file name with the day or
+	// hour, in local time, and rolls over to a new file at every boundary:
+	// logs.txt is written as logs-2024-05-01.txt, or logs-2024-05-01-13.txt
+	// hourly. MaxSize and MaxBackups then apply within the period, to
+	// logs-2024-05-01.txt.1 and so on. Empty disables the schedule.
+	Schedule string
+
+	// Compress gzips the rotated files, in the background: logs.txt.1
+	// becomes logs.txt.1.gz, logs-2024-05-01.txt logs-2024-05-01.txt.gz.
+	Compress bool
+
+	// MaxAge, when positive, deletes the rotated files last modified
+	// longer ago.
+	MaxAge time.Duration
+
+	// MaxTotalSize, when positive, bounds the total size in bytes of the
+	// rotated files, the oldest of which are deleted past it.
+	MaxTotalSize int64
+}
+
+// layout returns the layout of the date in the file names, empty without a
+// schedule.
+func (r Rotation) layout() (string, error) {
+	switch r.Schedule {
+	case "":
+		return "", nil
+	case "daily":
+		return logfile.DailyLayout, nil
+	case "hourly":
+		return logfile.HourlyLayout, nil
+	default:
+		return "", fmt.Errorf("invalid rotation schedule %q, expected daily, hourly or empty", r.Schedule)
+	}
+}
+
+// period returns the path of the file written at t, and the end of the
+// period it is written for, zero without a schedule.
+func (r Rotation) period(path string, t time.Time) (string, time.Time) {
+	layout, _ := r.layout()
+	y, m, d := t.Date()
+	var end time.Time
+	switch layout {
+	case "":
+		return path, time.Time{}
+	case logfile.DailyLayout:
+		end = time.Date(y, m, d+1, 0, 0, 0, 0, t.Location())
+	case logfile.HourlyLayout:
+		end = time.Date(y, m, d, t.Hour()+1, 0, 0, 0, t.Location())
+	}
+	return filepath.Join(filepath.Dir(path), logfile.DatedName(filepath.Base(path), t.Format(layout))), end
+}
+
+// rotationFromEnv returns r with the settings of the <prefix>_MAX_SIZE_MB,
+// <prefix>_MAX_BACKUPS, <prefix>_ROTATION (the schedule),
+// <prefix>_COMPRESS, <prefix>_MAX_AGE_DAYS and <prefix>_MAX_TOTAL_SIZE_MB
+// environment variables that are set.
+func rotationFromEnv(prefix string, r Rotation) (Rotation, error) {
+	if s := os.Getenv(prefix + "_MAX_SIZE_MB"); s != "" {
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || v < 0 {
+			return r, fmt.Errorf("invalid %s_MAX_SIZE_MB: %s", prefix, s)
+		}
+		r.MaxSize = v << 20
+	}
+	if s := os.Getenv(prefix + "_MAX_BACKUPS"); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 0 {
+			return r, fmt.Errorf("invalid %s_MAX_BACKUPS: %s", prefix, s)
+		}
+		r.MaxBackups = v
+	}
+	if s := os.Getenv(prefix + "_ROTATION"); s != "" {
+		schedule := Rotation{Schedule: s}
+		if _, err := schedule.layout(); err != nil {
+			return r, fmt.Errorf("invalid %s_ROTATION: %s", prefix, s)
+		}
+		r.Schedule = s
+	}
+	if s := os.Getenv(prefix + "_COMPRESS"); s != "" {
+		v, err := strconv.ParseBool(s)
+		if err != nil {
+			return r, fmt.Errorf("invalid %s_COMPRESS: %s", prefix, s)
+		}
+		r.Compress = v
+	}
+	if s := os.Getenv(prefix + "_MAX_AGE_DAYS"); s != "" {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil || v < 0 {
+			return r, fmt.Errorf("invalid %s_MAX_AGE_DAYS: %s", prefix, s)
+		}
+		r.MaxAge = time.Duration(v * float64(24*time.Hour))
+	}
+	if s := os.Getenv(prefix + "_MAX_TOTAL_SIZE_MB"); s != "" {
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || v < 0 {
+			return r, fmt.Errorf("invalid %s_MAX_TOTAL_SIZE_MB: %s", prefix, s)
+		}
+		r.MaxTotalSize = v << 20
+	}
+	return r, nil
+}
+
+// rotatingFile is an append-only file rotated as its Rotation says. Reload
+// reopens it, for external rotation such as logrotate.
+type rotatingFile struct {
+	// base is the path of the file, and path the one written, stamped with
+	// the current period if the rotation has a schedule.
+	base     string
+	perm     os.FileMode
+	rotation Rotation
+
+	mu        sync.Mutex
+	path      string
+	periodEnd time.Time
+	file      *os.File
+	size      int64
+
+	// mill, when the rotated files are compressed or expire, wakes the
+	// goroutine doing it, see retention.go. millMu keeps it off the
+	// backups while they are renamed.
+	mill   chan struct{}
+	millMu sync.Mutex
+}
+
+// openFiles are the rotating files opened by the process, see reopenFiles.
+var (
+	openFilesMu sync.Mutex
+	openFiles   []*rotatingFile
+)
+
+// openRotatingFile opens or creates the file at path, or the one of the
+// current period, with permission perm.
+func openRotatingFile(path string, perm os.FileMode, rotation Rotation) (*rotatingFile, error) {
+	if _, err := rotation.layout(); err != nil {
+		return nil, err
+	}
+	r := &rotatingFile{base: path, perm: perm, rotation: rotation}
+	r.path, r.periodEnd = rotation.period(path, time.Now())
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	openFilesMu.Lock()
+	openFiles = append(openFiles, r)
+	openFilesMu.Unlock()
+	r.startMill()
+	return r, nil
+}
+
+// reopenFiles reopens every rotating file at its path.
+func reopenFiles() {
+	openFilesMu.Lock()
+	files := append([]*rotatingFile(nil), openFiles...)
+	openFilesMu.Unlock()
+	for _, r := range files {
+		if err := r.reopen(); err != nil {
+			fmt.Printf("Failed to reopen %s: %v\n", r.base, err)
+		}
+	}
+}
+
+func (r *rotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, r.perm)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	r.file, r.size = file, info.Size()
+	return nil
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.periodEnd.IsZero() {
+		if now := time.Now(); !now.Before(r.periodEnd) {
+			if err := r.rollOver(now); err != nil {
+				fmt.Printf("Failed to roll %s over: %v\n", r.path, err)
+			}
+		}
+	}
+	if r.rotation.MaxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.rotation.MaxSize {
+		if err := r.rotate(); err != nil {
+			fmt.Printf("Failed to rotate %s: %v\n", r.path, err)
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// rollOver opens the file of the period of now, writing to the previous
+// one if it fails.
+func (r *rotatingFile) rollOver(now time.Time) error {
+	previous, previousEnd := r.path, r.periodEnd
+	r.path, r.periodEnd = r.rotation.period(r.base, now)
+	old := r.file
+	if err := r.open(); err != nil {
+		r.path, r.periodEnd = previous, previousEnd
+		return err
+	}
+	err := old.Close()
+	r.wakeMill()
+	return err
+}
+
+// rotate moves the current file to path.1 and opens a new one.
+func (r *rotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		// Keep writing to the file, reopened in place.
+		if openErr := r.open(); openErr != nil {
+			return openErr
+		}
+		return err
+	}
+	var err error
+	if maxBackups := r.rotation.MaxBackups; maxBackups > 0 {
+		// Keep the mill off the backups while they are renamed.
+		r.millMu.Lock()
+		for _, ext := range []string{"", ".gz"} {
+			os.Remove(fmt.Sprintf("%s.%d%s", r.path, maxBackups, ext))
+			for i := maxBackups - 1; i > 0; i-- {
+				os.Rename(fmt.Sprintf("%s.%d%s", r.path, i, ext), fmt.Sprintf("%s.%d%s", r.path, i+1, ext))
+			}
+		}
+		err = os.Rename(r.path, r.path+".1")
+		r.millMu.Unlock()
+		r.wakeMill()
+	} else {
+		err = os.Remove(r.path)
+	}
+	// Keep writing, to the old file if it could not be moved.
+	if openErr := r.open(); openErr != nil {
+		return openErr
+	}
+	return err
+}
+
+// reopen closes the file and opens the one now at path, e.g. after it was
+// moved away.
+func (r *rotatingFile) reopen() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	previous := r.file
+	if err := r.open(); err != nil {
+		return err
+	}
+	return previous.Close()
+}
+
+// Close closes the file, which is no longer reopened.
+func (r *rotatingFile) Close() error {
+	openFilesMu.Lock()
+	for i, f := range openFiles {
+		if f == r {
+			openFiles = append(openFiles[:i:i], openFiles[i+1:]...)
+			break
+		}
+	}
+	openFilesMu.Unlock()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.mill != nil {
+		close(r.mill)
+		r.mill = nil
+	}
+	return r.file.Close()
+}
+
+func (r *rotatingFile) Sync() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Sync()
+}
@@ -0,0 +1,138 @@
+// sad-go-logger/logger/rotate_test.go
+
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestRotatingFile opens logs.txt in a temporary directory, without
+// registering it for reopenFiles.
+func newTestRotatingFile(t *testing.T, rotation Rotation) *rotatingFile {
+	t.Helper()
+	r := &rotatingFile{base: filepath.Join(t.TempDir(), "logs.txt"), perm: 0644, rotation: rotation}
+	r.path, r.periodEnd = rotation.period(r.base, time.Now())
+	if err := r.open(); err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { r.file.Close() })
+	return r
+}
+
+// readFile returns the content of path, empty if it does not exist.
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestRotatingFileShiftsBackups(t *testing.T) {
+	r := newTestRotatingFile(t, Rotation{MaxSize: 10, MaxBackups: 2})
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := r.Write([]byte(line)); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+
+	for suffix, want := range map[string]string{"": "fourth\n", ".1": "third\n", ".2": "second\n", ".3": ""} {
+		if got := readFile(t, r.path+suffix); got != want {
+			t.Errorf("logs.txt%s = %q, want %q", suffix, got, want)
+		}
+	}
+}
+
+func TestRotatingFileTruncatesWithoutBackups(t *testing.T) {
+	r := newTestRotatingFile(t, Rotation{MaxSize: 10})
+
+	r.Write([]byte("first\n"))
+	r.Write([]byte("second\n"))
+
+	if got := readFile(t, r.path); got != "second\n" {
+		t.Errorf("logs.txt = %q, want the last entry only", got)
+	}
+	if got := readFile(t, r.path+".1"); got != "" {
+		t.Errorf("logs.txt.1 = %q, want no backup", got)
+	}
+}
+
+func TestRotatingFileReopensAfterFailedClose(t *testing.T) {
+	r := newTestRotatingFile(t, Rotation{MaxSize: 10, MaxBackups: 1})
+	r.Write([]byte("first\n"))
+
+	// Closing the file again in rotate fails.
+	r.file.Close()
+	if _, err := r.Write([]byte("second\n")); err != nil {
+		t.Fatalf("Write after a failed rotation: %v", err)
+	}
+	if got := readFile(t, r.path); got != "first\nsecond\n" {
+		t.Errorf("logs.txt = %q, want the entries kept in place", got)
+	}
+}
+
+func TestRotatingFileRollsOverDaily(t *testing.T) {
+	r := newTestRotatingFile(t, Rotation{Schedule: "daily"})
+	today := r.path
+
+	// Start in the previous period, which is over.
+	yesterday, _ := r.rotation.period(r.base, time.Now().AddDate(0, 0, -1))
+	r.file.Close()
+	r.path, r.periodEnd = yesterday, time.Now().Add(-time.Second)
+	if err := r.open(); err != nil {
+		t.Fatalf("open: %v", err)
+	}
+
+	r.Write([]byte("today\n"))
+	if got := readFile(t, yesterday); got != "" {
+		t.Errorf("%s = %q, want nothing written past its period", filepath.Base(yesterday), got)
+	}
+	if got := readFile(t, today); got != "today\n" {
+		t.Errorf("%s = %q, want the entry", filepath.Base(today), got)
+	}
+	if r.periodEnd.Before(time.Now()) {
+		t.Errorf("period ends at %v, want the end of today", r.periodEnd)
+	}
+}
+
+func TestRotationPeriod(t *testing.T) {
+	at := time.Date(2024, 5, 1, 13, 30, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		schedule, path string
+		end            time.Time
+	}{
+		{"", "logs.txt", time.Time{}},
+		{"daily", "logs-2024-05-01.txt", time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)},
+		{"hourly", "logs-2024-05-01-13.txt", time.Date(2024, 5, 1, 14, 0, 0, 0, time.UTC)},
+	} {
+		path, end := Rotation{Schedule: tt.schedule}.period("logs.txt", at)
+		if path != tt.path || !end.Equal(tt.end) {
+			t.Errorf("period(%q) = %s, %v, want %s, %v", tt.schedule, path, end, tt.path, tt.end)
+		}
+	}
+	if _, err := (Rotation{Schedule: "weekly"}).layout(); err == nil {
+		t.Error("layout accepted the weekly schedule")
+	}
+}
+
+func TestRotationFromEnv(t *testing.T) {
+	if r, err := rotationFromEnv("LOG_TEST", Rotation{}); err != nil || r != (Rotation{}) {
+		t.Errorf("rotationFromEnv() = %+v, %v, want the zero Rotation", r, err)
+	}
+
+	t.Setenv("LOG_TEST_MAX_SIZE_MB", "10")
+	base := Rotation{MaxSize: 1, MaxBackups: 3}
+	if r, err := rotationFromEnv("LOG_TEST", base); err != nil || r != (Rotation{MaxSize: 10 << 20, MaxBackups: 3}) {
+		t.Errorf("rotationFromEnv() = %+v, %v, want the size set on top of the base", r, err)
+	}
+
+	t.Setenv("LOG_TEST_MAX_BACKUPS", "-1")
+	if _, err := rotationFromEnv("LOG_TEST", base); err == nil {
+		t.Error("rotationFromEnv accepted a negative LOG_TEST_MAX_BACKUPS")
+	}
+}
@@ -0,0 +1,134 @@
+// sad-go-logger/logger/sampling.go
+
+package logger
+
+import (
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// SampleRateKey is the field added to the entries kept by the sampler past
+// the initial ones, holding the number of entries each one stands for, so
+// that backends such as Honeycomb can weight them.
+const SampleRateKey = "sampleRate"
+
+// SamplingConfig caps the volume of repeated entries. Within each second,
+// the first Initial entries with a given level and message are logged, then
+// only every Thereafter-th one (none if Thereafter is 0). Unlike zap's
+// sampler, the settings of a built logger can be changed at runtime.
+type SamplingConfig struct {
+	Initial    int `json:"initial"`
+	Thereafter int `json:"thereafter"`
+}
+
+// samplingTick is the window over which entries are counted.
+const samplingTick = time.Second
+
+// samplingCounters is the number of counters per level; messages are
+// hashed onto them, as in zap.
+const samplingCounters = 4096
+
+// samplingControl holds the current settings and counters of a logger's
+// sampler, shared by all cores derived from it with With.
+type samplingControl struct {
+	settings atomic.Pointer[SamplingConfig]
+	counts   [zapcore.FatalLevel - zapcore.DebugLevel + 1][samplingCounters]samplingCounter
+}
+
+// set replaces the sampling settings; nil disables sampling.
+func (s *samplingControl) set(cfg *SamplingConfig) {
+	if cfg != nil {
+		copied := *cfg
+		cfg = &copied
+	}
+	s.settings.Store(cfg)
+}
+
+// samplingCounter counts entries in the current tick.
+type samplingCounter struct {
+	resetAt atomic.Int64
+	count   atomic.Uint64
+}
+
+func (c *samplingCounter) incCheckReset(t time.Time) uint64 {
+	tn := t.UnixNano()
+	resetAfter := c.resetAt.Load()
+	if resetAfter > tn {
+		return c.count.Add(1)
+	}
+
+	c.count.Store(1)
+	newResetAfter := tn + samplingTick.Nanoseconds()
+	if !c.resetAt.CompareAndSwap(resetAfter, newResetAfter) {
+		// We raced with another goroutine trying to reset, and it also reset
+		// the counter to 1, so we need to reincrement the counter.
+		return c.count.Add(1)
+	}
+	return 1
+}
+
+// samplingCore applies the sampling settings of its control to the entries
+// written to the wrapped core.
+type samplingCore struct {
+	zapcore.Core
+	control *samplingControl
+}
+
+func (c *samplingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &samplingCore{Core: c.Core.With(fields), control: c.control}
+}
+
+func (c *samplingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(ent.Level) {
+		return ce
+	}
+
+	if s := c.control.settings.Load(); s != nil && ent.Level >= zapcore.DebugLevel && ent.Level <= zapcore.FatalLevel {
+		counter := &c.control.counts[ent.Level-zapcore.DebugLevel][fnv32a(ent.Message)%samplingCounters]
+		n := counter.incCheckReset(ent.Time)
+		if n > uint64(s.Initial) {
+			if s.Thereafter <= 0 || (n-uint64(s.Initial))%uint64(s.Thereafter) != 0 {
+				return ce
+			}
+			if s.Thereafter > 1 {
+				return (&sampleRateCore{Core: c.Core, rate: s.Thereafter}).Check(ent, ce)
+			}
+		}
+	}
+	return c.Core.Check(ent, ce)
+}
+
+// sampleRateCore adds the SampleRateKey field to the entries it writes.
+type sampleRateCore struct {
+	zapcore.Core
+	rate int
+}
+
+func (c *sampleRateCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *sampleRateCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	fields = append(fields[:len(fields):len(fields)], zap.Int(SampleRateKey, c.rate))
+	return c.Core.Write(ent, fields)
+}
+
+// fnv32a is the 32-bit FNV-1a hash of s.
+func fnv32a(s string) uint32 {
+	const (
+		offset32 = 2166136261
+		prime32  = 16777619
+	)
+	hash := uint32(offset32)
+	for i := 0; i < len(s); i++ {
+		hash ^= uint32(s[i])
+		hash *= prime32
+	}
+	return hash
+}
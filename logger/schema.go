@@ -0,0 +1,161 @@
+// sad-go-logger/logger/schema.go
+
+package logger
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Schema lists the fields an entry must carry, keeping field names
+// consistent across a large codebase.
+type Schema struct {
+	// Required are the keys every entry must carry, passed with the entry
+	// or added with Logger.With.
+	Required []string `json:"required,omitempty"`
+
+	// Types constrains the type of fields, when present: "string",
+	// "number", "bool", "duration", "time", "error", "object" or "array".
+	Types map[string]string `json:"types,omitempty"`
+}
+
+var (
+	schemasMu      sync.RWMutex
+	schemas        = map[string]Schema{}
+	schemaHookOnce sync.Once
+	schemaStrict   atomic.Bool
+	schemaWarned   sync.Map
+)
+
+// RegisterSchema validates against s the entries of the logger named name
+// (see zap.Logger.Named), and the entries whose event field equals name.
+// Violations are reported once per schema and problem with a warning on the
+// global Log or, in strict mode, panic so that tests fail; see
+// SetSchemaStrict.
+func RegisterSchema(name string, s Schema) error {
+	if name == "" {
+		return fmt.Errorf("schema name must not be empty")
+	}
+	for key, typ := range s.Types {
+		if _, ok := schemaTypes[typ]; !ok {
+			return fmt.Errorf("schema %q: unknown type %q for field %q", name, typ, key)
+		}
+	}
+
+	schemasMu.Lock()
+	schemas[name] = s
+	schemasMu.Unlock()
+
+	schemaHookOnce.Do(func() {
+		schemaStrict.Store(os.Getenv("LOG_SCHEMA_STRICT") == "true")
+		RegisterHook(PreWrite, validateSchemas)
+	})
+	return nil
+}
+
+// SetSchemaStrict turns strict mode on or off. In strict mode, meant for
+// tests and CI, an entry violating its schema panics, failing the test that
+// logged it, including through NewTest loggers. Strict mode is also
+// enabled by LOG_SCHEMA_STRICT=true.
+func SetSchemaStrict(strict bool) {
+	schemaHookOnce.Do(func() {
+		RegisterHook(PreWrite, validateSchemas)
+	})
+	schemaStrict.Store(strict)
+}
+
+// schemaTypes maps the schema types to the field types they accept.
+var schemaTypes = map[string][]zapcore.FieldType{
+	"string": {zapcore.StringType, zapcore.StringerType, zapcore.ByteStringType},
+	"number": {
+		zapcore.Int64Type, zapcore.Int32Type, zapcore.Int16Type, zapcore.Int8Type,
+		zapcore.Uint64Type, zapcore.Uint32Type, zapcore.Uint16Type, zapcore.Uint8Type,
+		zapcore.UintptrType, zapcore.Float64Type, zapcore.Float32Type,
+	},
+	"bool":     {zapcore.BoolType},
+	"duration": {zapcore.DurationType},
+	"time":     {zapcore.TimeType, zapcore.TimeFullType},
+	"error":    {zapcore.ErrorType},
+	"object":   {zapcore.ObjectMarshalerType, zapcore.InlineMarshalerType, zapcore.ReflectType},
+	"array":    {zapcore.ArrayMarshalerType, zapcore.ReflectType},
+}
+
+// validateSchemas is the PreWrite hook checking entries against the
+// registered schemas.
+func validateSchemas(e *Entry) error {
+	fields := make(map[string]zapcore.Field, len(e.Context)+len(e.Fields))
+	for _, f := range e.Context {
+		fields[f.Key] = f
+	}
+	for _, f := range e.Fields {
+		fields[f.Key] = f
+	}
+	event := ""
+	if f, ok := fields[EventKey]; ok && f.Type == zapcore.StringType && f.String != e.LoggerName {
+		event = f.String
+	}
+
+	// Look the schemas up first: reporting a violation logs, which runs
+	// this hook again.
+	schemasMu.RLock()
+	byLogger, loggerOK := schemas[e.LoggerName]
+	byEvent, eventOK := schemas[event]
+	schemasMu.RUnlock()
+
+	if loggerOK {
+		checkSchema(e.LoggerName, byLogger, e, fields)
+	}
+	if eventOK && event != "" {
+		checkSchema(event, byEvent, e, fields)
+	}
+	return nil
+}
+
+// checkSchema reports the violations of s by the entry.
+func checkSchema(name string, s Schema, e *Entry, fields map[string]zapcore.Field) {
+	var problems []string
+	for _, key := range s.Required {
+		if _, ok := fields[key]; !ok {
+			problems = append(problems, "missing field "+key)
+		}
+	}
+	for key, typ := range s.Types {
+		f, ok := fields[key]
+		if ok && !schemaTypeAccepts(typ, f.Type) {
+			problems = append(problems, fmt.Sprintf("field %s is not of type %s", key, typ))
+		}
+	}
+	if len(problems) == 0 {
+		return
+	}
+	sort.Strings(problems)
+
+	if schemaStrict.Load() {
+		panic(fmt.Sprintf("log entry %q violates schema %q: %s", e.Message, name, strings.Join(problems, ", ")))
+	}
+	for _, problem := range problems {
+		if _, warned := schemaWarned.LoadOrStore(name+"\x00"+problem, true); warned {
+			continue
+		}
+		Log.Warn("Log entry violates schema",
+			zap.String("schema", name),
+			zap.String("problem", problem),
+			zap.String("entryMessage", e.Message))
+	}
+}
+
+func schemaTypeAccepts(typ string, t zapcore.FieldType) bool {
+	for _, accepted := range schemaTypes[typ] {
+		if t == accepted {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,201 @@
+// sad-go-logger/logger/scrub.go
+
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// The patterns of the built-in scrubbers, see SetScrubbers.
+const (
+	// ScrubAPIKeys matches the API keys of AWS, GitHub, Google, Slack and
+	// Stripe, recognizable by their prefix.
+	ScrubAPIKeys = `\b(?:AKIA[0-9A-Z]{16}|gh[pousr]_[A-Za-z0-9]{36,}|AIza[0-9A-Za-z_\-]{35}|xox[abprs]-[A-Za-z0-9\-]{10,}|(?:sk|pk|rk)_(?:live|test)_[A-Za-z0-9]{10,})\b`
+
+	// ScrubBearerTokens matches the credentials of the Bearer
+	// authorization scheme, the scheme included.
+	ScrubBearerTokens = `(?i)\bbearer\s+[A-Za-z0-9\-._~+/]+=*`
+
+	// ScrubCreditCards matches the numbers of 13 to 19 digits, possibly
+	// grouped by spaces or dashes, passing the Luhn check.
+	ScrubCreditCards = `\b\d(?:[ \-]?\d){12,18}\b`
+
+	// ScrubEmails matches email addresses.
+	ScrubEmails = `\b[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}\b`
+)
+
+// builtinScrubbers are the patterns of the built-in scrubbers, by name.
+var builtinScrubbers = map[string]string{
+	"api_key":     ScrubAPIKeys,
+	"bearer":      ScrubBearerTokens,
+	"credit_card": ScrubCreditCards,
+	"email":       ScrubEmails,
+}
+
+// scrubber is a compiled scrubbing pattern. Matches are replaced unless
+// valid rejects them.
+type scrubber struct {
+	re    *regexp.Regexp
+	valid func(string) bool
+}
+
+var scrubbers atomic.Pointer[[]scrubber]
+
+// SetScrubbers replaces the patterns whose matches are rewritten to
+// "[REDACTED]" in the messages and string fields of the entries, before
+// they reach any sink of the loggers built with Config.Build or NewTest,
+// e.g.
+//
+//	logger.SetScrubbers(logger.ScrubBearerTokens, logger.ScrubEmails, `acct-\d{8}`)
+//
+// The patterns are regular expressions, such as the built-in ScrubAPIKeys,
+// ScrubBearerTokens, ScrubCreditCards, whose matches are only replaced if
+// they pass the Luhn check, and ScrubEmails. None are set by default;
+// LOG_SCRUBBERS sets the initial built-in scrubbers by name, comma-separated:
+// api_key, bearer, credit_card and email, or "all", and LOG_SCRUB_PATTERNS
+// adds patterns of its own, as a JSON array of strings. Calling
+// SetScrubbers without patterns disables scrubbing.
+func SetScrubbers(patterns ...string) error {
+	compiled := make([]scrubber, 0, len(patterns))
+	for i, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("scrubber %d: invalid pattern: %v", i, err)
+		}
+		s := scrubber{re: re}
+		if pattern == ScrubCreditCards {
+			s.valid = luhnValid
+		}
+		compiled = append(compiled, s)
+	}
+	scrubbers.Store(&compiled)
+	return nil
+}
+
+// scrubbersFromEnv sets the scrubbers of LOG_SCRUBBERS and
+// LOG_SCRUB_PATTERNS.
+func scrubbersFromEnv(names, patterns string) error {
+	var list []string
+	for _, name := range strings.Split(names, ",") {
+		switch name = strings.TrimSpace(name); name {
+		case "":
+		case "all":
+			for _, name := range sortedScrubberNames() {
+				list = append(list, builtinScrubbers[name])
+			}
+		default:
+			pattern, ok := builtinScrubbers[name]
+			if !ok {
+				return fmt.Errorf("invalid LOG_SCRUBBERS: unknown scrubber %q, expected %s or all", name, strings.Join(sortedScrubberNames(), ", "))
+			}
+			list = append(list, pattern)
+		}
+	}
+	if patterns != "" {
+		var own []string
+		if err := json.Unmarshal([]byte(patterns), &own); err != nil {
+			return fmt.Errorf("invalid LOG_SCRUB_PATTERNS: %v", err)
+		}
+		list = append(list, own...)
+	}
+	return SetScrubbers(list...)
+}
+
+// sortedScrubberNames returns the names of the built-in scrubbers, sorted.
+func sortedScrubberNames() []string {
+	names := make([]string, 0, len(builtinScrubbers))
+	for name := range builtinScrubbers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// scrubString returns s with the matches of the scrubbers replaced.
+func scrubString(list []scrubber, s string) string {
+	for _, sc := range list {
+		if !sc.re.MatchString(s) {
+			continue
+		}
+		s = sc.re.ReplaceAllStringFunc(s, func(match string) string {
+			if sc.valid != nil && !sc.valid(match) {
+				return match
+			}
+			return redactedValue
+		})
+	}
+	return s
+}
+
+// scrubEntry rewrites the matches of the scrubbers in the message and the
+// string fields of e.
+func scrubEntry(e *Entry) {
+	list := scrubbers.Load()
+	if list == nil || len(*list) == 0 {
+		return
+	}
+	e.Message = scrubString(*list, e.Message)
+	e.Fields = scrubFields(e.Fields)
+}
+
+// scrubFields returns fields with the matches of the scrubbers replaced in
+// the string fields, or fields itself when nothing is scrubbed.
+func scrubFields(fields []zapcore.Field) []zapcore.Field {
+	list := scrubbers.Load()
+	if list == nil || len(*list) == 0 {
+		return fields
+	}
+	var scrubbed []zapcore.Field
+	for i, f := range fields {
+		var value string
+		switch f.Type {
+		case zapcore.StringType:
+			value = f.String
+		case zapcore.ByteStringType:
+			value = string(f.Interface.([]byte))
+		default:
+			continue
+		}
+		s := scrubString(*list, value)
+		if s == value {
+			continue
+		}
+		if scrubbed == nil {
+			scrubbed = append([]zapcore.Field(nil), fields...)
+		}
+		scrubbed[i] = zap.String(f.Key, s)
+	}
+	if scrubbed == nil {
+		return fields
+	}
+	return scrubbed
+}
+
+// luhnValid reports whether the digits of s pass the Luhn check of card
+// numbers.
+func luhnValid(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && sum%10 == 0
+}
@@ -0,0 +1,82 @@
+// sad-go-logger/logger/secret.go
+
+package logger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// secretHashPrefixLen is the number of hex characters of the SHA-256 digest
+// included in a redacted secret. It is enough to tell two secrets apart in
+// the logs without making the digest useful on its own.
+const secretHashPrefixLen = 8
+
+// Secret wraps a value that must never appear in log output. The wrapped
+// value can be carried through application code and retrieved with Value,
+// but every rendering path (fmt verbs, JSON, text and zap fields) produces
+// "[REDACTED:<sha256-prefix>]" instead of the value itself.
+type Secret[T any] struct {
+	value T
+}
+
+// NewSecret wraps value in a Secret.
+func NewSecret[T any](value T) Secret[T] {
+	return Secret[T]{value: value}
+}
+
+// Value returns the wrapped value. Callers are responsible for not logging it.
+func (s Secret[T]) Value() T {
+	return s.value
+}
+
+// Redacted returns the placeholder rendered in place of the wrapped value.
+func (s Secret[T]) Redacted() string {
+	var raw []byte
+	switch v := any(s.value).(type) {
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		raw = []byte(fmt.Sprintf("%v", v))
+	}
+	sum := sha256.Sum256(raw)
+	return "[REDACTED:" + hex.EncodeToString(sum[:])[:secretHashPrefixLen] + "]"
+}
+
+// String implements fmt.Stringer.
+func (s Secret[T]) String() string {
+	return s.Redacted()
+}
+
+// GoString implements fmt.GoStringer so %#v does not expose the value.
+func (s Secret[T]) GoString() string {
+	return s.Redacted()
+}
+
+// Format implements fmt.Formatter so that every verb, including %+v and %d,
+// renders the placeholder.
+func (s Secret[T]) Format(f fmt.State, _ rune) {
+	fmt.Fprint(f, s.Redacted())
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s Secret[T]) MarshalText() ([]byte, error) {
+	return []byte(s.Redacted()), nil
+}
+
+// MarshalJSON implements json.Marshaler, which also covers zap.Any and
+// zap.Reflect on both the JSON and console encoders.
+func (s Secret[T]) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + s.Redacted() + `"`), nil
+}
+
+// SecretField constructs a field that always renders the redacted
+// placeholder for s, regardless of the sink it is written to.
+func SecretField[T any](key string, s Secret[T]) zap.Field {
+	return zap.String(key, s.Redacted())
+}
@@ -0,0 +1,123 @@
+// sad-go-logger/logger/shedding.go
+
+package logger
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// defaultShedWatermarks are the queue fills, in percent, past which debug,
+// info and warn entries are shed.
+var defaultShedWatermarks = [3]int{50, 70, 90}
+
+// shedWatermarksFromEnv returns the watermarks described by
+// LOG_SHED_WATERMARKS, such as "50,70,90", and false when shedding is
+// disabled with "off".
+func shedWatermarksFromEnv() ([3]int, bool) {
+	s := os.Getenv("LOG_SHED_WATERMARKS")
+	switch s {
+	case "":
+		return defaultShedWatermarks, true
+	case "off":
+		return [3]int{}, false
+	}
+
+	var marks [3]int
+	parts := strings.Split(s, ",")
+	valid := len(parts) == len(marks)
+	for i := 0; valid && i < len(parts); i++ {
+		n, err := strconv.Atoi(strings.TrimSpace(parts[i]))
+		valid = err == nil && n > 0 && n <= 100 && (i == 0 || n >= marks[i-1])
+		marks[i] = n
+	}
+	if !valid {
+		fmt.Printf("Invalid LOG_SHED_WATERMARKS: %s. Using 50,70,90.\n", s)
+		return defaultShedWatermarks, true
+	}
+	return marks, true
+}
+
+// shedState sheds the low-level entries of a remote sink while its queue
+// is filling up, and counts them.
+type shedState struct {
+	sink  pressureReporter
+	marks [3]int
+
+	mu   sync.Mutex
+	shed [3]uint64
+}
+
+// newShedState returns the shedding state of w, or nil if w does not
+// report its backpressure.
+func newShedState(w RemoteSyncWriter, marks [3]int) *shedState {
+	sink, ok := w.(pressureReporter)
+	if !ok {
+		return nil
+	}
+	return &shedState{sink: sink, marks: marks}
+}
+
+// drop reports whether an entry at level must be shed. Once the queue is
+// back under the lowest watermark, it prints a summary of the entries shed
+// meanwhile.
+func (s *shedState) drop(level zapcore.Level) bool {
+	p := s.sink.Pressure()
+	fill := 0
+	if p.Capacity > 0 {
+		fill = p.Queued * 100 / p.Capacity
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if fill < s.marks[0] && s.shed != [3]uint64{} {
+		fmt.Printf("%s backpressure subsided; shed %d debug, %d info and %d warn log entries\n",
+			p.Sink, s.shed[0], s.shed[1], s.shed[2])
+		s.shed = [3]uint64{}
+	}
+
+	i := int(level - zapcore.DebugLevel)
+	if i < 0 || i >= len(s.marks) || fill < s.marks[i] {
+		return false
+	}
+	s.shed[i]++
+	return true
+}
+
+// wrap returns core shedding entries with s, or core itself if s is nil.
+func (s *shedState) wrap(core zapcore.Core) zapcore.Core {
+	if s == nil {
+		return core
+	}
+	return &shedCore{Core: core, state: s}
+}
+
+// shedCore drops the entries its shedState sheds.
+type shedCore struct {
+	zapcore.Core
+	state *shedState
+}
+
+func (c *shedCore) With(fields []zapcore.Field) zapcore.Core {
+	return &shedCore{Core: c.Core.With(fields), state: c.state}
+}
+
+func (c *shedCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *shedCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if c.state.drop(ent.Level) {
+		return nil
+	}
+	return c.Core.Write(ent, fields)
+}
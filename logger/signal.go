@@ -0,0 +1,98 @@
+// sad-go-logger/logger/signal.go
+
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// defaultSignalDebugDuration is how long SIGUSR2 keeps the debug level
+// when LOG_SIGNAL_DEBUG_DURATION is not set.
+const defaultSignalDebugDuration = 10 * time.Minute
+
+// signalDump is the report written to stderr on SIGUSR1.
+type signalDump struct {
+	ServiceName string   `json:"serviceName"`
+	Hostname    string   `json:"hostname"`
+	Level       string   `json:"level"`
+	Sinks       []string `json:"sinks"`
+	Uptime      string   `json:"uptime"`
+	Stats       Stats    `json:"stats"`
+}
+
+// dumpState writes the logger configuration and stats to stderr as JSON.
+func dumpState() {
+	initMu.Lock()
+	g, cfg := global, globalConfig
+	initMu.Unlock()
+	if g == nil {
+		fmt.Fprintln(os.Stderr, "Logger not initialized")
+		return
+	}
+	dump := signalDump{
+		ServiceName: cfg.ServiceName,
+		Hostname:    hostname,
+		Level:       g.level.Level().String(),
+		Sinks:       g.sinks.Load().names,
+		Stats:       GetStats(),
+	}
+	dump.Uptime = dump.Stats.Uptime.Round(time.Second).String()
+	b, err := json.Marshal(dump)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to dump logger state: %v\n", err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%s\n", b)
+}
+
+// debugToggle raises the global level to debug for a limited time.
+type debugToggle struct {
+	mu       sync.Mutex
+	duration time.Duration
+	level    zap.AtomicLevel
+	previous zapcore.Level
+	timer    *time.Timer // non-nil while debug is forced
+}
+
+// toggle raises the level to debug, or reverts it early if it is already
+// raised.
+func (d *debugToggle) toggle() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.timer != nil {
+		d.revertLocked("SIGUSR2")
+		return
+	}
+	g := current()
+	if g == nil {
+		return
+	}
+
+	d.level = g.level
+	d.previous = g.level.Level()
+	g.level.SetLevel(zapcore.DebugLevel)
+	d.timer = time.AfterFunc(d.duration, func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		d.revertLocked("timeout")
+	})
+	Log.Warn("Log level raised to debug", zap.String("previous", d.previous.String()), zap.Duration("duration", d.duration))
+}
+
+func (d *debugToggle) revertLocked(reason string) {
+	if d.timer == nil {
+		return
+	}
+	d.timer.Stop()
+	d.timer = nil
+	d.level.SetLevel(d.previous)
+	Log.Warn("Log level reverted", zap.String("level", d.previous.String()), zap.String("reason", reason))
+}
@@ -0,0 +1,18 @@
+// sad-go-logger/logger/signal_other.go
+
+//go:build windows || plan9 || js || wasip1
+
+package logger
+
+import "time"
+
+// EnableSignalControl is a no-op on platforms without SIGUSR1 and SIGUSR2.
+func EnableSignalControl(debugDuration time.Duration) (stop func()) {
+	return func() {}
+}
+
+// ReloadOnSignal is a no-op on platforms without SIGHUP; call Reload
+// directly instead.
+func ReloadOnSignal() (stop func()) {
+	return func() {}
+}
@@ -0,0 +1,78 @@
+// sad-go-logger/logger/signal_unix.go
+
+//go:build !windows && !plan9 && !js && !wasip1
+
+package logger
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// EnableSignalControl installs handlers for runtime control of the global
+// logger without an HTTP endpoint:
+//   - SIGUSR1 writes the logger configuration and stats to stderr as JSON
+//   - SIGUSR2 raises the level to debug for debugDuration, then reverts it;
+//     a second SIGUSR2 reverts it early
+//
+// It is called by Init when LOG_SIGNAL_CONTROL is "true", with the
+// duration from LOG_SIGNAL_DEBUG_DURATION. The returned function removes
+// the handlers.
+func EnableSignalControl(debugDuration time.Duration) (stop func()) {
+	toggle := &debugToggle{duration: debugDuration}
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(signals, syscall.SIGUSR1, syscall.SIGUSR2)
+
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case sig := <-signals:
+				switch sig {
+				case syscall.SIGUSR1:
+					dumpState()
+				case syscall.SIGUSR2:
+					toggle.toggle()
+				}
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
+
+// ReloadOnSignal calls Reload on every SIGHUP, e.g. from a logrotate
+// postrotate script. It is called by Init when LOG_RELOAD_ON_SIGHUP is
+// "true". The returned function removes the handler.
+func ReloadOnSignal() (stop func()) {
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(signals, syscall.SIGHUP)
+
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-signals:
+				if err := Reload(); err != nil {
+					Log.Error("Failed to reload the logger configuration", zap.Error(err))
+				}
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
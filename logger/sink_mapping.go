@@ -0,0 +1,175 @@
+// sad-go-logger/logger/sink_mapping.go
+
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// SinkMapping adapts the entries sent to a sink to the native vocabulary
+// of its destination, so that no server-side parsing rules are needed. It
+// applies to the remote sinks as well as to the console and file sinks.
+type SinkMapping struct {
+	// Keys renames keys, e.g. {"message": "msg", "datetime": "ts"}. The
+	// message, level and datetime keys as well as top-level field keys can
+	// be renamed.
+	Keys map[string]string `json:"keys,omitempty"`
+
+	// CallerKey, when set, adds the caller (file:line) under this key; it is
+	// not written by default. NameKey moves the logger name, see Get and
+	// zap.Logger.Named, written as logger by default.
+	CallerKey string `json:"callerKey,omitempty"`
+	NameKey   string `json:"nameKey,omitempty"`
+
+	// Levels maps level names ("debug", "info", "warn", "error", "dpanic",
+	// "panic", "fatal") to the values written. Unmapped levels are written
+	// in capitals, as by the other sinks.
+	Levels map[string]string `json:"levels,omitempty"`
+
+	// Time is the timestamp format, see Config.TimeFormat, or empty for the
+	// format of the logger.
+	Time string `json:"time,omitempty"`
+
+	// TimeZone is the zone timestamps are rendered in, see Config.TimeZone,
+	// or empty for the zone of the logger.
+	TimeZone string `json:"timezone,omitempty"`
+}
+
+// SinkMappingPresets are the built-in mappings, selected by name in the
+// LOG_SINK_MAPPING_<SINK> environment variables.
+var SinkMappingPresets = map[string]SinkMapping{
+	"newrelic": {
+		Keys: map[string]string{"level": "severity", "datetime": "timestamp"},
+		Levels: map[string]string{
+			"debug": "debug", "info": "info", "warn": "warn", "error": "error",
+			"dpanic": "critical", "panic": "critical", "fatal": "critical",
+		},
+		Time: "millis",
+	},
+	"ecs": {
+		Keys: map[string]string{"level": "log.level", "datetime": "@timestamp"},
+		Levels: map[string]string{
+			"debug": "debug", "info": "info", "warn": "warn", "error": "error",
+			"dpanic": "critical", "panic": "critical", "fatal": "fatal",
+		},
+		Time: "rfc3339nano",
+	},
+}
+
+// sinkMappingFromEnv returns the mapping configured for sink by
+// LOG_SINK_MAPPING_<SINK>: either the name of one of SinkMappingPresets or
+// a JSON SinkMapping. It returns nil if the variable is not set.
+func sinkMappingFromEnv(sink string) (*SinkMapping, error) {
+	name := "LOG_SINK_MAPPING_" + strings.ToUpper(sink)
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return nil, nil
+	}
+	if m, ok := SinkMappingPresets[value]; ok {
+		return &m, nil
+	}
+	var m SinkMapping
+	if err := json.Unmarshal([]byte(value), &m); err != nil {
+		return nil, fmt.Errorf("invalid %s, expected a mapping name or JSON: %v", name, err)
+	}
+	return &m, nil
+}
+
+// encoderConfig returns cfg adapted to the mapping, timeFormat and timeZone
+// being those of the logger.
+func (m *SinkMapping) encoderConfig(cfg zapcore.EncoderConfig, timeFormat, timeZone string) (zapcore.EncoderConfig, error) {
+	if m == nil {
+		return cfg, nil
+	}
+	rename := func(key string) string {
+		if to, ok := m.Keys[key]; ok {
+			return to
+		}
+		return key
+	}
+	cfg.MessageKey = rename(cfg.MessageKey)
+	cfg.LevelKey = rename(cfg.LevelKey)
+	cfg.TimeKey = rename(cfg.TimeKey)
+	if m.CallerKey != "" {
+		cfg.CallerKey = m.CallerKey
+	}
+	if m.NameKey != "" {
+		cfg.NameKey = m.NameKey
+	}
+
+	if len(m.Levels) > 0 {
+		levels := m.Levels
+		cfg.EncodeLevel = func(l zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+			if v, ok := levels[l.String()]; ok {
+				enc.AppendString(v)
+				return
+			}
+			zapcore.CapitalLevelEncoder(l, enc)
+		}
+	}
+
+	if m.Time != "" || m.TimeZone != "" {
+		if m.Time != "" {
+			timeFormat = m.Time
+		}
+		if m.TimeZone != "" {
+			timeZone = m.TimeZone
+		}
+		encode, err := timeEncoder(timeFormat, timeZone)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid sink mapping time: %v", err)
+		}
+		cfg.EncodeTime = encode
+	}
+	return cfg, nil
+}
+
+// wrap returns core with the field keys renamed by the mapping.
+func (m *SinkMapping) wrap(core zapcore.Core) zapcore.Core {
+	if m == nil || len(m.Keys) == 0 {
+		return core
+	}
+	return &keyMappingCore{Core: core, keys: m.Keys}
+}
+
+// keyMappingCore renames the keys of the fields written to its core.
+type keyMappingCore struct {
+	zapcore.Core
+	keys map[string]string
+}
+
+func (c *keyMappingCore) rename(fields []zapcore.Field) []zapcore.Field {
+	var renamed []zapcore.Field
+	for i, f := range fields {
+		if to, ok := c.keys[f.Key]; ok {
+			if renamed == nil {
+				renamed = append([]zapcore.Field(nil), fields...)
+			}
+			renamed[i].Key = to
+		}
+	}
+	if renamed == nil {
+		return fields
+	}
+	return renamed
+}
+
+func (c *keyMappingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &keyMappingCore{Core: c.Core.With(c.rename(fields)), keys: c.keys}
+}
+
+func (c *keyMappingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *keyMappingCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(ent, c.rename(fields))
+}
@@ -0,0 +1,175 @@
+// sad-go-logger/logger/slog.go
+
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// slogHandler is the slog.Handler of NewSlogHandler.
+type slogHandler struct {
+	// fields are the attributes of WithAttrs, and the namespaces of
+	// WithGroup, in order.
+	fields []zap.Field
+
+	// cache is the global Log with fields, built again once Init, or a
+	// test, replaces Log.
+	cache *atomic.Pointer[slogCache]
+}
+
+// slogCache is a Log with the fields of a slogHandler.
+type slogCache struct {
+	log  *zap.Logger
+	with *zap.Logger
+}
+
+// NewSlogHandler returns a slog.Handler writing to the global Log, so that
+// code using log/slog writes to the same files and remote sinks, e.g.
+//
+//	slog.SetDefault(slog.New(logger.NewSlogHandler()))
+//
+// Records logged with a context go through FromContext(ctx), with the
+// request id and trace fields of the request. The slog levels map to the
+// zap levels below them: debug, info, warn and error, and levels in
+// between to the level below, e.g. slog.LevelInfo+2 to info. The caller is
+// the one of the record, and groups become nested objects.
+func NewSlogHandler() slog.Handler {
+	return &slogHandler{cache: new(atomic.Pointer[slogCache])}
+}
+
+// logger returns the logger of ctx with the fields of h.
+func (h *slogHandler) logger(ctx context.Context) *zap.Logger {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	log := FromContext(ctx)
+	if log != Log {
+		return log.With(h.fields...)
+	}
+	if c := h.cache.Load(); c != nil && c.log == log {
+		return c.with
+	}
+	with := log.With(h.fields...)
+	h.cache.Store(&slogCache{log: log, with: with})
+	return with
+}
+
+func (h *slogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.logger(ctx).Core().Enabled(slogLevel(level))
+}
+
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	ce := h.logger(ctx).Check(slogLevel(r.Level), r.Message)
+	if ce == nil {
+		return nil
+	}
+	if !r.Time.IsZero() {
+		ce.Time = r.Time
+	}
+	if ce.Caller.Defined && r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		ce.Caller = zapcore.NewEntryCaller(frame.PC, frame.File, frame.Line, true)
+		ce.Caller.Function = frame.Function
+	}
+	fields := make([]zap.Field, 0, r.NumAttrs())
+	r.Attrs(func(attr slog.Attr) bool {
+		fields = appendSlogAttr(fields, attr)
+		return true
+	})
+	ce.Write(fields...)
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := append([]zap.Field(nil), h.fields...)
+	for _, attr := range attrs {
+		fields = appendSlogAttr(fields, attr)
+	}
+	return &slogHandler{fields: fields, cache: new(atomic.Pointer[slogCache])}
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	fields := append(append([]zap.Field(nil), h.fields...), zap.Namespace(name))
+	return &slogHandler{fields: fields, cache: new(atomic.Pointer[slogCache])}
+}
+
+// slogLevel returns the zap level of the slog level l.
+func slogLevel(l slog.Level) zapcore.Level {
+	switch {
+	case l >= slog.LevelError:
+		return zapcore.ErrorLevel
+	case l >= slog.LevelWarn:
+		return zapcore.WarnLevel
+	case l >= slog.LevelInfo:
+		return zapcore.InfoLevel
+	default:
+		return zapcore.DebugLevel
+	}
+}
+
+// appendSlogAttr appends attr to fields as a zap field, following the
+// rules of slog.Handler: empty attributes are ignored, and the attributes
+// of groups without a key inlined.
+func appendSlogAttr(fields []zap.Field, attr slog.Attr) []zap.Field {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return fields
+	}
+	v := attr.Value
+	switch v.Kind() {
+	case slog.KindGroup:
+		attrs := v.Group()
+		if len(attrs) == 0 {
+			return fields
+		}
+		if attr.Key == "" {
+			for _, a := range attrs {
+				fields = appendSlogAttr(fields, a)
+			}
+			return fields
+		}
+		return append(fields, zap.Object(attr.Key, slogGroup(attrs)))
+	case slog.KindString:
+		return append(fields, zap.String(attr.Key, v.String()))
+	case slog.KindInt64:
+		return append(fields, zap.Int64(attr.Key, v.Int64()))
+	case slog.KindUint64:
+		return append(fields, zap.Uint64(attr.Key, v.Uint64()))
+	case slog.KindFloat64:
+		return append(fields, zap.Float64(attr.Key, v.Float64()))
+	case slog.KindBool:
+		return append(fields, zap.Bool(attr.Key, v.Bool()))
+	case slog.KindDuration:
+		return append(fields, zap.Duration(attr.Key, v.Duration()))
+	case slog.KindTime:
+		return append(fields, zap.Time(attr.Key, v.Time()))
+	}
+	if err, ok := v.Any().(error); ok {
+		return append(fields, zap.NamedError(attr.Key, err))
+	}
+	return append(fields, zap.Any(attr.Key, v.Any()))
+}
+
+// slogGroup renders the attributes of a group as an object.
+type slogGroup []slog.Attr
+
+// MarshalLogObject implements zapcore.ObjectMarshaler.
+func (g slogGroup) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	var fields []zap.Field
+	for _, attr := range g {
+		fields = appendSlogAttr(fields, attr)
+	}
+	for _, field := range fields {
+		field.AddTo(enc)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+// sad-go-logger/logger/sqlite_store.go
+
+package logger
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sadco-io/sad-go-logger/logger/logfile"
+)
+
+// storeFileName is the default file of the SQLite store.
+const storeFileName = "logs.db"
+
+// localStore is the SQLite store shared by the loggers of the process, and
+// the writer feeding it, opened by the first Build with LOG_SQLITE_STORE
+// set.
+var (
+	localStoreOnce   sync.Once
+	localStore       *logfile.Store
+	localStoreWriter *batchWriter
+)
+
+// openLocalStore opens the SQLite store and returns its writer, or nil if
+// the store cannot be opened. It reads configuration from environment
+// variables:
+//   - LOG_SQLITE_PATH: The database file (default: logs.db in the log directory)
+//   - LOG_SQLITE_MAX_SIZE_MB: The size beyond which the oldest entries are
+//     pruned (default: 100)
+//
+// The store is only available in builds with the logsqlite tag.
+func openLocalStore() *batchWriter {
+	localStoreOnce.Do(func() {
+		path := os.Getenv("LOG_SQLITE_PATH")
+		if path == "" {
+			path = logPath(storeFileName)
+		}
+		maxSize := int64(100)
+		if s := os.Getenv("LOG_SQLITE_MAX_SIZE_MB"); s != "" {
+			n, err := strconv.ParseInt(s, 10, 64)
+			if err != nil || n <= 0 {
+				fmt.Printf("Invalid LOG_SQLITE_MAX_SIZE_MB: %s. Using 100.\n", s)
+			} else {
+				maxSize = n
+			}
+		}
+
+		store, err := logfile.OpenStore(path, logfile.StoreOptions{MaxSize: maxSize << 20})
+		if err != nil {
+			fmt.Printf("Failed to open SQLite log store: %v. SQLite logging disabled.\n", err)
+			return
+		}
+		localStore = store
+		localStoreWriter = newBatchWriter(batchConfig{
+			name:          "SQLite store",
+			batchSize:     500,
+			flushInterval: time.Second,
+			send: func(entries []map[string]interface{}) error {
+				records := make([]logfile.Record, len(entries))
+				for i, entry := range entries {
+					records[i] = entry
+				}
+				return store.Insert(records)
+			},
+		})
+	})
+	return localStoreWriter
+}
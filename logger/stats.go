@@ -0,0 +1,77 @@
+// sad-go-logger/logger/stats.go
+
+package logger
+
+import (
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// startTime is when the package was initialized.
+var startTime = time.Now()
+
+// entryCounts counts the entries written by all built loggers, per level,
+// indexed by level - zapcore.DebugLevel.
+var entryCounts [zapcore.FatalLevel - zapcore.DebugLevel + 1]atomic.Uint64
+
+// Stats is a snapshot of the logger's counters.
+type Stats struct {
+	// Uptime is the time since the package was initialized.
+	Uptime time.Duration `json:"-"`
+
+	// Entries is the number of entries written per level since start.
+	Entries map[string]uint64 `json:"entries"`
+
+	// StreamSubscribers is the number of connected live tail clients.
+	StreamSubscribers int `json:"streamSubscribers"`
+}
+
+// GetStats returns a snapshot of the logger's counters.
+func GetStats() Stats {
+	s := Stats{
+		Uptime:  time.Since(startTime),
+		Entries: make(map[string]uint64, len(entryCounts)),
+	}
+	for i := range entryCounts {
+		level := zapcore.Level(i) + zapcore.DebugLevel
+		s.Entries[level.String()] = entryCounts[i].Load()
+	}
+
+	liveStream.mu.RLock()
+	s.StreamSubscribers = len(liveStream.subs)
+	liveStream.mu.RUnlock()
+	return s
+}
+
+// statsCore counts the entries that pass the logger's level.
+type statsCore struct {
+	level zapcore.LevelEnabler
+}
+
+func (c *statsCore) Enabled(level zapcore.Level) bool {
+	return c.level.Enabled(level)
+}
+
+func (c *statsCore) With([]zapcore.Field) zapcore.Core {
+	return c
+}
+
+func (c *statsCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *statsCore) Write(ent zapcore.Entry, _ []zapcore.Field) error {
+	if ent.Level >= zapcore.DebugLevel && ent.Level <= zapcore.FatalLevel {
+		entryCounts[ent.Level-zapcore.DebugLevel].Add(1)
+	}
+	return nil
+}
+
+func (c *statsCore) Sync() error {
+	return nil
+}
@@ -0,0 +1,135 @@
+// sad-go-logger/logger/stdlog.go
+
+package logger
+
+import (
+	"bytes"
+	"log"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// StdLogSource is the value of the source field of the entries of
+// RedirectStdLog and StdWriter.
+const StdLogSource = "stdlog"
+
+// stdLogCallerSkip skips the frames of the log package and of
+// stdLogWriter.Write to report the caller of log.Printf.
+const stdLogCallerSkip = 3
+
+// stdLogCache is the logger of Log for the entries of the standard
+// library, with the source field.
+type stdLogCache struct {
+	log    *zap.Logger
+	stdlog *zap.Logger
+}
+
+// stdLogger returns the logger of the current Log with the source field
+// and opts, cached in cache until Init, or a test, replaces Log.
+func stdLogger(cache *atomic.Pointer[stdLogCache], opts ...zap.Option) *zap.Logger {
+	log := Log
+	if c := cache.Load(); c != nil && c.log == log {
+		return c.stdlog
+	}
+	l := log.WithOptions(opts...).With(zap.String("source", StdLogSource))
+	cache.Store(&stdLogCache{log: log, stdlog: l})
+	return l
+}
+
+// stdLogWriter is the output of the log package set by RedirectStdLog,
+// written once per message.
+type stdLogWriter struct {
+	level zapcore.Level
+	cache atomic.Pointer[stdLogCache]
+}
+
+func (w *stdLogWriter) Write(p []byte) (int, error) {
+	l := stdLogger(&w.cache, zap.AddCallerSkip(stdLogCallerSkip))
+	if ce := l.Check(w.level, string(bytes.TrimSuffix(p, []byte("\n")))); ce != nil {
+		ce.Write()
+	}
+	return len(p), nil
+}
+
+// RedirectStdLog sends the output of the standard library log package to
+// Log, every message as an entry at level with the source field set to
+// "stdlog" and the caller of log.Printf, e.g.
+//
+//	defer logger.RedirectStdLog(zap.WarnLevel)()
+//
+// The flags of the log package are cleared, the entries having their own
+// timestamp, and its prefix kept at the beginning of the messages. The
+// function returned restores the previous output and flags. Libraries
+// writing to stderr directly can be given a StdWriter instead.
+func RedirectStdLog(level zapcore.Level) func() {
+	flags, output := log.Flags(), log.Writer()
+	log.SetFlags(0)
+	log.SetOutput(&stdLogWriter{level: level})
+	return func() {
+		log.SetFlags(flags)
+		log.SetOutput(output)
+	}
+}
+
+// StdWriter is an io.Writer logging every line written to it as an entry
+// of Log, at its level with the source field set to "stdlog", for
+// the libraries writing their messages to an io.Writer such as os.Stderr.
+// Lines split across writes are logged once complete, and the last one on
+// Close.
+type StdWriter struct {
+	level zapcore.Level
+	cache atomic.Pointer[stdLogCache]
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+// NewStdWriter returns a StdWriter logging at level.
+func NewStdWriter(level zapcore.Level) *StdWriter {
+	return &StdWriter{level: level}
+}
+
+// Write logs the complete lines of p, keeping the rest until the end of
+// its line is written.
+func (w *StdWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.log(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+	if len(w.buf) == 0 {
+		w.buf = nil
+	}
+	return len(p), nil
+}
+
+// Close logs the last line written, if it did not end with a newline.
+func (w *StdWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.buf) > 0 {
+		w.log(w.buf)
+		w.buf = nil
+	}
+	return nil
+}
+
+// log logs line, without its carriage return, unless it is blank.
+func (w *StdWriter) log(line []byte) {
+	line = bytes.TrimSuffix(line, []byte("\r"))
+	if len(bytes.TrimSpace(line)) == 0 {
+		return
+	}
+	if ce := stdLogger(&w.cache, zap.WithCaller(false)).Check(w.level, string(line)); ce != nil {
+		ce.Write()
+	}
+}
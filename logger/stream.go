@@ -0,0 +1,232 @@
+// sad-go-logger/logger/stream.go
+
+package logger
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sadco-io/sad-go-logger/logger/logfile"
+	"go.uber.org/zap/zapcore"
+)
+
+// streamBufferSize is the number of entries buffered per live stream
+// subscriber. When a subscriber falls behind, further entries are dropped
+// for it (and only for it) and it is told how many were missed.
+const streamBufferSize = 256
+
+// streamHub fans out live entries to the subscribers of the WebSocket and
+// Server-Sent Events endpoints. It is teed into every logger built by
+// Config.Build and costs nothing while nobody is subscribed.
+type streamHub struct {
+	mu   sync.RWMutex
+	subs map[*subscription]struct{}
+
+	// minLevel is the lowest level any subscriber wants, or
+	// noSubscribersLevel when there are none.
+	minLevel atomic.Int32
+
+	encoder zapcore.Encoder
+}
+
+// noSubscribersLevel disables the hub core while nobody is subscribed.
+const noSubscribersLevel = int32(zapcore.InvalidLevel)
+
+// liveStream is the hub shared by all loggers of the process.
+var liveStream = newStreamHub()
+
+func newStreamHub() *streamHub {
+	h := &streamHub{
+		subs:    make(map[*subscription]struct{}),
+		encoder: zapcore.NewJSONEncoder(newEncoderConfig()),
+	}
+	h.minLevel.Store(noSubscribersLevel)
+	return h
+}
+
+// subscription is one live stream client.
+type subscription struct {
+	mu     sync.Mutex
+	filter logfile.Filter
+
+	// entries carries JSON-encoded entries to the client.
+	entries chan []byte
+
+	// dropped counts entries missed since the client last caught up.
+	dropped atomic.Int64
+}
+
+// Filter returns the current filter of the subscription.
+func (s *subscription) Filter() logfile.Filter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.filter
+}
+
+// TakeDropped returns and resets the number of entries dropped for the
+// subscription.
+func (s *subscription) TakeDropped() int64 {
+	return s.dropped.Swap(0)
+}
+
+// subscribe registers a new subscriber receiving the entries that match
+// filter.
+func (h *streamHub) subscribe(filter logfile.Filter) *subscription {
+	s := &subscription{filter: filter, entries: make(chan []byte, streamBufferSize)}
+	h.mu.Lock()
+	h.subs[s] = struct{}{}
+	h.mu.Unlock()
+	h.updateMinLevel()
+	return s
+}
+
+// unsubscribe removes s from the hub.
+func (h *streamHub) unsubscribe(s *subscription) {
+	h.mu.Lock()
+	delete(h.subs, s)
+	h.mu.Unlock()
+	h.updateMinLevel()
+}
+
+// setFilter replaces the filter of s.
+func (h *streamHub) setFilter(s *subscription, filter logfile.Filter) {
+	s.mu.Lock()
+	s.filter = filter
+	s.mu.Unlock()
+	h.updateMinLevel()
+}
+
+func (h *streamHub) updateMinLevel() {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	min := noSubscribersLevel
+	for s := range h.subs {
+		if level := int32(s.Filter().MinLevel); level < min {
+			min = level
+		}
+	}
+	h.minLevel.Store(min)
+}
+
+// publish delivers an entry to every matching subscriber without blocking.
+func (h *streamHub) publish(ent zapcore.Entry, fields []zapcore.Field) error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if len(h.subs) == 0 {
+		return nil
+	}
+
+	record := entryRecord(ent, fields)
+	var encoded []byte
+	for s := range h.subs {
+		if !s.Filter().Match(record) {
+			continue
+		}
+		if encoded == nil {
+			buf, err := h.encoder.Clone().EncodeEntry(ent, fields)
+			if err != nil {
+				return err
+			}
+			encoded = append([]byte(nil), buf.Bytes()...)
+			buf.Free()
+		}
+		select {
+		case s.entries <- encoded:
+		default:
+			s.dropped.Add(1)
+		}
+	}
+	return nil
+}
+
+// entryRecord converts an entry to the record form used by logfile filters.
+func entryRecord(ent zapcore.Entry, fields []zapcore.Field) logfile.Record {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	record := logfile.Record(enc.Fields)
+	record[logfile.MessageKey] = ent.Message
+	record[logfile.LevelKey] = ent.Level.CapitalString()
+	return record
+}
+
+// streamCore is the zapcore.Core that feeds a streamHub.
+type streamCore struct {
+	hub    *streamHub
+	fields []zapcore.Field
+}
+
+func (c *streamCore) Enabled(level zapcore.Level) bool {
+	return int32(level) >= c.hub.minLevel.Load()
+}
+
+func (c *streamCore) With(fields []zapcore.Field) zapcore.Core {
+	return &streamCore{hub: c.hub, fields: append(append([]zapcore.Field(nil), c.fields...), fields...)}
+}
+
+func (c *streamCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *streamCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	all := fields
+	if len(c.fields) > 0 {
+		all = append(append([]zapcore.Field(nil), c.fields...), fields...)
+	}
+	return c.hub.publish(ent, all)
+}
+
+func (c *streamCore) Sync() error {
+	return nil
+}
+
+// streamFilter is the filter negotiated by a live stream client, either in
+// the query string or, for WebSocket clients, in a JSON message.
+type streamFilter struct {
+	Level  string            `json:"level"`
+	Grep   string            `json:"grep"`
+	Fields map[string]string `json:"fields"`
+}
+
+// streamFilterFromQuery reads the level, grep and field=key=value
+// parameters of a live stream request.
+func streamFilterFromQuery(q url.Values) streamFilter {
+	sf := streamFilter{Level: q.Get("level"), Grep: q.Get("grep"), Fields: map[string]string{}}
+	for _, kv := range q["field"] {
+		if key, value, ok := strings.Cut(kv, "="); ok {
+			sf.Fields[key] = value
+		}
+	}
+	return sf
+}
+
+// compile validates the negotiated filter.
+func (sf streamFilter) compile() (logfile.Filter, error) {
+	f := logfile.Filter{MinLevel: zapcore.DebugLevel}
+	if sf.Level != "" {
+		var err error
+		if f.MinLevel, err = ParseLevel(sf.Level); err != nil {
+			return f, err
+		}
+	}
+	if sf.Grep != "" {
+		re, err := regexp.Compile(sf.Grep)
+		if err != nil {
+			return f, fmt.Errorf("invalid grep %q: %v", sf.Grep, err)
+		}
+		f.Grep = re
+	}
+	if len(sf.Fields) > 0 {
+		f.Fields = sf.Fields
+	}
+	return f, nil
+}
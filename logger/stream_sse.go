@@ -0,0 +1,75 @@
+// sad-go-logger/logger/stream_sse.go
+
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// SSEHandler returns a handler that streams live log entries as
+// Server-Sent Events, for environments where proxies block WebSockets. It
+// shares the subscriptions and filters of WebSocketHandler: clients filter
+// with the level, grep and field (key=value, repeatable) query parameters,
+// and to change the filter they reconnect with new parameters.
+//
+// Each entry is sent as a "log" event whose data is the JSON-encoded entry.
+// A slow client misses entries instead of slowing down the application,
+// and receives a "dropped" event with the number of missed entries once it
+// catches up.
+//
+// The handler performs no authentication; mount it behind the service's own
+// admin authentication.
+func SSEHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+			return
+		}
+
+		filter, err := streamFilterFromQuery(r.URL.Query()).compile()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("X-Accel-Buffering", "no") // Disable proxy buffering (nginx)
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		sub := liveStream.subscribe(filter)
+		defer liveStream.unsubscribe(sub)
+
+		ping := time.NewTicker(streamPingInterval)
+		defer ping.Stop()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-ping.C:
+				if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+					return
+				}
+			case entry := <-sub.entries:
+				if _, err := fmt.Fprintf(w, "event: log\ndata: %s\n\n", bytes.TrimRight(entry, "\n")); err != nil {
+					return
+				}
+				if len(sub.entries) == 0 {
+					if n := sub.TakeDropped(); n > 0 {
+						if _, err := fmt.Fprintf(w, "event: dropped\ndata: {\"dropped\":%d}\n\n", n); err != nil {
+							return
+						}
+					}
+				}
+			}
+			flusher.Flush()
+		}
+	})
+}
@@ -0,0 +1,121 @@
+// sad-go-logger/logger/stream_ws.go
+
+package logger
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// streamPingInterval is how often idle WebSocket clients are pinged.
+const streamPingInterval = 30 * time.Second
+
+// streamNotice is a control message sent to live stream clients alongside
+// the entries themselves.
+type streamNotice struct {
+	Type    string `json:"type"`
+	Dropped int64  `json:"dropped,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// WebSocketHandler returns a handler that streams live log entries over
+// WebSocket, one JSON-encoded entry per text message. Clients choose what
+// they receive with the level, grep and field (key=value, repeatable) query
+// parameters, and can replace that filter at any time by sending a JSON
+// message such as {"level":"warn","fields":{"component":"billing"}}.
+//
+// Each client has its own buffer: a slow client misses entries instead of
+// slowing down the application, and receives {"type":"dropped","dropped":n}
+// once it catches up.
+//
+// The handler performs no authentication; mount it behind the service's own
+// admin authentication.
+func WebSocketHandler() http.Handler {
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 4096,
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		filter, err := streamFilterFromQuery(r.URL.Query()).compile()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return // Upgrade already replied to the client
+		}
+		defer conn.Close()
+
+		sub := liveStream.subscribe(filter)
+		defer liveStream.unsubscribe(sub)
+
+		// Read filter updates until the client goes away.
+		notices := make(chan streamNotice, 1)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for {
+				var sf streamFilter
+				if err := conn.ReadJSON(&sf); err != nil {
+					switch err.(type) {
+					case *json.SyntaxError, *json.UnmarshalTypeError:
+						notifyStream(notices, streamNotice{Type: "error", Error: err.Error()})
+						continue
+					}
+					return
+				}
+				filter, err := sf.compile()
+				if err != nil {
+					notifyStream(notices, streamNotice{Type: "error", Error: err.Error()})
+					continue
+				}
+				liveStream.setFilter(sub, filter)
+				notifyStream(notices, streamNotice{Type: "filter"})
+			}
+		}()
+
+		ping := time.NewTicker(streamPingInterval)
+		defer ping.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case notice := <-notices:
+				if err := conn.WriteJSON(notice); err != nil {
+					return
+				}
+			case <-ping.C:
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(time.Second)); err != nil {
+					return
+				}
+			case entry := <-sub.entries:
+				if err := conn.WriteMessage(websocket.TextMessage, entry); err != nil {
+					return
+				}
+				if len(sub.entries) == 0 {
+					if n := sub.TakeDropped(); n > 0 {
+						if err := conn.WriteJSON(streamNotice{Type: "dropped", Dropped: n}); err != nil {
+							return
+						}
+					}
+				}
+			}
+		}
+	})
+}
+
+// notifyStream queues a notice for the client, dropping it if one is
+// already pending.
+func notifyStream(notices chan<- streamNotice, notice streamNotice) {
+	select {
+	case notices <- notice:
+	default:
+	}
+}
@@ -0,0 +1,146 @@
+// sad-go-logger/logger/sugar.go
+
+package logger
+
+import (
+	"sync/atomic"
+
+	"go.uber.org/zap"
+)
+
+// The functions of this file write to the global Log through its
+// zap.SugaredLogger, reporting their callers rather than themselves, e.g.
+//
+//	logger.Infof("Listening on %s", addr)
+//	logger.Errorw("Request failed", "path", r.URL.Path, "error", err)
+//
+// The plain forms concatenate their arguments, adding spaces between
+// operands when neither is a string, the f forms format them with
+// fmt.Sprintf, and the w forms take a message and alternating keys and
+// values, or zap fields. DPanic panics in development, Panic panics and
+// Fatal exits the process, after writing the entry.
+
+// sugarCache is the sugared logger of a Log.
+type sugarCache struct {
+	log   *zap.Logger
+	sugar *zap.SugaredLogger
+}
+
+var sugared atomic.Pointer[sugarCache]
+
+// sugar returns the sugared logger of the current Log, built again once
+// Init, or a test, replaces Log.
+func sugar() *zap.SugaredLogger {
+	log := Log
+	if c := sugared.Load(); c != nil && c.log == log {
+		return c.sugar
+	}
+	s := log.WithOptions(zap.AddCallerSkip(1)).Sugar()
+	sugared.Store(&sugarCache{log: log, sugar: s})
+	return s
+}
+
+// Debug writes args at debug level, see zap.SugaredLogger.Debug.
+func Debug(args ...interface{}) {
+	sugar().Debug(args...)
+}
+
+// Debugf writes template formatted with args at debug level.
+func Debugf(template string, args ...interface{}) {
+	sugar().Debugf(template, args...)
+}
+
+// Debugw writes msg with the keysAndValues at debug level.
+func Debugw(msg string, keysAndValues ...interface{}) {
+	sugar().Debugw(msg, keysAndValues...)
+}
+
+// Info writes args at info level, see zap.SugaredLogger.Info.
+func Info(args ...interface{}) {
+	sugar().Info(args...)
+}
+
+// Infof writes template formatted with args at info level.
+func Infof(template string, args ...interface{}) {
+	sugar().Infof(template, args...)
+}
+
+// Infow writes msg with the keysAndValues at info level.
+func Infow(msg string, keysAndValues ...interface{}) {
+	sugar().Infow(msg, keysAndValues...)
+}
+
+// Warn writes args at warn level, see zap.SugaredLogger.Warn.
+func Warn(args ...interface{}) {
+	sugar().Warn(args...)
+}
+
+// Warnf writes template formatted with args at warn level.
+func Warnf(template string, args ...interface{}) {
+	sugar().Warnf(template, args...)
+}
+
+// Warnw writes msg with the keysAndValues at warn level.
+func Warnw(msg string, keysAndValues ...interface{}) {
+	sugar().Warnw(msg, keysAndValues...)
+}
+
+// Error writes args at error level, see zap.SugaredLogger.Error.
+func Error(args ...interface{}) {
+	sugar().Error(args...)
+}
+
+// Errorf writes template formatted with args at error level.
+func Errorf(template string, args ...interface{}) {
+	sugar().Errorf(template, args...)
+}
+
+// Errorw writes msg with the keysAndValues at error level.
+func Errorw(msg string, keysAndValues ...interface{}) {
+	sugar().Errorw(msg, keysAndValues...)
+}
+
+// DPanic writes args at dpanic level, see zap.SugaredLogger.DPanic.
+func DPanic(args ...interface{}) {
+	sugar().DPanic(args...)
+}
+
+// DPanicf writes template formatted with args at dpanic level.
+func DPanicf(template string, args ...interface{}) {
+	sugar().DPanicf(template, args...)
+}
+
+// DPanicw writes msg with the keysAndValues at dpanic level.
+func DPanicw(msg string, keysAndValues ...interface{}) {
+	sugar().DPanicw(msg, keysAndValues...)
+}
+
+// Panic writes args at panic level, see zap.SugaredLogger.Panic.
+func Panic(args ...interface{}) {
+	sugar().Panic(args...)
+}
+
+// Panicf writes template formatted with args at panic level.
+func Panicf(template string, args ...interface{}) {
+	sugar().Panicf(template, args...)
+}
+
+// Panicw writes msg with the keysAndValues at panic level.
+func Panicw(msg string, keysAndValues ...interface{}) {
+	sugar().Panicw(msg, keysAndValues...)
+}
+
+// Fatal writes args at fatal level, see zap.SugaredLogger.Fatal.
+func Fatal(args ...interface{}) {
+	sugar().Fatal(args...)
+}
+
+// Fatalf writes template formatted with args at fatal level.
+func Fatalf(template string, args ...interface{}) {
+	sugar().Fatalf(template, args...)
+}
+
+// Fatalw writes msg with the keysAndValues at fatal level.
+func Fatalw(msg string, keysAndValues ...interface{}) {
+	sugar().Fatalw(msg, keysAndValues...)
+}
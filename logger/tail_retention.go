@@ -0,0 +1,250 @@
+// sad-go-logger/logger/tail_retention.go
+
+package logger
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// maxTailBytes caps the encoded entries held for one request. Entries past
+// the cap are dropped and counted in the request summary.
+const maxTailBytes = 1 << 20
+
+// tailBuffer holds the debug and info entries of one request destined for
+// the remote sinks until the outcome of the request is known.
+type tailBuffer struct {
+	mu      sync.Mutex
+	pending []tailWrite
+	size    int
+	dropped int
+	errored bool
+}
+
+// tailWrite is one encoded entry and the sink it is destined for.
+type tailWrite struct {
+	sink zapcore.WriteSyncer
+	p    []byte
+}
+
+// cores returns the cores writing to sink for a request logger: warnings
+// and errors are written straight away, lower levels are held in t.
+func (t *tailBuffer) cores(enc zapcore.Encoder, sink zapcore.WriteSyncer, level zapcore.LevelEnabler) []zapcore.Core {
+	direct := zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+		return l >= zapcore.WarnLevel && level.Enabled(l)
+	})
+	held := zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+		return l < zapcore.WarnLevel && level.Enabled(l)
+	})
+	return []zapcore.Core{
+		zapcore.NewCore(enc, sink, direct),
+		zapcore.NewCore(enc, &tailWriter{tail: t, sink: sink}, held),
+		&tailOutcomeCore{tail: t},
+	}
+}
+
+// flush writes the held entries to their sinks.
+func (t *tailBuffer) flush() {
+	t.mu.Lock()
+	pending := t.pending
+	t.pending, t.size = nil, 0
+	t.mu.Unlock()
+
+	for _, w := range pending {
+		if _, err := w.sink.Write(w.p); err != nil {
+			fmt.Printf("Failed to write retained log entry: %v\n", err)
+		}
+	}
+}
+
+// tailWriter holds the entries written to it in its tailBuffer.
+type tailWriter struct {
+	tail *tailBuffer
+	sink zapcore.WriteSyncer
+}
+
+func (w *tailWriter) Write(p []byte) (int, error) {
+	w.tail.mu.Lock()
+	defer w.tail.mu.Unlock()
+
+	if w.tail.size+len(p) > maxTailBytes {
+		w.tail.dropped++
+		return len(p), nil
+	}
+	// The encoder reuses p once Write returns.
+	w.tail.pending = append(w.tail.pending, tailWrite{sink: w.sink, p: append([]byte(nil), p...)})
+	w.tail.size += len(p)
+	return len(p), nil
+}
+
+func (w *tailWriter) Sync() error {
+	return nil
+}
+
+// tailOutcomeCore records in its tailBuffer that the request logged an
+// error.
+type tailOutcomeCore struct {
+	tail *tailBuffer
+}
+
+func (c *tailOutcomeCore) Enabled(level zapcore.Level) bool {
+	return level >= zapcore.ErrorLevel
+}
+
+func (c *tailOutcomeCore) With([]zapcore.Field) zapcore.Core {
+	return c
+}
+
+func (c *tailOutcomeCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *tailOutcomeCore) Write(zapcore.Entry, []zapcore.Field) error {
+	c.tail.mu.Lock()
+	c.tail.errored = true
+	c.tail.mu.Unlock()
+	return nil
+}
+
+func (c *tailOutcomeCore) Sync() error {
+	return nil
+}
+
+// withTail returns l, a logger built by newLogger, with its entries for the
+// remote sinks held in tail. The returned logger keeps the fields, options
+// and debug override of l. It returns false if l was built otherwise.
+func (b *builtLogger) withTail(l *zap.Logger, tail *tailBuffer) (*zap.Logger, bool) {
+	ok := false
+	l = l.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		c, is := core.(*debugOverrideCore)
+		if !is {
+			return core
+		}
+		ok = true
+		held := b.newCore(b.level, tail, nil)
+		held.forced = c.forced
+		return held.With(c.fields)
+	}))
+	return l, ok
+}
+
+// TailRetentionMiddleware cuts the volume sent to the remote sinks while
+// keeping the detail of failed requests. The debug and info entries of the
+// request logger, stored in the request context for FromContext, reach the
+// console and files as usual but are held back from the remote sinks until
+// the request completes. They are forwarded only if the request logged an
+// error, responded with a 5xx status, panicked or took longer than latency
+// (a latency of 0 disables the threshold); otherwise they are discarded.
+// Warnings and errors are always forwarded immediately. The request logger
+// is the logger already in the request context, or the global Log, with
+// its fields and options. Like ScopeMiddleware, it correlates the request
+// logger with the trace of the traceparent header.
+//
+// Every request ends with a "Request completed" summary entry on the global
+// Log. While the global Log is replaced, e.g. by RouteToTest, or the request
+// context carries a logger that was not built by this package, requests
+// pass through untouched.
+func TailRetentionMiddleware(latency time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			g := current()
+			if g == nil || Log != g.logger {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ctx := requestContext(r)
+			base, span := scopeLogger(ctx)
+			tail := &tailBuffer{}
+			l, ok := g.withTail(base, tail)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			defer func() {
+				if p := recover(); p != nil {
+					tail.flush()
+					panic(p)
+				}
+			}()
+
+			next.ServeHTTP(rec, r.WithContext(newScope(ctx, l, span)))
+
+			duration := time.Since(start)
+			tail.mu.Lock()
+			retained := tail.errored || rec.status >= http.StatusInternalServerError || (latency > 0 && duration > latency)
+			dropped := tail.dropped
+			tail.mu.Unlock()
+			if retained {
+				tail.flush()
+			}
+
+			fields := []zap.Field{
+				zap.String("method", r.Method),
+				zap.String("path", r.URL.Path),
+				zap.Int("status", rec.status),
+				zap.Duration("duration", duration),
+				zap.Bool("retained", retained),
+			}
+			if dropped > 0 {
+				fields = append(fields, zap.Int("droppedEntries", dropped))
+			}
+			Log.Info("Request completed", fields...)
+		})
+	}
+}
+
+// statusRecorder records the status code and body size written to a
+// ResponseWriter.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.status, r.wroteHeader = code, true
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(p []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(p)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// Flush and Hijack keep streaming and WebSocket handlers working behind the
+// middleware.
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	return h.Hijack()
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,132 @@
+// sad-go-logger/logger/tail_retention_test.go
+
+package logger
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// recordingWriter is a remote sink keeping what is written to it.
+type recordingWriter struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *recordingWriter) Sync() error {
+	return nil
+}
+
+func (w *recordingWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+// useRemoteTestLogger replaces the global Log with a debug logger whose only
+// sink is the remote sink remote, restoring it once the test finishes.
+func useRemoteTestLogger(t *testing.T, remote *recordingWriter) {
+	t.Helper()
+	enc := zapcore.NewJSONEncoder(newEncoderConfig())
+	sink := zapcore.AddSync(remote)
+	b := &builtLogger{level: zap.NewAtomicLevelAt(zap.DebugLevel), sampling: &samplingControl{}}
+	b.sinks.Store(&sinkSet{newCore: func(level zapcore.LevelEnabler, tail *tailBuffer, extra []zapcore.Core) zapcore.Core {
+		if tail != nil {
+			return zapcore.NewTee(append(tail.cores(enc, sink, level), extra...)...)
+		}
+		return zapcore.NewTee(append([]zapcore.Core{zapcore.NewCore(enc, sink, level)}, extra...)...)
+	}})
+	b.logger = b.newLogger(nil)
+
+	previous, previousLog := global, Log
+	global, Log = b, b.logger
+	t.Cleanup(func() { global, Log = previous, previousLog })
+}
+
+func serveTail(ctx func(*http.Request) *http.Request, status int) {
+	handler := TailRetentionMiddleware(0)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).Debug("loading the cart")
+		w.WriteHeader(status)
+	}))
+	r := httptest.NewRequest(http.MethodGet, "/cart", nil)
+	if ctx != nil {
+		r = ctx(r)
+	}
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+}
+
+func TestTailRetentionDiscardsSuccessfulRequests(t *testing.T) {
+	remote := &recordingWriter{}
+	useRemoteTestLogger(t, remote)
+
+	serveTail(nil, http.StatusOK)
+
+	got := remote.String()
+	if strings.Contains(got, "loading the cart") {
+		t.Errorf("entry of a successful request forwarded:\n%s", got)
+	}
+	if !strings.Contains(got, `"message":"Request completed"`) || !strings.Contains(got, `"retained":false`) {
+		t.Errorf("request summary not written:\n%s", got)
+	}
+}
+
+func TestTailRetentionForwardsFailedRequests(t *testing.T) {
+	remote := &recordingWriter{}
+	useRemoteTestLogger(t, remote)
+
+	serveTail(nil, http.StatusBadGateway)
+
+	got := remote.String()
+	if !strings.Contains(got, "loading the cart") || !strings.Contains(got, `"retained":true`) {
+		t.Errorf("entry of a failed request not forwarded:\n%s", got)
+	}
+}
+
+func TestTailRetentionKeepsTheScopedLogger(t *testing.T) {
+	remote := &recordingWriter{}
+	useRemoteTestLogger(t, remote)
+
+	serveTail(func(r *http.Request) *http.Request {
+		l := ForceDebug(Log.With(zap.String("request_id", "req-1")))
+		return r.WithContext(NewContext(r.Context(), l))
+	}, http.StatusInternalServerError)
+
+	for _, line := range strings.Split(remote.String(), "\n") {
+		if strings.Contains(line, "loading the cart") {
+			if !strings.Contains(line, `"request_id":"req-1"`) {
+				t.Errorf("entry lost the fields of the scoped logger: %s", line)
+			}
+			return
+		}
+	}
+	t.Errorf("entry not forwarded:\n%s", remote.String())
+}
+
+func TestTailRetentionPassesForeignLoggersThrough(t *testing.T) {
+	useRemoteTestLogger(t, &recordingWriter{})
+	foreign, _ := NewTest(t)
+
+	var got *zap.Logger
+	handler := TailRetentionMiddleware(0)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = FromContext(r.Context())
+	}))
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), r.WithContext(NewContext(r.Context(), foreign)))
+
+	if got != foreign {
+		t.Error("the logger of the request context was replaced")
+	}
+}
@@ -0,0 +1,130 @@
+// sad-go-logger/logger/testing.go
+
+package logger
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// Observer gives tests access to the entries captured by a logger created
+// with NewTest.
+type Observer struct {
+	logs *observer.ObservedLogs
+}
+
+// testObservers maps each test to the Observer of the last logger created
+// for it by NewTest, so Expect can find it.
+var testObservers sync.Map
+
+// NewTest returns a logger that records every entry in memory, at all
+// levels, together with an Observer to inspect them. It does not touch the
+// global Log, create files or open remote connections, so code under test
+// should be handed the returned logger instead of using Log directly.
+// Entries are also written through t.Log, see NewTBWriter. Registered
+// hooks, such as schema validation, run as for built loggers.
+//
+// Additional zap options are applied to the logger, e.g.
+// zap.WithClock(NewManualClock(start)) for deterministic timestamps.
+func NewTest(t testing.TB, opts ...zap.Option) (*zap.Logger, *Observer) {
+	t.Helper()
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	core = &hookCore{cores: []zapcore.Core{core, newTBCore(t)}}
+	opts = append([]zap.Option{zap.AddCaller()}, opts...)
+
+	obs := &Observer{logs: logs}
+	testObservers.Store(t, obs)
+	t.Cleanup(func() {
+		testObservers.Delete(t)
+	})
+	return zap.New(core, opts...), obs
+}
+
+// tbWriter is a zapcore.WriteSyncer that forwards each encoded entry to
+// testing.TB.Log, so it is interleaved with the test's own output and only
+// shown for failing tests (or with go test -v).
+type tbWriter struct {
+	t testing.TB
+}
+
+// NewTBWriter returns a WriteSyncer that writes through t.Log.
+func NewTBWriter(t testing.TB) zapcore.WriteSyncer {
+	return tbWriter{t: t}
+}
+
+// Write implements io.Writer. The trailing newline added by the encoder is
+// dropped because t.Log adds its own.
+func (w tbWriter) Write(p []byte) (int, error) {
+	w.t.Helper()
+	w.t.Log(string(bytes.TrimRight(p, "\n")))
+	return len(p), nil
+}
+
+// Sync implements zapcore.WriteSyncer.
+func (w tbWriter) Sync() error {
+	return nil
+}
+
+// newTBCore returns a debug-level core that renders entries with the
+// package's console encoder through t.Log.
+func newTBCore(t testing.TB) zapcore.Core {
+	return zapcore.NewCore(zapcore.NewConsoleEncoder(newEncoderConfig()), NewTBWriter(t), zapcore.DebugLevel)
+}
+
+// RouteToTest replaces the global Log with a logger that writes console
+// output through t.Log instead of stdout, files and remote sinks, and
+// restores the previous logger when the test finishes. Because it swaps
+// the global, it must not be used from tests that run in parallel.
+func RouteToTest(t testing.TB) {
+	t.Helper()
+
+	previous := Log
+	Log = zap.New(newTBCore(t), zap.AddCaller(), zap.Fields(
+		zap.String("hostname", hostname),
+		zap.String("serviceName", serviceName),
+	))
+	t.Cleanup(func() {
+		Log = previous
+	})
+}
+
+// Entries returns a copy of all captured entries, in the order they were
+// logged.
+func (o *Observer) Entries() []observer.LoggedEntry {
+	return o.logs.All()
+}
+
+// Len returns the number of captured entries.
+func (o *Observer) Len() int {
+	return o.logs.Len()
+}
+
+// FilterMessage returns an Observer over the captured entries whose message
+// is exactly msg.
+func (o *Observer) FilterMessage(msg string) *Observer {
+	return &Observer{logs: o.logs.FilterMessage(msg)}
+}
+
+// FilterMessageSnippet returns an Observer over the captured entries whose
+// message contains snippet.
+func (o *Observer) FilterMessageSnippet(snippet string) *Observer {
+	return &Observer{logs: o.logs.FilterMessageSnippet(snippet)}
+}
+
+// FilterField returns an Observer over the captured entries that carry
+// field, compared by key, type and value.
+func (o *Observer) FilterField(field zap.Field) *Observer {
+	return &Observer{logs: o.logs.FilterField(field)}
+}
+
+// FilterFieldKey returns an Observer over the captured entries that carry a
+// field named key, whatever its value.
+func (o *Observer) FilterFieldKey(key string) *Observer {
+	return &Observer{logs: o.logs.FilterFieldKey(key)}
+}
@@ -0,0 +1,87 @@
+// sad-go-logger/logger/testing_test.go
+
+package logger
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestNewTestCapturesEntries(t *testing.T) {
+	previous := Log
+	log, obs := NewTest(t)
+
+	log.Debug("cache warmed", zap.Int("entries", 3))
+	log.Info("User logged in", zap.String("user", "john"))
+	log.With(zap.String("request", "r1")).Error("payment failed", zap.Int("order_id", 42))
+
+	if Log != previous {
+		t.Error("NewTest replaced the global Log")
+	}
+	if obs.Len() != 3 {
+		t.Fatalf("got %d entries, want 3", obs.Len())
+	}
+
+	entries := obs.Entries()
+	if entries[0].Level != zapcore.DebugLevel || entries[0].Message != "cache warmed" {
+		t.Errorf("first entry = %s %q, want debug \"cache warmed\"", entries[0].Level, entries[0].Message)
+	}
+	if entries[1].Caller.File == "" {
+		t.Error("entry has no caller")
+	}
+	fields := entries[2].ContextMap()
+	if fields["request"] != "r1" || fields["order_id"] != int64(42) {
+		t.Errorf("fields = %v, want request=r1 and order_id=42", fields)
+	}
+}
+
+func TestObserverFilters(t *testing.T) {
+	log, obs := NewTest(t)
+
+	log.Info("User logged in", zap.String("user", "john"))
+	log.Info("User logged out", zap.String("user", "john"))
+	log.Info("User logged in", zap.String("user", "jane"), zap.Bool("mfa", true))
+
+	tests := []struct {
+		name string
+		obs  *Observer
+		want int
+	}{
+		{"FilterMessage", obs.FilterMessage("User logged in"), 2},
+		{"FilterMessageSnippet", obs.FilterMessageSnippet("logged"), 3},
+		{"FilterField", obs.FilterField(zap.String("user", "john")), 2},
+		{"FilterFieldKey", obs.FilterFieldKey("mfa"), 1},
+		{"chained", obs.FilterMessage("User logged in").FilterField(zap.String("user", "john")), 1},
+	}
+	for _, tt := range tests {
+		if got := tt.obs.Len(); got != tt.want {
+			t.Errorf("%s: got %d entries, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNewTestOptions(t *testing.T) {
+	log, obs := NewTest(t, zap.Fields(zap.String("component", "billing")))
+	log.Info("hello")
+
+	if obs.FilterField(zap.String("component", "billing")).Len() != 1 {
+		t.Errorf("option not applied: %v", obs.Entries())
+	}
+}
+
+func TestNewTestRunsHooks(t *testing.T) {
+	remove := RegisterHook(PreWrite, func(e *Entry) error {
+		e.Fields = append(e.Fields, zap.String("tagged", "yes"))
+		return nil
+	})
+	defer remove()
+
+	log, obs := NewTest(t)
+	log.Info("hello")
+
+	if obs.FilterField(zap.String("tagged", "yes")).Len() != 1 {
+		t.Errorf("PreWrite hook did not run: %v", obs.Entries())
+	}
+}
@@ -0,0 +1,99 @@
+// sad-go-logger/logger/time_format.go
+
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// defaultTimeLayout is the layout of the timestamps when no format is set.
+const defaultTimeLayout = "2006-01-02 15:04:05.000"
+
+// timeEncoder returns the encoder of the timestamps in format, in the zone
+// named zone, see Config.TimeFormat and Config.TimeZone.
+func timeEncoder(format, zone string) (zapcore.TimeEncoder, error) {
+	var encode zapcore.TimeEncoder
+	switch format {
+	case "":
+		encode = layoutTimeEncoder(defaultTimeLayout)
+	case "epoch":
+		encode = zapcore.EpochTimeEncoder
+	case "millis":
+		encode = func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+			enc.AppendInt64(t.UnixMilli())
+		}
+	case "nanos":
+		encode = zapcore.EpochNanosTimeEncoder
+	case "rfc3339":
+		encode = zapcore.RFC3339TimeEncoder
+	case "rfc3339nano":
+		encode = zapcore.RFC3339NanoTimeEncoder
+	default:
+		// A custom layout renders at least one element of the reference
+		// time differently from itself.
+		if reference := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC); reference.Format(format) == format {
+			return nil, fmt.Errorf("unknown time format %q, expected epoch, millis, nanos, rfc3339, rfc3339nano or a Go layout", format)
+		}
+		encode = layoutTimeEncoder(format)
+	}
+
+	if zone == "" {
+		return encode, nil
+	}
+	loc, err := loadTimeZone(zone)
+	if err != nil {
+		return nil, err
+	}
+	return func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+		encode(t.In(loc), enc)
+	}, nil
+}
+
+// layoutTimeEncoder returns the encoder of the timestamps in layout.
+func layoutTimeEncoder(layout string) zapcore.TimeEncoder {
+	return func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+		enc.AppendString(t.Format(layout))
+	}
+}
+
+// loadTimeZone returns the location named zone: "UTC", "Local", in any
+// case, or an IANA name such as "Europe/Paris".
+func loadTimeZone(zone string) (*time.Location, error) {
+	switch strings.ToLower(zone) {
+	case "utc":
+		return time.UTC, nil
+	case "local":
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q: %v", zone, err)
+	}
+	return loc, nil
+}
+
+// encoderConfig returns the encoder configuration of the sinks, with the
+// timestamps in the format and zone of the configuration.
+func (c Config) encoderConfig() (zapcore.EncoderConfig, error) {
+	cfg := newEncoderConfig()
+	encode, err := timeEncoder(c.TimeFormat, c.TimeZone)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.EncodeTime = encode
+	return cfg, nil
+}
+
+// globalEncoderConfig returns the encoder configuration of the sinks of the
+// global Log, for the access and audit logs.
+func globalEncoderConfig() zapcore.EncoderConfig {
+	cfg, err := globalConfig.encoderConfig()
+	if err != nil {
+		return newEncoderConfig()
+	}
+	return cfg
+}
@@ -0,0 +1,165 @@
+// sad-go-logger/logger/trace.go
+
+package logger
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/opentracing/opentracing-go"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Keys of the trace correlation fields.
+const (
+	TraceIDKey    = "trace_id"
+	SpanIDKey     = "span_id"
+	TraceFlagsKey = "trace_flags"
+)
+
+// TraceparentHeader is the W3C Trace Context header read by
+// ContextWithTraceparent.
+const TraceparentHeader = "traceparent"
+
+// TraceFields returns the trace_id, span_id and trace_flags fields of the
+// span carried by ctx: an OpenTelemetry span or, for services still on
+// OpenTracing clients such as Jaeger's, an OpenTracing span. The flags are
+// two hex digits, "01" for a sampled trace, and are omitted when the
+// tracer does not propagate them. It returns nil if ctx carries no span,
+// or an OpenTracing span whose tracer does not propagate a known format.
+func TraceFields(ctx context.Context) []zap.Field {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		return []zap.Field{
+			zap.String(TraceIDKey, sc.TraceID().String()),
+			zap.String(SpanIDKey, sc.SpanID().String()),
+			zap.String(TraceFlagsKey, sc.TraceFlags().String()),
+		}
+	}
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		if traceID, spanID, flags := openTracingIDs(span); traceID != "" {
+			fields := []zap.Field{zap.String(TraceIDKey, traceID), zap.String(SpanIDKey, spanID)}
+			if flags != "" {
+				fields = append(fields, zap.String(TraceFlagsKey, flags))
+			}
+			return fields
+		}
+	}
+	return nil
+}
+
+// ContextWithTraceparent returns ctx carrying the remote span of a W3C
+// traceparent header value, {version}-{trace-id}-{parent-id}-{flags}, so
+// that TraceFields and FromContext correlate the entries with the trace of
+// the caller. ctx is returned as is if it already carries a span, or if
+// traceparent is empty or invalid.
+func ContextWithTraceparent(ctx context.Context, traceparent string) context.Context {
+	if traceparent == "" || trace.SpanContextFromContext(ctx).IsValid() || opentracing.SpanFromContext(ctx) != nil {
+		return ctx
+	}
+	sc, err := parseTraceparent(traceparent)
+	if err != nil {
+		return ctx
+	}
+	return trace.ContextWithRemoteSpanContext(ctx, sc)
+}
+
+// requestContext returns the context of r, carrying the span of its
+// traceparent header, see ContextWithTraceparent.
+func requestContext(r *http.Request) context.Context {
+	return ContextWithTraceparent(r.Context(), r.Header.Get(TraceparentHeader))
+}
+
+// parseTraceparent parses a W3C traceparent header value. Versions after
+// 00 may append fields, which are ignored.
+func parseTraceparent(s string) (trace.SpanContext, error) {
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || parts[0] == "00" && len(parts) != 4 {
+		return trace.SpanContext{}, fmt.Errorf("invalid traceparent %q", s)
+	}
+	if _, err := strconv.ParseUint(parts[0], 16, 8); err != nil || strings.ToLower(parts[0]) != parts[0] {
+		return trace.SpanContext{}, fmt.Errorf("invalid traceparent version %q", parts[0])
+	}
+	traceID, err := trace.TraceIDFromHex(parts[1])
+	if err != nil {
+		return trace.SpanContext{}, fmt.Errorf("invalid traceparent trace id: %v", err)
+	}
+	spanID, err := trace.SpanIDFromHex(parts[2])
+	if err != nil {
+		return trace.SpanContext{}, fmt.Errorf("invalid traceparent parent id: %v", err)
+	}
+	flags, err := strconv.ParseUint(parts[3], 16, 8)
+	if err != nil || len(parts[3]) != 2 {
+		return trace.SpanContext{}, fmt.Errorf("invalid traceparent flags %q", parts[3])
+	}
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.TraceFlags(flags),
+		Remote:     true,
+	}), nil
+}
+
+// openTracingIDs reads the ids and flags of span from its propagation
+// headers, since OpenTracing has no accessor for them. It understands the
+// Jaeger (uber-trace-id), Zipkin B3 and W3C traceparent formats.
+func openTracingIDs(span opentracing.Span) (traceID, spanID, flags string) {
+	carrier := opentracing.TextMapCarrier{}
+	if err := span.Tracer().Inject(span.Context(), opentracing.TextMap, carrier); err != nil {
+		return "", "", ""
+	}
+	headers := make(map[string]string, len(carrier))
+	for key, value := range carrier {
+		headers[strings.ToLower(key)] = value
+	}
+
+	if v := headers["uber-trace-id"]; v != "" {
+		// {trace-id}:{span-id}:{parent-span-id}:{flags}, possibly escaped.
+		v = strings.ReplaceAll(v, "%3A", ":")
+		if parts := strings.Split(v, ":"); len(parts) == 4 {
+			// Bit 0 of the flags is the sampling decision, as in W3C.
+			if n, err := strconv.ParseUint(parts[3], 16, 8); err == nil {
+				flags = fmt.Sprintf("%02x", n&1)
+			}
+			return parts[0], parts[1], flags
+		}
+	}
+	if v := headers["x-b3-traceid"]; v != "" {
+		sampled := headers["x-b3-sampled"]
+		if headers["x-b3-flags"] == "1" {
+			sampled = "d"
+		}
+		return v, headers["x-b3-spanid"], b3Flags(sampled)
+	}
+	if v := headers["b3"]; v != "" {
+		// {trace-id}-{span-id}[-{sampled}[-{parent-span-id}]]
+		if parts := strings.Split(v, "-"); len(parts) >= 2 {
+			if len(parts) >= 3 {
+				flags = b3Flags(parts[2])
+			}
+			return parts[0], parts[1], flags
+		}
+	}
+	if v := headers[TraceparentHeader]; v != "" {
+		// {version}-{trace-id}-{parent-id}-{flags}
+		if parts := strings.Split(v, "-"); len(parts) == 4 {
+			return parts[1], parts[2], parts[3]
+		}
+	}
+	return "", "", ""
+}
+
+// b3Flags returns the W3C flags of a B3 sampling state: "1" or "true"
+// sampled, "d" debug, which implies sampled, and "0" or "false" not.
+func b3Flags(sampled string) string {
+	switch sampled {
+	case "1", "true", "d":
+		return "01"
+	case "0", "false":
+		return "00"
+	}
+	return ""
+}
@@ -0,0 +1,193 @@
+// sad-go-logger/logger/ui.go
+
+//go:build logui
+
+package logger
+
+import (
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/sadco-io/sad-go-logger/logger/logfile"
+)
+
+//go:embed ui
+var uiFiles embed.FS
+
+// uiDefaultLimit and uiMaxLimit bound the number of entries returned by the
+// log browser API.
+const (
+	uiDefaultLimit = 500
+	uiMaxLimit     = 5000
+)
+
+// uiMaxFacetValues is the number of distinct values above which a field is
+// not offered as a facet.
+const uiMaxFacetValues = 20
+
+// uiIndex is the full-text index over the local log file, opened on the
+// first text search and again when the file rolls over to a new period.
+var (
+	uiIndexMu   sync.Mutex
+	uiIndex     *logfile.Index
+	uiIndexPath string
+)
+
+// uiResponse is the body of the log browser's entries API.
+type uiResponse struct {
+	Entries []logfile.Record          `json:"entries"`
+	Facets  map[string]map[string]int `json:"facets"`
+}
+
+// UIHandler returns a handler serving an embedded single-page log browser
+// over the local log file, for hosts without central logging. It lists the
+// most recent entries with full-text search, level filters and field facets.
+// Mount it under a prefix with http.StripPrefix, behind the service's own
+// admin authentication.
+//
+// The UI is only compiled in with the logui build tag; without it the
+// handler responds 404.
+func UIHandler() http.Handler {
+	static, _ := fs.Sub(uiFiles, "ui")
+
+	mux := http.NewServeMux()
+	mux.Handle("/", http.FileServer(http.FS(static)))
+	mux.HandleFunc("/api/entries", serveUIEntries)
+	return mux
+}
+
+// serveUIEntries returns the most recent entries of the local log file that
+// match the level, grep, field and text query parameters, with value counts
+// of their low-cardinality fields. Text searches go through the full-text
+// index. With the SQLite store enabled, every query is answered from the
+// store instead.
+func serveUIEntries(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter, err := streamFilterFromQuery(q).compile()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	limit := uiDefaultLimit
+	if s := q.Get("limit"); s != "" {
+		if limit, err = strconv.Atoi(s); err != nil || limit <= 0 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+	if limit > uiMaxLimit {
+		limit = uiMaxLimit
+	}
+
+	var entries []logfile.Record
+	if localStore != nil {
+		entries, err = localStore.Search(logfile.Query{
+			Text:     q.Get("text"),
+			MinLevel: filter.MinLevel,
+			Fields:   filter.Fields,
+			Grep:     filter.Grep,
+			Limit:    limit,
+		})
+	} else if text := q.Get("text"); text != "" {
+		entries, err = searchUIIndex(text, filter, limit)
+	} else {
+		entries, err = scanUIEntries(filter, limit)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(uiResponse{Entries: entries, Facets: uiFacets(entries)})
+}
+
+// searchUIIndex answers a text search from the index of the local log file.
+func searchUIIndex(text string, filter logfile.Filter, limit int) ([]logfile.Record, error) {
+	uiIndexMu.Lock()
+	defer uiIndexMu.Unlock()
+	if path := currentLogPath(); uiIndex == nil || uiIndexPath != path {
+		idx, err := logfile.OpenIndex(path, logfile.IndexOptions{})
+		if err != nil {
+			return nil, err
+		}
+		uiIndex, uiIndexPath = idx, path
+	}
+	if err := uiIndex.Refresh(); err != nil {
+		return nil, err
+	}
+	return uiIndex.Search(logfile.Query{
+		Text:     text,
+		MinLevel: filter.MinLevel,
+		Fields:   filter.Fields,
+		Grep:     filter.Grep,
+		Limit:    limit,
+	})
+}
+
+// scanUIEntries returns the last limit entries of the local log file that
+// match filter, most recent first.
+func scanUIEntries(filter logfile.Filter, limit int) ([]logfile.Record, error) {
+	// Keep the last limit matching entries in a ring.
+	ring := make([]logfile.Record, 0, limit)
+	next := 0
+	err := logfile.ScanFile(currentLogPath(), func(rec logfile.Record) error {
+		if !filter.Match(rec) {
+			return nil
+		}
+		if len(ring) < limit {
+			ring = append(ring, rec)
+		} else {
+			ring[next] = rec
+		}
+		next = (next + 1) % limit
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Most recent first.
+	entries := make([]logfile.Record, 0, len(ring))
+	for i := 0; i < len(ring); i++ {
+		entries = append(entries, ring[(next-1-i+2*len(ring))%len(ring)])
+	}
+	return entries, nil
+}
+
+// uiFacets counts the values of every field with at most uiMaxFacetValues
+// distinct values among entries.
+func uiFacets(entries []logfile.Record) map[string]map[string]int {
+	counts := map[string]map[string]int{}
+	for _, rec := range entries {
+		for key := range rec {
+			if key == logfile.MessageKey || key == logfile.TimeKey {
+				continue
+			}
+			value, _ := rec.Field(key)
+			if counts[key] == nil {
+				counts[key] = map[string]int{}
+			}
+			counts[key][value]++
+		}
+	}
+
+	facets := map[string]map[string]int{}
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if len(counts[key]) <= uiMaxFacetValues {
+			facets[key] = counts[key]
+		}
+	}
+	return facets
+}
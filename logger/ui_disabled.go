@@ -0,0 +1,16 @@
+// sad-go-logger/logger/ui_disabled.go
+
+//go:build !logui
+
+package logger
+
+import "net/http"
+
+// UIHandler returns a handler serving the embedded log browser. This build
+// does not include the UI, so the handler responds 404; build with
+// -tags logui to enable it.
+func UIHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "log browser not available: build with -tags logui", http.StatusNotFound)
+	})
+}